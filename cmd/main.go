@@ -3,34 +3,171 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"syscall"
 	"time"
 
-	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/accesslog"
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/acl"
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/admin"
 	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/balancer"
 	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/config"
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/events"
 	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/health"
 	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/listener"
 	prommetrics "github.com/reybrally/TCP-Load-Balancer/internal/adapter/metrics"
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/ratelimit"
 	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/repository"
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/sni"
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/statsd"
+	statuspage "github.com/reybrally/TCP-Load-Balancer/internal/adapter/status"
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/telemetry"
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/tlsutil"
+	"github.com/reybrally/TCP-Load-Balancer/internal/api/handler"
 	"github.com/reybrally/TCP-Load-Balancer/internal/application/usecase"
 	appcfg "github.com/reybrally/TCP-Load-Balancer/internal/config"
 	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/port"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/hotrestart"
 	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/systemd"
+	"go.uber.org/zap"
 )
 
 const (
-	Version             = "v1.0.0"
-	HealthCheckInterval = 10 * time.Second
-	HealthCheckTimeout  = 2 * time.Second
-	ShutdownTimeout     = 30 * time.Second
+	Version           = "v1.0.0"
+	ShutdownTimeout   = 30 * time.Second
+	HotRestartTimeout = 30 * time.Second
+
+	// MetricsSocketName and AdminSocketName name the metrics and admin
+	// sockets among inherited sockets, both in a hot restart and as a
+	// systemd FileDescriptorName.
+	MetricsSocketName = "_metrics"
+	AdminSocketName   = "_admin"
 )
 
+// metricsSink hands out per-frontend metrics collectors and drops the
+// series of backends that have been removed.
+type metricsSink interface {
+	ForFrontend(name string) port.MetricsCollector
+	RemoveBackend(backend string)
+}
+
+// teeSink reports to every sink.
+type teeSink []metricsSink
+
+func (t teeSink) ForFrontend(name string) port.MetricsCollector {
+	collectors := make([]port.MetricsCollector, len(t))
+	for i, sink := range t {
+		collectors[i] = sink.ForFrontend(name)
+	}
+	return prommetrics.Tee(collectors...)
+}
+
+func (t teeSink) RemoveBackend(backend string) {
+	for _, sink := range t {
+		sink.RemoveBackend(backend)
+	}
+}
+
+type frontend struct {
+	cfg      appcfg.FrontendConfig
+	pools    []*pool
+	handler  port.ConnectionHandler
+	listener *listener.TCPListener
+
+	udpHandler  port.DatagramHandler
+	udpListener *listener.UDPListener
+
+	tlsReloader *tlsutil.ServerReloader
+	acl         *acl.ACL
+}
+
+// poolRepository holds a pool's backends and their admin states.
+type poolRepository interface {
+	port.BackendRepository
+	port.BackendAdmin
+}
+
+type pool struct {
+	name string
+	repo poolRepository
+}
+
+// poolHealth checks one pool on behalf of every frontend that has health
+// checks enabled and sends traffic to it.
+type poolHealth struct {
+	name      string
+	repo      poolRepository
+	frontends []string
+	metrics   []port.MetricsCollector
+	interval  time.Duration
+	timeout   time.Duration
+}
+
+func (fe *frontend) Listen(ctx context.Context) error {
+	if fe.udpListener != nil {
+		return fe.udpListener.Listen(ctx, fe.udpHandler)
+	}
+	return fe.listener.Listen(ctx, fe.handler)
+}
+
+func (fe *frontend) Close() error {
+	if fe.udpListener != nil {
+		return fe.udpListener.Close()
+	}
+	return fe.listener.Close()
+}
+
+// Drain waits for the frontend's TCP connections to finish. UDP sessions
+// are not drained; replies can't be sent once the socket is closed.
+func (fe *frontend) Drain(ctx context.Context) error {
+	if fe.udpListener != nil {
+		return nil
+	}
+	return fe.listener.Drain(ctx)
+}
+
+func (fe *frontend) status() statuspage.Frontend {
+	status := statuspage.Frontend{
+		Name:      fe.cfg.Name,
+		Protocol:  fe.cfg.Protocol,
+		Listen:    fe.cfg.ListenAddress(),
+		Algorithm: fe.cfg.Algorithm,
+	}
+	for _, p := range fe.pools {
+		status.Pools = append(status.Pools, statuspage.Pool{Name: p.name, Repository: p.repo, Admin: p.repo})
+	}
+	return status
+}
+
+func (fe *frontend) Files() ([]*os.File, error) {
+	if fe.udpListener != nil {
+		file, err := fe.udpListener.File()
+		if err != nil {
+			return nil, err
+		}
+		return []*os.File{file}, nil
+	}
+	return fe.listener.Files()
+}
+
+// handedOver is called once another process serves the frontend's sockets.
+func (fe *frontend) handedOver() {
+	if fe.listener != nil {
+		fe.listener.HandedOver()
+	}
+}
+
 func main() {
 	log := logger.New("development")
 	defer log.Sync()
@@ -40,92 +177,282 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	log.PrintBanner(Version, fmt.Sprintf("%d", cfg.Server.Port))
-
-	metrics := prommetrics.NewPrometheusMetrics()
-	log.Infof("Prometheus metrics collector initialized")
+	log.PrintBanner(Version, listenPorts(cfg))
+
+	registry := newMetricsRegistry(cfg.Metrics)
+	var metrics metricsSink
+	var tracer *telemetry.Tracer
+	switch {
+	case cfg.OTel.Enabled:
+		provider, err := telemetry.NewProvider(context.Background(), telemetry.Options{
+			Endpoint:    cfg.OTel.Endpoint,
+			Insecure:    cfg.OTel.Insecure,
+			Headers:     cfg.OTel.Headers,
+			Interval:    cfg.OTel.Interval,
+			ServiceName: cfg.OTel.ServiceName,
+			Traces:      cfg.OTel.Traces,
+		})
+		if err != nil {
+			log.Fatalf("Failed to set up OpenTelemetry: %v", err)
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+			defer cancel()
+			if err := provider.Shutdown(ctx); err != nil {
+				log.Errorf("Failed to flush OpenTelemetry data: %v", err)
+			}
+		}()
+		otelMetrics, err := provider.Metrics()
+		if err != nil {
+			log.Fatalf("Failed to create OpenTelemetry instruments: %v", err)
+		}
+		metrics = otelMetrics
+		tracer = provider.Tracer()
+		log.Infof("Exporting OpenTelemetry metrics to %s", cfg.OTel.Endpoint)
+	case cfg.StatsD.Enabled:
+		client, err := statsd.New(statsd.Config{
+			Address:       cfg.StatsD.Address,
+			Prefix:        cfg.StatsD.Prefix,
+			FlushInterval: cfg.StatsD.FlushInterval,
+			Tags:          cfg.StatsD.Tags,
+			MaxPacketSize: cfg.StatsD.MaxPacketSize,
+		})
+		if err != nil {
+			log.Fatalf("Failed to set up StatsD: %v", err)
+		}
+		defer client.Close()
+		metrics = statsd.NewMetrics(client)
+		log.Infof("Sending StatsD metrics to %s every %v", cfg.StatsD.Address, cfg.StatsD.FlushInterval)
+	default:
+		metrics = prommetrics.NewPrometheusMetrics(registry)
+		log.Infof("Prometheus metrics collector initialized")
+	}
+	tracker := statuspage.New(Version)
+	metrics = teeSink{metrics, tracker}
 
 	var wg sync.WaitGroup
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		mux := http.NewServeMux()
-		mux.Handle("/metrics", promhttp.Handler())
-		mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-			w.WriteHeader(http.StatusOK)
-			w.Write([]byte("OK"))
-		})
-		log.Infof("Metrics endpoint started on :9090/metrics")
-		if err := http.ListenAndServe(":9090", mux); err != nil {
-			log.Errorf("Metrics server error: %v", err)
-		}
-	}()
+	parent, err := hotrestart.Inherit()
+	if err != nil {
+		log.Fatalf("Failed to receive sockets from previous process: %v", err)
+	}
+	inherited, err := inheritedSockets(parent)
+	if err != nil {
+		log.Fatalf("Failed to read socket activation: %v", err)
+	}
+
+	sessions := usecase.NewSessionRegistry()
+	bus := events.NewBus(events.DefaultHistory)
 
-	repo := repository.New()
+	mux := http.NewServeMux()
+	mux.Handle(cfg.Metrics.Path, handler.NewMetricsHandler(registry, log))
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+	})
+	metricsServer := &http.Server{Handler: mux}
+
+	metricsListener, err := listenHTTP(cfg.Metrics.Address, inherited[MetricsSocketName])
+	delete(inherited, MetricsSocketName)
+	if err != nil {
+		log.Errorf("Metrics server error: %v", err)
+	} else {
+		go func() {
+			log.Infof("Metrics endpoint started on %s%s", metricsListener.Addr(), cfg.Metrics.Path)
+			if err := metricsServer.Serve(metricsListener); err != nil && err != http.ErrServerClosed {
+				log.Errorf("Metrics server error: %v", err)
+			}
+		}()
+	}
 
-	if err := initBackends(cfg, repo, log); err != nil {
+	repos, err := initPools(cfg, log)
+	if err != nil {
 		log.Fatalf("Failed to initialize backends: %v", err)
 	}
 
-	lb := balancer.New()
-	log.Infof("Load balancing algorithm: Round-Robin")
+	adminPools := make(map[string]port.BackendAdmin, len(repos))
+	for name, repo := range repos {
+		adminPools[name] = repo
+	}
+	adminMux := http.NewServeMux()
+	admin.NewSessionsHandler(sessions, bus, log).Register(adminMux)
+	admin.NewBackendsHandler(adminPools, log).Register(adminMux)
+	admin.NewEventsHandler(bus, log).Register(adminMux)
+	admin.NewStatusHandler(tracker, log).Register(adminMux)
+	adminServer := &http.Server{Handler: admin.RequireToken(cfg.Admin.Token, adminMux)}
+
+	adminListener, err := listenHTTP(cfg.Admin.Address, inherited[AdminSocketName])
+	delete(inherited, AdminSocketName)
+	if err != nil {
+		log.Errorf("Admin server error: %v", err)
+	} else {
+		go func() {
+			log.Infof("Admin endpoints started on %s", adminListener.Addr())
+			if err := adminServer.Serve(adminListener); err != nil && err != http.ErrServerClosed {
+				log.Errorf("Admin server error: %v", err)
+			}
+		}()
+	}
 
-	healthChecker := health.New(HealthCheckTimeout, metrics, log)
-	log.Infof("Health checker initialized (interval: %v, timeout: %v)", HealthCheckInterval, HealthCheckTimeout)
+	accessLog, accessLogFile, err := newAccessLog(cfg.AccessLog)
+	if err != nil {
+		log.Fatalf("Failed to open access log: %v", err)
+	}
+	if accessLogFile != nil {
+		defer accessLogFile.Close()
+	}
 
-	handleConnUseCase := usecase.New(lb, repo, metrics, log)
+	frontends := make([]*frontend, 0, len(cfg.Frontends))
+	for _, feCfg := range cfg.Frontends {
+		files := inherited[feCfg.Name]
+		delete(inherited, feCfg.Name)
+		if len(files) > 0 {
+			log.Infof("Frontend %s uses %d inherited socket(s)", feCfg.Name, len(files))
+		}
 
-	tcpListener, err := listener.New(cfg.Server.Host, cfg.Server.Port, log)
-	if err != nil {
-		log.Fatalf("Failed to create listener: %v", err)
+		fe, err := newFrontend(feCfg, cfg, repos, metrics, tracer, accessLog, sessions, bus, files, log)
+		if err != nil {
+			log.Fatalf("Failed to create frontend %s: %v", feCfg.Name, err)
+		}
+		defer fe.Close()
+		frontends = append(frontends, fe)
+		tracker.AddFrontend(fe.status())
+	}
+	for name, files := range inherited {
+		log.Warnf("No frontend named %s for inherited socket, closing it", name)
+		for _, file := range files {
+			file.Close()
+		}
 	}
-	defer tcpListener.Close()
+
+	notifier := systemd.NewNotifier()
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	var totalConnections atomic.Int64
-	var activeConnections atomic.Int64
+	for name, repo := range repos {
+		poolLog := log.WithFields(zap.String("pool", name))
+		changes := repo.Watch(ctx)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			removeSeries(ctx, repo, metrics)
+		}()
+		go func() {
+			defer wg.Done()
+			events.PublishBackendChanges(changes, bus, name, poolLog)
+		}()
+	}
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		runHealthChecks(ctx, repo, healthChecker, log)
-	}()
+	for _, ph := range poolHealthChecks(frontends, metrics) {
+		poolLog := log.WithFields(zap.String("pool", ph.name))
+		checker := health.New(ph.timeout, prommetrics.Tee(ph.metrics...), poolLog, healthOptions(cfg.Pool(ph.name))...)
+		poolLog.Debugf("Health checks every %s for frontends %s", ph.interval, strings.Join(ph.frontends, ","))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runHealthChecks(ctx, ph.repo, checker, ph.interval, poolLog)
+		}()
+	}
+
+	for _, fe := range frontends {
+		feLog := log.WithFields(zap.String("frontend", fe.cfg.Name))
+
+		if fe.tlsReloader != nil {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				fe.tlsReloader.Watch(ctx, fe.cfg.TLS.ReloadInterval)
+			}()
+		}
+		if fe.acl != nil && fe.cfg.ACL.File != "" {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				fe.acl.Watch(ctx, fe.cfg.ACL.ReloadInterval)
+			}()
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fe.Listen(ctx); err != nil && err != context.Canceled {
+				feLog.Warnf("Listener error: %v", err)
+			}
+		}()
+
+		log.Infof("Frontend %s listening on %s -> pools %s (%s)",
+			fe.cfg.Name, fe.cfg.ListenAddress(), strings.Join(fe.cfg.PoolNames(), ","), fe.cfg.Algorithm)
+	}
 
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		printStats(ctx, repo, &totalConnections, &activeConnections, log)
+		notifier.RunWatchdog(ctx)
 	}()
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		if err := tcpListener.Listen(ctx, handleConnUseCase); err != nil && err != context.Canceled {
-			log.Warnf("Listener error: %v", err)
+	if parent != nil {
+		if err := parent.Ready(); err != nil {
+			log.Fatalf("Failed to report readiness to previous process: %v", err)
 		}
-	}()
+		notifier.Notify(fmt.Sprintf("MAINPID=%d", os.Getpid()))
+	}
+	notifier.Ready()
+	notifier.Status(fmt.Sprintf("Serving %d frontends", len(frontends)))
 
 	log.Infof("TCP Load Balancer is ready to accept connections")
-	log.Infof("Listening on %s:%d", cfg.Server.Host, cfg.Server.Port)
 	log.Infof("Press Ctrl+C to gracefully shutdown")
 
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	for sig := range sigChan {
+		if sig == syscall.SIGUSR1 {
+			if accessLogFile != nil {
+				if err := accessLogFile.Reopen(); err != nil {
+					log.Errorf("Failed to reopen access log: %v", err)
+				} else {
+					log.Infof("Access log reopened")
+				}
+			}
+			continue
+		}
+		if sig != syscall.SIGUSR2 {
+			log.Warnf("Shutdown signal received, initiating graceful shutdown...")
+			notifier.Stopping()
+			break
+		}
 
-	<-sigChan
-	log.Warnf("Shutdown signal received, initiating graceful shutdown...")
+		log.Infof("Hot restart requested, starting new process...")
+		pid, err := hotRestart(frontends, map[string]net.Listener{
+			MetricsSocketName: metricsListener,
+			AdminSocketName:   adminListener,
+		})
+		if err != nil {
+			log.Errorf("Hot restart failed, continuing to serve: %v", err)
+			continue
+		}
+		log.Infof("New process %d is serving, draining connections", pid)
+		notifier.Status(fmt.Sprintf("Handed over to pid %d, draining", pid))
+		break
+	}
 
 	cancel()
-	tcpListener.Close()
+	for _, fe := range frontends {
+		fe.Close()
+	}
 
 	log.Infof("Waiting for active connections to complete (max %v)...", ShutdownTimeout)
 
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer drainCancel()
+
 	done := make(chan struct{})
 	go func() {
 		wg.Wait()
+		for _, fe := range frontends {
+			fe.Drain(drainCtx)
+		}
 		done <- struct{}{}
 	}()
 
@@ -136,23 +463,403 @@ func main() {
 		log.Warnf("Timeout waiting for connections to close, forcing shutdown")
 	}
 
-	printFinalStats(repo, &totalConnections, log)
+	bus.Close()
+	metricsServer.Shutdown(context.Background())
+	adminServer.Shutdown(context.Background())
+
+	printFinalStats(frontends, tracker, log)
 
 	log.Infof("TCP Load Balancer stopped successfully")
 }
 
-func initBackends(cfg *appcfg.Config, repo interface {
+func listenPorts(cfg *appcfg.Config) string {
+	ports := make([]string, 0, len(cfg.Frontends))
+	for _, fe := range cfg.Frontends {
+		if fe.Unix.Path != "" {
+			ports = append(ports, fe.Unix.Path)
+			continue
+		}
+		ports = append(ports, fmt.Sprintf("%d", fe.Port))
+	}
+	return strings.Join(ports, ",")
+}
+
+// inheritedSockets returns the sockets handed over by a hot restart or, if
+// there was none, passed by systemd, keyed by frontend name. Systemd sockets
+// are named by their FileDescriptorName.
+func inheritedSockets(parent *hotrestart.Child) (hotrestart.Files, error) {
+	if parent != nil {
+		return parent.Files(), nil
+	}
+
+	files, err := systemd.ListenFiles(true)
+	if err != nil {
+		return nil, err
+	}
+	sockets := make(hotrestart.Files, len(files))
+	for _, file := range files {
+		sockets[file.Name()] = append(sockets[file.Name()], file)
+	}
+	return sockets, nil
+}
+
+// newMetricsRegistry returns the registry the metrics endpoint serves, with
+// the runtime collectors cfg asks for.
+func newMetricsRegistry(cfg appcfg.MetricsConfig) *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	if cfg.GoCollector {
+		registry.MustRegister(collectors.NewGoCollector())
+	}
+	if cfg.ProcessCollector {
+		registry.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	}
+	return registry
+}
+
+// listenHTTP listens on an inherited socket if there is one, and on addr
+// otherwise.
+func listenHTTP(addr string, files []*os.File) (net.Listener, error) {
+	if len(files) == 0 {
+		return net.Listen("tcp", addr)
+	}
+	for _, file := range files[1:] {
+		file.Close()
+	}
+	defer files[0].Close()
+	return net.FileListener(files[0])
+}
+
+// hotRestart starts a new copy of the binary with the frontends' sockets and
+// the HTTP servers' listeners, keyed by socket name, and waits until it
+// serves them.
+func hotRestart(frontends []*frontend, servers map[string]net.Listener) (int, error) {
+	files := make(hotrestart.Files, len(frontends)+len(servers))
+	defer func() {
+		for _, group := range files {
+			for _, file := range group {
+				file.Close()
+			}
+		}
+	}()
+
+	for _, fe := range frontends {
+		feFiles, err := fe.Files()
+		if err != nil {
+			return 0, fmt.Errorf("frontend %s: %w", fe.cfg.Name, err)
+		}
+		files[fe.cfg.Name] = feFiles
+	}
+
+	for name, ln := range servers {
+		if tcpListener, ok := ln.(*net.TCPListener); ok {
+			file, err := tcpListener.File()
+			if err != nil {
+				return 0, fmt.Errorf("%s listener: %w", name, err)
+			}
+			files[name] = []*os.File{file}
+		}
+	}
+
+	pid, err := hotrestart.Start(files, HotRestartTimeout)
+	if err != nil {
+		return 0, err
+	}
+	for _, fe := range frontends {
+		fe.handedOver()
+	}
+	return pid, nil
+}
+
+// newAccessLog opens the access log cfg describes, or returns nil if it is
+// disabled. The file is returned so the caller can close it.
+func newAccessLog(cfg appcfg.AccessLogConfig) (*accesslog.Logger, *accesslog.RotatingFile, error) {
+	if !cfg.Enabled {
+		return nil, nil, nil
+	}
+
+	var out io.Writer = os.Stdout
+	var file *accesslog.RotatingFile
+	if cfg.Output != appcfg.AccessLogStdout {
+		var err error
+		file, err = accesslog.OpenFile(cfg.Output, int64(cfg.MaxSizeMB)<<20, cfg.MaxBackups)
+		if err != nil {
+			return nil, nil, err
+		}
+		out = file
+	}
+
+	accessLog, err := accesslog.New(out, cfg.Format, cfg.Template)
+	if err != nil {
+		if file != nil {
+			file.Close()
+		}
+		return nil, nil, err
+	}
+	return accessLog, file, nil
+}
+
+// newFrontend builds a frontend. When files are given they are used as the
+// listening sockets instead of opening them from the config.
+func newFrontend(cfg appcfg.FrontendConfig, appCfg *appcfg.Config, repos map[string]poolRepository, metrics metricsSink, tracer *telemetry.Tracer, accessLog *accesslog.Logger, sessions *usecase.SessionRegistry, bus *events.Bus, files []*os.File, log *logger.Logger) (*frontend, error) {
+	feLog := log.WithFields(zap.String("frontend", cfg.Name))
+	feMetrics := metrics.ForFrontend(cfg.Name)
+	feEvents := bus.ForFrontend(cfg.Name)
+
+	fe := &frontend{
+		cfg: cfg,
+	}
+
+	for _, name := range cfg.PoolNames() {
+		fe.pools = append(fe.pools, &pool{name: name, repo: repos[name]})
+	}
+
+	if cfg.Protocol == appcfg.ProtocolUDP {
+		lb, err := balancer.NewByAlgorithm(cfg.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+		var udpListener *listener.UDPListener
+		switch len(files) {
+		case 0:
+			udpListener, err = listener.NewUDP(cfg.Host, cfg.Port, feLog)
+		case 1:
+			udpListener, err = listener.NewUDPFromFile(files[0], feLog)
+		default:
+			err = fmt.Errorf("expected one inherited udp socket, got %d", len(files))
+		}
+		if err != nil {
+			return nil, err
+		}
+		var udpOpts []usecase.DatagramOption
+		if accessLog != nil {
+			udpOpts = append(udpOpts, usecase.WithDatagramAccessLog(accessLog.ForFrontend(cfg.Name)))
+		}
+		fe.udpHandler = usecase.NewDatagram(lb, repos[cfg.Pool], feMetrics, feLog, cfg.UDP.SessionTimeout, udpOpts...)
+		fe.udpListener = udpListener
+		return fe, nil
+	}
+
+	handlers := make(map[string]port.ConnectionHandler)
+	for _, name := range cfg.PoolNames() {
+		lb, err := balancer.NewByAlgorithm(cfg.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+		opts, err := poolOptions(appCfg.Pool(name))
+		if err != nil {
+			return nil, fmt.Errorf("pool %s: %w", name, err)
+		}
+		if cfg.Queue.Size > 0 {
+			opts = append(opts, usecase.WithQueue(cfg.Queue.Size, cfg.Queue.Timeout))
+		}
+		if accessLog != nil {
+			opts = append(opts, usecase.WithAccessLog(accessLog.ForFrontend(cfg.Name)))
+		}
+		if tracer != nil {
+			opts = append(opts, usecase.WithTracer(tracer.ForFrontend(cfg.Name)))
+		}
+		opts = append(opts, usecase.WithSessions(sessions, cfg.Name), usecase.WithEvents(feEvents))
+		handlers[name] = usecase.New(lb, repos[name], feMetrics, feLog.WithFields(zap.String("pool", name)), opts...)
+	}
+
+	fe.handler = handlers[cfg.Pool]
+	if len(cfg.SNI.Routes) > 0 {
+		routes := make([]sni.Route, 0, len(cfg.SNI.Routes))
+		for _, route := range cfg.SNI.Routes {
+			routes = append(routes, sni.Route{
+				Hosts:   route.Hosts,
+				ALPN:    route.ALPN,
+				Handler: handlers[route.Pool],
+			})
+		}
+		fe.handler = sni.NewRouter(routes, handlers[cfg.Pool], cfg.SNI.PeekTimeout, feMetrics, feLog)
+	}
+
+	opts := []listener.Option{listener.WithMetrics(feMetrics), listener.WithEvents(feEvents)}
+	if cfg.Acceptors > 1 {
+		opts = append(opts, listener.WithReusePort(cfg.Acceptors))
+	}
+	if cfg.Backlog > 0 {
+		opts = append(opts, listener.WithBacklog(cfg.Backlog))
+	}
+	if cfg.MaxConnections > 0 {
+		opts = append(opts, listener.WithMaxConnections(cfg.MaxConnections))
+	}
+	if cfg.ACL.Enabled() {
+		access, err := acl.New(acl.Options{
+			Allow:    cfg.ACL.Allow,
+			Deny:     cfg.ACL.Deny,
+			File:     cfg.ACL.File,
+			OnReload: reloadEvents(feEvents, "acl", cfg.ACL.File),
+		}, feLog)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, listener.WithACL(access))
+		fe.acl = access
+	}
+	if rl := cfg.RateLimit; rl.Enabled() {
+		opts = append(opts, listener.WithRateLimit(ratelimit.New(ratelimit.Config{
+			Rate:                rl.Rate,
+			Burst:               rl.Burst,
+			PrefixRate:          rl.PrefixRate,
+			PrefixBurst:         rl.PrefixBurst,
+			IPv4Prefix:          rl.IPv4Prefix,
+			IPv6Prefix:          rl.IPv6Prefix,
+			MaxConnectionsPerIP: rl.MaxConnectionsPerIP,
+			MaxTracked:          rl.MaxTracked,
+		})))
+	}
+	if cfg.AcceptProxy.Enabled {
+		trusted, err := parseCIDRs(cfg.AcceptProxy.TrustedCIDRs)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, listener.WithProxyProtocol(trusted, cfg.AcceptProxy.HeaderTimeout))
+	}
+	if cfg.TLS.Enabled {
+		reloader, err := tlsutil.NewServerReloader(tlsutil.ServerOptions{
+			CertFile:     cfg.TLS.CertFile,
+			KeyFile:      cfg.TLS.KeyFile,
+			MinVersion:   cfg.TLS.MinVersion,
+			MaxVersion:   cfg.TLS.MaxVersion,
+			CipherSuites: cfg.TLS.CipherSuites,
+			ClientAuth:   cfg.TLS.ClientAuth,
+			ClientCAFile: cfg.TLS.ClientCAFile,
+			OnReload:     reloadEvents(feEvents, "tls", cfg.TLS.CertFile),
+		}, feLog)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, listener.WithTLS(reloader.Config(), cfg.TLS.HandshakeTimeout))
+		fe.tlsReloader = reloader
+	}
+
+	var tcpListener *listener.TCPListener
+	var err error
+	switch {
+	case len(files) > 0:
+		tcpListener, err = listener.NewFromFiles(files, feLog, opts...)
+	case cfg.Unix.Path != "":
+		mode, _ := cfg.Unix.FileMode()
+		tcpListener, err = listener.NewUnix(listener.UnixSocket{
+			Path:  cfg.Unix.Path,
+			Mode:  mode,
+			User:  cfg.Unix.User,
+			Group: cfg.Unix.Group,
+		}, feLog, opts...)
+	default:
+		tcpListener, err = listener.New(cfg.Host, cfg.Port, feLog, opts...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fe.listener = tcpListener
+
+	return fe, nil
+}
+
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", cidr, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+func poolOptions(cfg *appcfg.PoolConfig) ([]usecase.Option, error) {
+	var opts []usecase.Option
+
+	if cfg.TLS.Enabled {
+		tlsConfig, err := tlsutil.NewClientConfig(tlsutil.ClientOptions{
+			ServerName:         cfg.TLS.ServerName,
+			CAFile:             cfg.TLS.CAFile,
+			CertFile:           cfg.TLS.CertFile,
+			KeyFile:            cfg.TLS.KeyFile,
+			MinVersion:         cfg.TLS.MinVersion,
+			InsecureSkipVerify: cfg.TLS.InsecureSkipVerify,
+		})
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, usecase.WithBackendTLS(tlsConfig))
+	}
+
+	if cfg.ProxyProtocol.Version != 0 {
+		opts = append(opts, usecase.WithProxyProtocol(cfg.ProxyProtocol.Version))
+	}
+
+	return opts, nil
+}
+
+func healthOptions(cfg *appcfg.PoolConfig) []health.Option {
+	var opts []health.Option
+	if cfg.ProxyProtocol.Version != 0 && cfg.ProxyProtocol.HealthCheck {
+		opts = append(opts, health.WithProxyProtocol(cfg.ProxyProtocol.Version))
+	}
+	return opts
+}
+
+func initPools(cfg *appcfg.Config, log *logger.Logger) (map[string]poolRepository, error) {
+	repos := make(map[string]poolRepository, len(cfg.Pools))
+	for _, pool := range cfg.Pools {
+		repo, err := newPoolRepository(pool, log)
+		if err != nil {
+			return nil, err
+		}
+		repos[pool.Name] = repo
+	}
+	return repos, nil
+}
+
+// newPoolRepository keeps a pool in memory, or in its state file if it has
+// one. An existing state file takes precedence over the backends in the
+// config file, which only seed a new one.
+func newPoolRepository(pool appcfg.PoolConfig, log *logger.Logger) (poolRepository, error) {
+	if pool.StateFile == "" {
+		repo := repository.New()
+		return repo, initBackends(pool, repo, log)
+	}
+
+	repo, err := repository.OpenFile(pool.StateFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load state of pool %s: %w", pool.Name, err)
+	}
+	if !repo.Loaded() {
+		log.Infof("No state file for pool %s yet, seeding %s from the config", pool.Name, pool.StateFile)
+		return repo, initBackends(pool, repo, log)
+	}
+
+	backends := repo.GetAll(context.Background())
+	log.Infof("Loaded %d backend servers for pool %s from %s", len(backends), pool.Name, pool.StateFile)
+	if len(pool.Backends) > 0 {
+		log.Warnf("Pool %s: backends in the config file are ignored in favor of %s", pool.Name, pool.StateFile)
+	}
+	for _, b := range backends {
+		log.Infof("  ✓ Backend %s (weight: %d)", b.GetAddress(), b.Weight)
+	}
+	return repo, nil
+}
+
+func initBackends(pool appcfg.PoolConfig, repo interface {
 	Add(context.Context, *model.Backend) error
 }, log *logger.Logger) error {
-	log.Infof("Initializing %d backend servers...", len(cfg.Backends))
-
-	for i, backendCfg := range cfg.Backends {
-		backend := model.NewBackend(
-			fmt.Sprintf("backend-%d", i),
-			backendCfg.Address,
-			backendCfg.Port,
-			backendCfg.Weight,
-		)
+	log.Infof("Initializing %d backend servers for pool %s...", len(pool.Backends), pool.Name)
+
+	for i, backendCfg := range pool.Backends {
+		id := fmt.Sprintf("%s-backend-%d", pool.Name, i)
+		var backend *model.Backend
+		if backendCfg.Socket != "" {
+			backend = model.NewUnixBackend(id, backendCfg.Socket, backendCfg.Weight)
+		} else {
+			backend = model.NewBackend(id, backendCfg.Address, backendCfg.Port, backendCfg.Weight)
+		}
+		backend.MaxConnections = backendCfg.MaxConnections
 		if err := repo.Add(context.Background(), backend); err != nil {
 			return fmt.Errorf("failed to add backend %s: %w", backend.GetAddress(), err)
 		}
@@ -164,10 +871,12 @@ func initBackends(cfg *appcfg.Config, repo interface {
 
 func runHealthChecks(ctx context.Context, repo interface {
 	GetAll(context.Context) []*model.Backend
+	SetHealthy(context.Context, string, bool) error
+	AdminState(context.Context, string) string
 }, healthChecker interface {
 	Check(context.Context, *model.Backend) bool
-}, log *logger.Logger) {
-	ticker := time.NewTicker(HealthCheckInterval)
+}, interval time.Duration, log *logger.Logger) {
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	log.Debugf("Health check daemon started")
@@ -184,13 +893,18 @@ func runHealthChecks(ctx context.Context, repo interface {
 			var unhealthyCount atomic.Int32
 
 			for _, backend := range backends {
+				if repo.AdminState(ctx, backend.ID) == model.AdminDisabled {
+					continue
+				}
 				wg.Add(1)
 				go func(b *model.Backend) {
 					defer wg.Done()
 
-					wasHealthy := b.GetHealthy()
 					isHealthy := healthChecker.Check(ctx, b)
-					b.SetHealthy(isHealthy)
+					if err := repo.SetHealthy(ctx, b.ID, isHealthy); err != nil {
+						log.Debugf("Backend %s removed during health check", b.GetAddress())
+						return
+					}
 
 					if isHealthy {
 						healthyCount.Add(1)
@@ -198,14 +912,6 @@ func runHealthChecks(ctx context.Context, repo interface {
 						unhealthyCount.Add(1)
 					}
 
-					if wasHealthy != isHealthy {
-						if isHealthy {
-							log.Infof("Backend %s recovered", b.GetAddress())
-						} else {
-							log.Warnf("Backend %s went down", b.GetAddress())
-						}
-					}
-
 					status := "healthy"
 					if !isHealthy {
 						status = "unhealthy"
@@ -220,50 +926,70 @@ func runHealthChecks(ctx context.Context, repo interface {
 	}
 }
 
-func printStats(ctx context.Context, repo interface {
-	GetAll(context.Context) []*model.Backend
-	GetHealthy(context.Context) []*model.Backend
-}, totalConns, activeConns *atomic.Int64, log *logger.Logger) {
-	ticker := time.NewTicker(60 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			allBackends := repo.GetAll(ctx)
-			healthyBackends := repo.GetHealthy(ctx)
-
-			log.Infof("Statistics: Total Backends: %d, Healthy: %d, Unhealthy: %d",
-				len(allBackends),
-				len(healthyBackends),
-				len(allBackends)-len(healthyBackends))
-
-			for _, b := range allBackends {
-				status := "OK"
-				if !b.GetHealthy() {
-					status = "NOT OK"
-				}
-				log.Infof("  %s %s - Active connections: %d",
-					status,
-					b.GetAddress(),
-					b.GetActiveConnections())
+// poolHealthChecks returns the pools to health check. A pool shared by
+// several frontends is checked once, at the shortest interval and with the
+// longest timeout among the frontends that enable checks, and its health
+// metrics are reported for each of them.
+func poolHealthChecks(frontends []*frontend, metrics metricsSink) []*poolHealth {
+	var checks []*poolHealth
+	byName := make(map[string]*poolHealth)
+	for _, fe := range frontends {
+		if fe.cfg.HealthCheck.Disabled {
+			continue
+		}
+		for _, p := range fe.pools {
+			ph, ok := byName[p.name]
+			if !ok {
+				ph = &poolHealth{name: p.name, repo: p.repo, interval: fe.cfg.HealthCheck.Interval, timeout: fe.cfg.HealthCheck.Timeout}
+				byName[p.name] = ph
+				checks = append(checks, ph)
 			}
+			ph.frontends = append(ph.frontends, fe.cfg.Name)
+			ph.metrics = append(ph.metrics, metrics.ForFrontend(fe.cfg.Name))
+			ph.interval = min(ph.interval, fe.cfg.HealthCheck.Interval)
+			ph.timeout = max(ph.timeout, fe.cfg.HealthCheck.Timeout)
 		}
 	}
+	return checks
 }
 
-func printFinalStats(repo interface {
-	GetAll(context.Context) []*model.Backend
-}, totalConns *atomic.Int64, log *logger.Logger) {
-	log.Infof("Final Statistics:")
-	log.Infof("  Total connections processed: %d", totalConns.Load())
+// removeSeries drops the metric series of backends removed from repo until
+// ctx is done.
+func removeSeries(ctx context.Context, repo port.BackendRepository, metrics metricsSink) {
+	for change := range repo.Watch(ctx) {
+		if change.Type == model.BackendRemoved {
+			metrics.RemoveBackend(change.Backend.GetAddress())
+		}
+	}
+}
 
-	backends := repo.GetAll(context.Background())
-	for _, b := range backends {
-		log.Infof("  Backend %s: %d active connections",
-			b.GetAddress(),
-			b.GetActiveConnections())
+// reloadEvents publishes the outcome of reloading file as a
+// config_reloaded or config_reload_failed event.
+func reloadEvents(events port.EventPublisher, kind, file string) func(error) {
+	return func(err error) {
+		event := model.Event{Type: model.EventConfigReloaded, Reason: kind, Message: file}
+		if err != nil {
+			event.Type = model.EventConfigReloadFailed
+			event.Message = err.Error()
+		}
+		events.Publish(event)
+	}
+}
+
+func printFinalStats(frontends []*frontend, tracker *statuspage.Tracker, log *logger.Logger) {
+	log.Infof("Final Statistics:")
+	log.Infof("  Total connections processed: %d", tracker.TotalSessions())
+
+	for _, fe := range frontends {
+		for _, p := range fe.pools {
+			backends := p.repo.GetAll(context.Background())
+			for _, b := range backends {
+				log.Infof("  [%s/%s] Backend %s: %d active connections",
+					fe.cfg.Name,
+					p.name,
+					b.GetAddress(),
+					b.GetActiveConnections())
+			}
+		}
 	}
 }
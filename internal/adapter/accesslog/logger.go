@@ -0,0 +1,119 @@
+package accesslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/port"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+
+	// DefaultTemplate is used for the text format when no template is set.
+	// Templates are executed with a *model.SessionRecord.
+	DefaultTemplate = `{{.Start.Format "2006-01-02T15:04:05.000Z07:00"}} {{.Frontend}} {{.Client}} -> {{or .Backend "-"}} ` +
+		`duration={{.Duration}} sent={{.BytesSent}} received={{.BytesReceived}} reason={{.CloseReason}} retries={{.Retries}}` +
+		`{{if .TLSVersion}} tls={{.TLSVersion}}{{end}}{{if .ServerName}} sni={{.ServerName}}{{end}}{{if .ALPN}} alpn={{.ALPN}}{{end}}`
+)
+
+// Logger writes one line per finished session. Lines are written with a
+// single Write call, so concurrent sessions never interleave.
+type Logger struct {
+	out      io.Writer
+	mu       *sync.Mutex
+	encode   func(*bytes.Buffer, *model.SessionRecord) error
+	frontend string
+}
+
+func New(out io.Writer, format, text string) (*Logger, error) {
+	l := &Logger{out: out, mu: &sync.Mutex{}}
+
+	switch format {
+	case "", FormatJSON:
+		l.encode = encodeJSON
+	case FormatText:
+		if text == "" {
+			text = DefaultTemplate
+		}
+		tmpl, err := template.New("access_log").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid access log template: %w", err)
+		}
+		l.encode = func(buf *bytes.Buffer, record *model.SessionRecord) error {
+			if err := tmpl.Execute(buf, record); err != nil {
+				return err
+			}
+			if buf.Len() == 0 || buf.Bytes()[buf.Len()-1] != '\n' {
+				buf.WriteByte('\n')
+			}
+			return nil
+		}
+	default:
+		return nil, fmt.Errorf("unknown access log format %q", format)
+	}
+
+	return l, nil
+}
+
+// ForFrontend returns a logger writing to the same output that fills in
+// the given frontend name.
+func (l *Logger) ForFrontend(frontend string) port.AccessLogger {
+	scoped := *l
+	scoped.frontend = frontend
+	return &scoped
+}
+
+func (l *Logger) LogSession(record *model.SessionRecord) {
+	if record.Frontend == "" {
+		record.Frontend = l.frontend
+	}
+
+	var buf bytes.Buffer
+	if err := l.encode(&buf, record); err != nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.out.Write(buf.Bytes())
+}
+
+type jsonRecord struct {
+	Start         time.Time `json:"start"`
+	Frontend      string    `json:"frontend"`
+	Client        string    `json:"client"`
+	Backend       string    `json:"backend,omitempty"`
+	DurationMS    float64   `json:"duration_ms"`
+	BytesSent     int64     `json:"bytes_sent"`
+	BytesReceived int64     `json:"bytes_received"`
+	CloseReason   string    `json:"close_reason"`
+	Retries       int       `json:"retries"`
+	TLSVersion    string    `json:"tls_version,omitempty"`
+	ServerName    string    `json:"sni,omitempty"`
+	ALPN          string    `json:"alpn,omitempty"`
+}
+
+func encodeJSON(buf *bytes.Buffer, record *model.SessionRecord) error {
+	return json.NewEncoder(buf).Encode(jsonRecord{
+		Start:         record.Start,
+		Frontend:      record.Frontend,
+		Client:        record.Client,
+		Backend:       record.Backend,
+		DurationMS:    float64(record.Duration.Microseconds()) / 1000,
+		BytesSent:     record.BytesSent,
+		BytesReceived: record.BytesReceived,
+		CloseReason:   record.CloseReason,
+		Retries:       record.Retries,
+		TLSVersion:    record.TLSVersion,
+		ServerName:    record.ServerName,
+		ALPN:          record.ALPN,
+	})
+}
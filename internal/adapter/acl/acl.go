@@ -0,0 +1,226 @@
+package acl
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/filewatch"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+)
+
+type Action string
+
+const (
+	Allow Action = "allow"
+	Deny  Action = "deny"
+)
+
+// Rule allows or denies clients within Prefix. Source says where the rule
+// was defined, such as "config" or "path:line".
+type Rule struct {
+	Action Action
+	Prefix netip.Prefix
+	Source string
+}
+
+func (r *Rule) String() string {
+	return fmt.Sprintf("%s %s (%s)", r.Action, r.Prefix, r.Source)
+}
+
+// Options lists inline rules and an optional file with more rules, one
+// "allow <cidr>" or "deny <cidr>" per line. A bare IP is a single address.
+type Options struct {
+	Allow []string
+	Deny  []string
+	File  string
+
+	// OnReload, if set, is called after every reload done by Watch.
+	OnReload func(err error)
+}
+
+// ACL decides whether a client may connect. The most specific matching
+// rule wins, with deny winning over allow for the same prefix. Clients that
+// match no rule are allowed unless there are allow rules.
+type ACL struct {
+	opts    Options
+	current atomic.Pointer[table]
+	watcher *filewatch.Watcher
+	logger  *logger.Logger
+}
+
+type table struct {
+	rules        trie
+	defaultAllow bool
+}
+
+func New(opts Options, logger *logger.Logger) (*ACL, error) {
+	a := &ACL{
+		opts:   opts,
+		logger: logger,
+	}
+	if opts.File != "" {
+		a.watcher = filewatch.New(opts.File)
+	}
+	if err := a.Reload(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Reload rebuilds the rules from the options and the rule file. On error
+// the previous rules stay in effect.
+func (a *ACL) Reload() error {
+	rules, err := inlineRules(a.opts)
+	if err != nil {
+		return err
+	}
+	if a.opts.File != "" {
+		f, err := os.Open(a.opts.File)
+		if err != nil {
+			return fmt.Errorf("failed to open ACL file: %w", err)
+		}
+		defer f.Close()
+
+		fileRules, err := ParseRules(f, a.opts.File)
+		if err != nil {
+			return err
+		}
+		rules = append(rules, fileRules...)
+	}
+
+	t := &table{defaultAllow: true}
+	for i := range rules {
+		if rules[i].Action == Allow {
+			t.defaultAllow = false
+		}
+		t.rules.insert(&rules[i])
+	}
+	a.current.Store(t)
+	return nil
+}
+
+// Watch reloads the rule file whenever it changes. It blocks until ctx is
+// cancelled and returns right away if there is no file.
+func (a *ACL) Watch(ctx context.Context, interval time.Duration) {
+	if a.watcher == nil {
+		return
+	}
+	a.watcher.Run(ctx, interval, func() {
+		err := a.Reload()
+		if err != nil {
+			a.logger.Warnf("Failed to reload ACL, keeping previous rules: %v", err)
+		} else {
+			a.logger.Infof("ACL reloaded from %s", a.opts.File)
+		}
+		if a.opts.OnReload != nil {
+			a.opts.OnReload(err)
+		}
+	})
+}
+
+// Check reports whether client may connect and describes the rule that
+// decided it. Clients without an IP address, such as Unix socket peers,
+// are always allowed.
+func (a *ACL) Check(client net.Addr) (bool, string) {
+	ip, ok := clientIP(client)
+	if !ok {
+		return true, ""
+	}
+
+	t := a.current.Load()
+	if match := t.rules.lookup(ip); match != nil {
+		return match.rule.Action == Allow, match.desc
+	}
+	if t.defaultAllow {
+		return true, "default allow"
+	}
+	return false, "default deny"
+}
+
+func clientIP(client net.Addr) (netip.Addr, bool) {
+	if tcpAddr, ok := client.(*net.TCPAddr); ok {
+		ip, ok := netip.AddrFromSlice(tcpAddr.IP)
+		return ip.Unmap(), ok
+	}
+	addrPort, err := netip.ParseAddrPort(client.String())
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addrPort.Addr().Unmap(), true
+}
+
+// ParseRules reads one rule per line. Blank lines and text after "#" are
+// ignored.
+func ParseRules(r io.Reader, source string) ([]Rule, error) {
+	var rules []Rule
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected \"allow <cidr>\" or \"deny <cidr>\"", source, line)
+		}
+
+		action := Action(strings.ToLower(fields[0]))
+		if action != Allow && action != Deny {
+			return nil, fmt.Errorf("%s:%d: unknown action %q", source, line, fields[0])
+		}
+		prefix, err := ParsePrefix(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", source, line, err)
+		}
+		rules = append(rules, Rule{Action: action, Prefix: prefix, Source: fmt.Sprintf("%s:%d", source, line)})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", source, err)
+	}
+	return rules, nil
+}
+
+// ParsePrefix parses a CIDR or a single IP address.
+func ParsePrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid CIDR %q", s)
+		}
+		if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid IP address %q", s)
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+func inlineRules(opts Options) ([]Rule, error) {
+	rules := make([]Rule, 0, len(opts.Allow)+len(opts.Deny))
+	for _, list := range []struct {
+		action  Action
+		entries []string
+	}{{Allow, opts.Allow}, {Deny, opts.Deny}} {
+		for _, entry := range list.entries {
+			prefix, err := ParsePrefix(entry)
+			if err != nil {
+				return nil, err
+			}
+			rules = append(rules, Rule{Action: list.action, Prefix: prefix, Source: "config"})
+		}
+	}
+	return rules, nil
+}
@@ -0,0 +1,72 @@
+package acl
+
+import "net/netip"
+
+// trie is a binary trie over address bits with one root per address family.
+// Lookups walk at most 32 or 128 nodes regardless of the number of rules.
+type trie struct {
+	v4 node
+	v6 node
+}
+
+type node struct {
+	children [2]*node
+	rule     *Rule
+	desc     string
+}
+
+// insert stores rule at its prefix. When an allow and a deny rule share a
+// prefix, the deny rule is kept.
+func (t *trie) insert(rule *Rule) {
+	prefix := rule.Prefix.Masked()
+	addr := prefix.Addr()
+
+	n := t.root(addr)
+	for i := 0; i < prefix.Bits(); i++ {
+		b := bit(addr, i)
+		if n.children[b] == nil {
+			n.children[b] = &node{}
+		}
+		n = n.children[b]
+	}
+
+	if n.rule == nil || n.rule.Action != Deny {
+		n.rule = rule
+		n.desc = rule.String()
+	}
+}
+
+// lookup returns the node holding the rule with the longest prefix
+// containing addr, or nil.
+func (t *trie) lookup(addr netip.Addr) *node {
+	n := t.root(addr)
+	var match *node
+	for i := 0; ; i++ {
+		if n.rule != nil {
+			match = n
+		}
+		if i == addr.BitLen() {
+			break
+		}
+		if n = n.children[bit(addr, i)]; n == nil {
+			break
+		}
+	}
+	return match
+}
+
+func (t *trie) root(addr netip.Addr) *node {
+	if addr.Is4() {
+		return &t.v4
+	}
+	return &t.v6
+}
+
+func bit(addr netip.Addr, i int) int {
+	if addr.Is4() {
+		b := addr.As4()
+		return int(b[i/8]>>(7-i%8)) & 1
+	}
+	b := addr.As16()
+	return int(b[i/8]>>(7-i%8)) & 1
+}
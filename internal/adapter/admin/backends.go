@@ -0,0 +1,57 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/port"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+)
+
+// BackendsHandler serves the admin API for backends, keyed by pool name:
+//
+//	PUT /pools/{pool}/backends/{id}/admin_state?state=enabled|drain|disabled
+type BackendsHandler struct {
+	pools  map[string]port.BackendAdmin
+	logger *logger.Logger
+}
+
+func NewBackendsHandler(pools map[string]port.BackendAdmin, logger *logger.Logger) *BackendsHandler {
+	return &BackendsHandler{
+		pools:  pools,
+		logger: logger,
+	}
+}
+
+func (h *BackendsHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("PUT /pools/{pool}/backends/{id}/admin_state", h.setAdminState)
+}
+
+func (h *BackendsHandler) setAdminState(w http.ResponseWriter, r *http.Request) {
+	name, id := r.PathValue("pool"), r.PathValue("id")
+	pool, ok := h.pools[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no pool %q", name))
+		return
+	}
+	state := r.URL.Query().Get("state")
+	if !model.ValidAdminState(state) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid admin state %q", state))
+		return
+	}
+
+	if err := pool.SetAdminState(r.Context(), id, state); err != nil {
+		if errors.Is(err, model.ErrBackendNotFound) {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("no backend %q in pool %q", id, name))
+			return
+		}
+		h.logger.Errorf("Failed to set admin state of %s/%s: %v", name, id, err)
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.logger.Warnf("Backend %s/%s set to %s via admin API from %s", name, id, state, r.RemoteAddr)
+	writeJSON(w, http.StatusOK, map[string]string{"pool": name, "id": id, "admin_state": state})
+}
@@ -0,0 +1,113 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/port"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+)
+
+// EventsHeartbeat is how often an idle event stream gets a comment line, so
+// proxies and clients notice dead connections.
+const EventsHeartbeat = 15 * time.Second
+
+// EventsHandler streams events as server-sent events:
+//
+//	GET /events?type=backend_down,backend_up&frontend=&pool=&backend=
+//
+// Clients resuming with a Last-Event-ID header first get the events they
+// missed, as far as they are still retained.
+type EventsHandler struct {
+	events port.EventStream
+	logger *logger.Logger
+}
+
+func NewEventsHandler(events port.EventStream, logger *logger.Logger) *EventsHandler {
+	return &EventsHandler{
+		events: events,
+		logger: logger,
+	}
+}
+
+func (h *EventsHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /events", h.stream)
+}
+
+type eventJSON struct {
+	ID       uint64    `json:"id"`
+	Time     time.Time `json:"time"`
+	Type     string    `json:"type"`
+	Frontend string    `json:"frontend,omitempty"`
+	Pool     string    `json:"pool,omitempty"`
+	Backend  string    `json:"backend,omitempty"`
+	Client   string    `json:"client,omitempty"`
+	Reason   string    `json:"reason,omitempty"`
+	Message  string    `json:"message,omitempty"`
+}
+
+func (h *EventsHandler) stream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	query := r.URL.Query()
+	filter := model.EventFilter{
+		Frontend: query.Get("frontend"),
+		Pool:     query.Get("pool"),
+		Backend:  query.Get("backend"),
+	}
+	if v := query.Get("type"); v != "" {
+		filter.Types = strings.Split(v, ",")
+	}
+	var after uint64
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid Last-Event-ID %q", v))
+			return
+		}
+		after = id
+	}
+
+	events, cancel := h.events.Subscribe(filter, after)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	h.logger.Debugf("Event stream opened by %s", r.RemoteAddr)
+	defer h.logger.Debugf("Event stream closed for %s", r.RemoteAddr)
+
+	heartbeat := time.NewTicker(EventsHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, _ := json.Marshal(eventJSON(event))
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,161 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/port"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+)
+
+// SessionsHandler serves the admin API for active sessions:
+//
+//	GET    /sessions?frontend=&backend=&client=&min_idle=&limit=
+//	DELETE /sessions/{id}
+//	DELETE /sessions?backend=
+//
+// Terminations are published to events, if it is not nil.
+type SessionsHandler struct {
+	sessions port.SessionManager
+	events   port.EventPublisher
+	logger   *logger.Logger
+}
+
+func NewSessionsHandler(sessions port.SessionManager, events port.EventPublisher, logger *logger.Logger) *SessionsHandler {
+	return &SessionsHandler{
+		sessions: sessions,
+		events:   events,
+		logger:   logger,
+	}
+}
+
+func (h *SessionsHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /sessions", h.list)
+	mux.HandleFunc("DELETE /sessions/{id}", h.kill)
+	mux.HandleFunc("DELETE /sessions", h.killBackend)
+}
+
+type sessionJSON struct {
+	ID              uint64    `json:"id"`
+	Frontend        string    `json:"frontend"`
+	Client          string    `json:"client"`
+	Backend         string    `json:"backend"`
+	Start           time.Time `json:"start"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	IdleSeconds     float64   `json:"idle_seconds"`
+	BytesSent       int64     `json:"bytes_sent"`
+	BytesReceived   int64     `json:"bytes_received"`
+}
+
+func (h *SessionsHandler) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := model.SessionFilter{
+		Frontend: query.Get("frontend"),
+		Backend:  query.Get("backend"),
+		Client:   query.Get("client"),
+	}
+	if v := query.Get("min_idle"); v != "" {
+		minIdle, err := time.ParseDuration(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid min_idle %q", v))
+			return
+		}
+		filter.MinIdle = minIdle
+	}
+	limit := 0
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid limit %q", v))
+			return
+		}
+		limit = n
+	}
+
+	infos := h.sessions.Sessions(filter)
+	total := len(infos)
+	if limit > 0 && len(infos) > limit {
+		infos = infos[:limit]
+	}
+
+	now := time.Now()
+	sessions := make([]sessionJSON, len(infos))
+	for i, info := range infos {
+		sessions[i] = sessionJSON{
+			ID:              info.ID,
+			Frontend:        info.Frontend,
+			Client:          info.Client,
+			Backend:         info.Backend,
+			Start:           info.Start,
+			DurationSeconds: now.Sub(info.Start).Seconds(),
+			IdleSeconds:     info.Idle.Seconds(),
+			BytesSent:       info.BytesSent,
+			BytesReceived:   info.BytesReceived,
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"total":    total,
+		"sessions": sessions,
+	})
+}
+
+func (h *SessionsHandler) kill(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid session id %q", r.PathValue("id")))
+		return
+	}
+	if !h.sessions.Kill(id) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no active session %d", id))
+		return
+	}
+
+	h.logger.Warnf("Session %d terminated via admin API from %s", id, r.RemoteAddr)
+	h.publish(model.Event{
+		Type:    model.EventSessionKilled,
+		Reason:  "admin",
+		Message: fmt.Sprintf("session %d terminated via admin API from %s", id, r.RemoteAddr),
+	})
+	writeJSON(w, http.StatusOK, map[string]int{"killed": 1})
+}
+
+// killBackend requires a backend so that a bare DELETE cannot drop every
+// session at once.
+func (h *SessionsHandler) killBackend(w http.ResponseWriter, r *http.Request) {
+	backend := r.URL.Query().Get("backend")
+	if backend == "" {
+		writeError(w, http.StatusBadRequest, "backend is required")
+		return
+	}
+
+	killed := h.sessions.KillBackend(backend)
+	h.logger.Warnf("%d sessions to %s terminated via admin API from %s", killed, backend, r.RemoteAddr)
+	h.publish(model.Event{
+		Type:    model.EventSessionKilled,
+		Backend: backend,
+		Reason:  "admin",
+		Message: fmt.Sprintf("%d sessions terminated via admin API from %s", killed, r.RemoteAddr),
+	})
+	writeJSON(w, http.StatusOK, map[string]int{"killed": killed})
+}
+
+func (h *SessionsHandler) publish(event model.Event) {
+	if h.events != nil {
+		h.events.Publish(event)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
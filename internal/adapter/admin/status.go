@@ -0,0 +1,229 @@
+package admin
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/port"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+)
+
+// DefaultStatusRefresh is how often the HTML status page reloads itself.
+const DefaultStatusRefresh = 10 * time.Second
+
+//go:embed status.html
+var statusPage string
+
+// StatusHandler serves the status page:
+//
+//	GET /status?refresh=
+//	GET /status?format=json
+type StatusHandler struct {
+	status port.StatusProvider
+	logger *logger.Logger
+	page   *template.Template
+}
+
+func NewStatusHandler(status port.StatusProvider, logger *logger.Logger) *StatusHandler {
+	return &StatusHandler{
+		status: status,
+		logger: logger,
+		page: template.Must(template.New("status").Funcs(template.FuncMap{
+			"bytes":  formatBytes,
+			"since":  formatSince,
+			"counts": formatCounts,
+			"sum":    sumCounts,
+		}).Parse(statusPage)),
+	}
+}
+
+func (h *StatusHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /status", h.serve)
+}
+
+type statusJSON struct {
+	Version       string         `json:"version"`
+	Started       time.Time      `json:"started"`
+	UptimeSeconds float64        `json:"uptime_seconds"`
+	Frontends     []frontendJSON `json:"frontends"`
+}
+
+type frontendJSON struct {
+	Name           string           `json:"name"`
+	Protocol       string           `json:"protocol"`
+	Listen         string           `json:"listen"`
+	Algorithm      string           `json:"algorithm"`
+	ActiveSessions int64            `json:"active_sessions"`
+	TotalSessions  int64            `json:"total_sessions"`
+	QueueDepth     int64            `json:"queue_depth"`
+	Errors         map[string]int64 `json:"errors"`
+	Rejected       map[string]int64 `json:"rejected"`
+	Pools          []poolJSON       `json:"pools"`
+}
+
+type poolJSON struct {
+	Name     string        `json:"name"`
+	Backends []backendJSON `json:"backends"`
+}
+
+type backendJSON struct {
+	ID             string            `json:"id"`
+	Address        string            `json:"address"`
+	State          string            `json:"state"`
+	AdminState     string            `json:"admin_state"`
+	Weight         int               `json:"weight"`
+	MaxConnections int               `json:"max_connections,omitempty"`
+	ActiveSessions int64             `json:"active_sessions"`
+	TotalSessions  int64             `json:"total_sessions"`
+	BytesSent      int64             `json:"bytes_sent"`
+	BytesReceived  int64             `json:"bytes_received"`
+	Errors         map[string]int64  `json:"errors"`
+	LastChange     *time.Time        `json:"last_change,omitempty"`
+	HealthChecks   []healthCheckJSON `json:"health_checks"`
+}
+
+type healthCheckJSON struct {
+	Time    time.Time `json:"time"`
+	Healthy bool      `json:"healthy"`
+}
+
+func (h *StatusHandler) serve(w http.ResponseWriter, r *http.Request) {
+	status := h.status.Status(r.Context())
+
+	if r.URL.Query().Get("format") == "json" {
+		writeJSON(w, http.StatusOK, toStatusJSON(status))
+		return
+	}
+
+	refresh := DefaultStatusRefresh
+	if v := r.URL.Query().Get("refresh"); v != "" {
+		seconds, err := strconv.Atoi(v)
+		if err != nil || seconds < 0 {
+			http.Error(w, fmt.Sprintf("invalid refresh %q", v), http.StatusBadRequest)
+			return
+		}
+		refresh = time.Duration(seconds) * time.Second
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err := h.page.Execute(w, map[string]any{
+		"Status":  status,
+		"Uptime":  time.Since(status.Started).Round(time.Second),
+		"Refresh": int(refresh.Seconds()),
+		"Now":     time.Now(),
+	})
+	if err != nil {
+		h.logger.Errorf("Failed to render status page: %v", err)
+	}
+}
+
+func toStatusJSON(status model.Status) statusJSON {
+	out := statusJSON{
+		Version:       status.Version,
+		Started:       status.Started,
+		UptimeSeconds: time.Since(status.Started).Seconds(),
+		Frontends:     make([]frontendJSON, 0, len(status.Frontends)),
+	}
+	for _, fe := range status.Frontends {
+		feJSON := frontendJSON{
+			Name:           fe.Name,
+			Protocol:       fe.Protocol,
+			Listen:         fe.Listen,
+			Algorithm:      fe.Algorithm,
+			ActiveSessions: fe.ActiveSessions,
+			TotalSessions:  fe.TotalSessions,
+			QueueDepth:     fe.QueueDepth,
+			Errors:         fe.Errors,
+			Rejected:       fe.Rejected,
+			Pools:          make([]poolJSON, 0, len(fe.Pools)),
+		}
+		for _, pool := range fe.Pools {
+			pJSON := poolJSON{Name: pool.Name, Backends: make([]backendJSON, 0, len(pool.Backends))}
+			for _, b := range pool.Backends {
+				bJSON := backendJSON{
+					ID:             b.ID,
+					Address:        b.Address,
+					State:          backendState(b.Healthy),
+					AdminState:     b.AdminState,
+					Weight:         b.Weight,
+					MaxConnections: b.MaxConnections,
+					ActiveSessions: b.ActiveSessions,
+					TotalSessions:  b.TotalSessions,
+					BytesSent:      b.BytesSent,
+					BytesReceived:  b.BytesReceived,
+					Errors:         b.Errors,
+					HealthChecks:   make([]healthCheckJSON, len(b.HealthChecks)),
+				}
+				if !b.LastChange.IsZero() {
+					lastChange := b.LastChange
+					bJSON.LastChange = &lastChange
+				}
+				for i, check := range b.HealthChecks {
+					bJSON.HealthChecks[i] = healthCheckJSON{Time: check.Time, Healthy: check.Healthy}
+				}
+				pJSON.Backends = append(pJSON.Backends, bJSON)
+			}
+			feJSON.Pools = append(feJSON.Pools, pJSON)
+		}
+		out.Frontends = append(out.Frontends, feJSON)
+	}
+	return out
+}
+
+func backendState(healthy bool) string {
+	if healthy {
+		return "up"
+	}
+	return "down"
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func formatSince(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return time.Since(t).Round(time.Second).String() + " ago"
+}
+
+// formatCounts renders counts as "a=1, b=2", sorted by key.
+func formatCounts(counts map[string]int64) string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	s := ""
+	for i, k := range keys {
+		if i > 0 {
+			s += ", "
+		}
+		s += fmt.Sprintf("%s=%d", k, counts[k])
+	}
+	return s
+}
+
+func sumCounts(counts map[string]int64) int64 {
+	var total int64
+	for _, n := range counts {
+		total += n
+	}
+	return total
+}
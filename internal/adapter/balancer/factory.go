@@ -0,0 +1,16 @@
+package balancer
+
+import (
+	"fmt"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/port"
+)
+
+func NewByAlgorithm(algorithm string) (port.LoadBalancer, error) {
+	switch algorithm {
+	case "", "round_robin", "roundrobin":
+		return New(), nil
+	default:
+		return nil, fmt.Errorf("unknown load balancing algorithm %q", algorithm)
+	}
+}
@@ -15,6 +15,8 @@ func Load(configPath string) (*cfg.Config, error) {
 	viper.SetDefault("server.port", 8080)
 	viper.SetDefault("server.host", "localhost")
 	viper.SetDefault("app.environment", "development")
+	viper.SetDefault("metrics.go_collector", true)
+	viper.SetDefault("metrics.process_collector", true)
 
 	viper.AutomaticEnv()
 	viper.SetEnvPrefix("LB")
@@ -28,5 +30,10 @@ func Load(configPath string) (*cfg.Config, error) {
 		return nil, fmt.Errorf("error unmarshaling config: %w", err)
 	}
 
+	config.ApplyDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
 	return &config, nil
 }
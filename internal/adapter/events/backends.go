@@ -0,0 +1,47 @@
+package events
+
+import (
+	"fmt"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/port"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+)
+
+// PublishBackendChanges logs the changes a Watch of pool's repository
+// delivers and publishes them until the channel is closed: health changes
+// as backend_up and backend_down, admin state changes as
+// backend_admin_changed and removals from the pool as backend_ejected. The
+// events name the pool but no frontend, since the pool may serve several.
+func PublishBackendChanges(changes <-chan model.BackendChange, events port.EventPublisher, pool string, log *logger.Logger) {
+	for change := range changes {
+		address := change.Backend.GetAddress()
+		event := model.Event{Pool: pool, Backend: address}
+
+		switch change.Type {
+		case model.BackendHealthChanged:
+			if change.Healthy {
+				event.Type = model.EventBackendUp
+				event.Message = fmt.Sprintf("Backend %s recovered", address)
+				log.Infof("%s", event.Message)
+			} else {
+				event.Type = model.EventBackendDown
+				event.Message = fmt.Sprintf("Backend %s went down", address)
+				log.Warnf("%s", event.Message)
+			}
+		case model.BackendAdminChanged:
+			event.Type = model.EventBackendAdminChanged
+			event.Reason = change.AdminState
+			event.Message = fmt.Sprintf("Backend %s set to %s", address, change.AdminState)
+			log.Infof("%s", event.Message)
+		case model.BackendRemoved:
+			event.Type = model.EventBackendEjected
+			event.Reason = "removed"
+			event.Message = fmt.Sprintf("Backend %s removed from pool %s", address, pool)
+			log.Infof("%s", event.Message)
+		default:
+			continue
+		}
+		events.Publish(event)
+	}
+}
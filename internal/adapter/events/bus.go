@@ -0,0 +1,138 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/port"
+)
+
+const (
+	// DefaultHistory is how many past events are kept for subscribers
+	// resuming after a disconnect.
+	DefaultHistory = 256
+
+	subscriberBuffer = 64
+)
+
+type subscriber struct {
+	filter model.EventFilter
+	ch     chan model.Event
+}
+
+// Bus fans published events out to subscribers. Publishing never blocks:
+// a subscriber whose buffer is full is disconnected and can resume from
+// the history with the last ID it saw.
+type Bus struct {
+	mu      sync.Mutex
+	nextID  uint64
+	history []model.Event
+	size    int
+	subs    map[*subscriber]struct{}
+	closed  bool
+}
+
+func NewBus(history int) *Bus {
+	if history <= 0 {
+		history = DefaultHistory
+	}
+	return &Bus{
+		size: history,
+		subs: make(map[*subscriber]struct{}),
+	}
+}
+
+func (b *Bus) Publish(event model.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+
+	b.nextID++
+	event.ID = b.nextID
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	if len(b.history) == b.size {
+		copy(b.history, b.history[1:])
+		b.history = b.history[:b.size-1]
+	}
+	b.history = append(b.history, event)
+
+	for sub := range b.subs {
+		if !sub.filter.Matches(event) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			delete(b.subs, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+func (b *Bus) Subscribe(filter model.EventFilter, after uint64) (<-chan model.Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var replay []model.Event
+	if after > 0 {
+		for _, event := range b.history {
+			if event.ID > after && filter.Matches(event) {
+				replay = append(replay, event)
+			}
+		}
+	}
+
+	sub := &subscriber{filter: filter, ch: make(chan model.Event, len(replay)+subscriberBuffer)}
+	for _, event := range replay {
+		sub.ch <- event
+	}
+	if b.closed {
+		close(sub.ch)
+		return sub.ch, func() {}
+	}
+	b.subs[sub] = struct{}{}
+
+	return sub.ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[sub]; ok {
+			delete(b.subs, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// Close disconnects every subscriber and drops later events.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for sub := range b.subs {
+		delete(b.subs, sub)
+		close(sub.ch)
+	}
+}
+
+// ForFrontend returns a publisher that fills in the frontend of events
+// that don't name one.
+func (b *Bus) ForFrontend(frontend string) port.EventPublisher {
+	return &scoped{bus: b, frontend: frontend}
+}
+
+type scoped struct {
+	bus      *Bus
+	frontend string
+}
+
+func (s *scoped) Publish(event model.Event) {
+	if event.Frontend == "" {
+		event.Frontend = s.frontend
+	}
+	s.bus.Publish(event)
+}
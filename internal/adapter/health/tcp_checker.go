@@ -8,20 +8,37 @@ import (
 	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
 	"github.com/reybrally/TCP-Load-Balancer/internal/domain/port"
 	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/proxyproto"
 )
 
 type TCPChecker struct {
 	timeout time.Duration
 	metrics port.MetricsCollector
 	logger  *logger.Logger
+
+	proxyProtocolVersion int
+}
+
+type Option func(*TCPChecker)
+
+// WithProxyProtocol sends a PROXY header with the LOCAL command on each check,
+// so backends that require the PROXY protocol accept the connection.
+func WithProxyProtocol(version int) Option {
+	return func(tc *TCPChecker) {
+		tc.proxyProtocolVersion = version
+	}
 }
 
-func New(timeout time.Duration, metrics port.MetricsCollector, logger *logger.Logger) *TCPChecker {
-	return &TCPChecker{
+func New(timeout time.Duration, metrics port.MetricsCollector, logger *logger.Logger, opts ...Option) *TCPChecker {
+	tc := &TCPChecker{
 		timeout: timeout,
 		metrics: metrics,
 		logger:  logger,
 	}
+	for _, opt := range opts {
+		opt(tc)
+	}
+	return tc
 }
 
 func (tc *TCPChecker) Check(ctx context.Context, backend *model.Backend) bool {
@@ -35,7 +52,13 @@ func (tc *TCPChecker) Check(ctx context.Context, backend *model.Backend) bool {
 	defer cancel()
 
 	dialer := &net.Dialer{}
-	conn, err := dialer.DialContext(checkCtx, "tcp", backendAddr)
+	conn, err := dialer.DialContext(checkCtx, backend.GetNetwork(), backendAddr)
+	if err == nil && tc.proxyProtocolVersion != 0 {
+		err = tc.sendLocal(checkCtx, conn)
+		if err != nil {
+			conn.Close()
+		}
+	}
 	if err != nil {
 		tc.logger.Debugf("Health check failed for %s: %v", backendAddr, err)
 		tc.metrics.IncHealthChecksTotal(backendAddr, "failed")
@@ -49,3 +72,16 @@ func (tc *TCPChecker) Check(ctx context.Context, backend *model.Backend) bool {
 	tc.metrics.SetBackendHealthStatus(backendAddr, true)
 	return true
 }
+
+func (tc *TCPChecker) sendLocal(ctx context.Context, conn net.Conn) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetWriteDeadline(deadline)
+	}
+
+	header := &proxyproto.Header{
+		Version: tc.proxyProtocolVersion,
+		Command: proxyproto.CommandLocal,
+	}
+	_, err := header.WriteTo(conn)
+	return err
+}
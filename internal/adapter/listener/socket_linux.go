@@ -0,0 +1,57 @@
+//go:build linux
+
+package listener
+
+import (
+	"context"
+	"net"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+func listenTCP(addr string, reusePort bool, backlog int) (net.Listener, error) {
+	lc := net.ListenConfig{}
+	if reusePort {
+		lc.Control = func(network, address string, c syscall.RawConn) error {
+			var sockErr error
+			err := c.Control(func(fd uintptr) {
+				sockErr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+			})
+			if err != nil {
+				return err
+			}
+			return sockErr
+		}
+	}
+
+	listener, err := lc.Listen(context.Background(), "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if backlog > 0 {
+		if err := setBacklog(listener.(*net.TCPListener), backlog); err != nil {
+			listener.Close()
+			return nil, err
+		}
+	}
+	return listener, nil
+}
+
+// setBacklog calls listen(2) again on the bound socket; Linux resizes the
+// accept queue of a socket that is already listening.
+func setBacklog(listener syscall.Conn, backlog int) error {
+	rc, err := listener.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var listenErr error
+	err = rc.Control(func(fd uintptr) {
+		listenErr = unix.Listen(int(fd), backlog)
+	})
+	if err != nil {
+		return err
+	}
+	return listenErr
+}
@@ -0,0 +1,22 @@
+//go:build !linux
+
+package listener
+
+import (
+	"errors"
+	"net"
+	"syscall"
+)
+
+var errUnsupportedSocketOption = errors.New("SO_REUSEPORT and backlog tuning are only supported on linux")
+
+func listenTCP(addr string, reusePort bool, backlog int) (net.Listener, error) {
+	if reusePort || backlog > 0 {
+		return nil, errUnsupportedSocketOption
+	}
+	return net.Listen("tcp", addr)
+}
+
+func setBacklog(listener syscall.Conn, backlog int) error {
+	return errUnsupportedSocketOption
+}
@@ -1,58 +1,462 @@
 package listener
 
 import (
+	"bufio"
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"net"
+	"os"
+	"sync"
+	"time"
 
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/tlsutil"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
 	"github.com/reybrally/TCP-Load-Balancer/internal/domain/port"
 	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/proxyproto"
+)
+
+const (
+	DefaultHandshakeTimeout   = 10 * time.Second
+	DefaultProxyHeaderTimeout = 5 * time.Second
 )
 
 type TCPListener struct {
-	listener net.Listener
-	logger   *logger.Logger
+	listeners        []net.Listener
+	conns            sync.WaitGroup
+	slots            chan struct{}
+	closed           chan struct{}
+	closeOnce        sync.Once
+	acceptors        int
+	backlog          int
+	logger           *logger.Logger
+	metrics          port.MetricsCollector
+	limiter          port.ConnectionLimiter
+	acl              port.AccessControl
+	events           port.EventPublisher
+	tlsConfig        *tls.Config
+	handshakeTimeout time.Duration
+
+	proxyProtocol      bool
+	proxyTrusted       []*net.IPNet
+	proxyHeaderTimeout time.Duration
+}
+
+type Option func(*TCPListener)
+
+// WithMaxConnections caps the number of connections the listener serves at
+// once. Further clients wait in the kernel accept queue until one finishes.
+func WithMaxConnections(max int) Option {
+	return func(tl *TCPListener) {
+		if max > 0 {
+			tl.slots = make(chan struct{}, max)
+		}
+	}
+}
+
+func WithMetrics(metrics port.MetricsCollector) Option {
+	return func(tl *TCPListener) {
+		tl.metrics = metrics
+	}
+}
+
+// WithRateLimit checks every accepted connection against limiter and closes
+// the ones it refuses. With the PROXY protocol enabled the check uses the
+// client address from the header instead of the peer address.
+func WithRateLimit(limiter port.ConnectionLimiter) Option {
+	return func(tl *TCPListener) {
+		tl.limiter = limiter
+	}
+}
+
+// WithACL closes connections from clients the access list denies. Like
+// WithRateLimit, it uses the PROXY header source when that is enabled.
+func WithACL(acl port.AccessControl) Option {
+	return func(tl *TCPListener) {
+		tl.acl = acl
+	}
+}
+
+// WithEvents publishes a connection_rejected event for every connection
+// refused by the access list or rate limits.
+func WithEvents(events port.EventPublisher) Option {
+	return func(tl *TCPListener) {
+		tl.events = events
+	}
+}
+
+// WithTLS terminates TLS on accepted connections before they are passed to
+// the handler, so backends receive the decrypted stream.
+func WithTLS(config *tls.Config, handshakeTimeout time.Duration) Option {
+	return func(tl *TCPListener) {
+		tl.tlsConfig = config
+		if handshakeTimeout > 0 {
+			tl.handshakeTimeout = handshakeTimeout
+		}
+	}
+}
+
+// WithProxyProtocol expects a PROXY v1/v2 header on connections from the
+// trusted networks and exposes the client address it carries through
+// RemoteAddr. Connections from other sources are served as-is. An empty
+// trusted list trusts no source.
+func WithProxyProtocol(trusted []*net.IPNet, headerTimeout time.Duration) Option {
+	return func(tl *TCPListener) {
+		tl.proxyProtocol = true
+		tl.proxyTrusted = trusted
+		if headerTimeout > 0 {
+			tl.proxyHeaderTimeout = headerTimeout
+		}
+	}
+}
+
+// WithReusePort opens the given number of sockets on the same address with
+// SO_REUSEPORT and runs an accept loop on each, letting the kernel spread
+// incoming connections across them. Only supported on Linux.
+func WithReusePort(acceptors int) Option {
+	return func(tl *TCPListener) {
+		tl.acceptors = acceptors
+	}
+}
+
+// WithBacklog sets the length of the kernel accept queue instead of the
+// system default (net.core.somaxconn). Only supported on Linux.
+func WithBacklog(backlog int) Option {
+	return func(tl *TCPListener) {
+		tl.backlog = backlog
+	}
 }
 
-func New(host string, port int, logger *logger.Logger) (*TCPListener, error) {
+func New(host string, port int, logger *logger.Logger, opts ...Option) (*TCPListener, error) {
+	tl := newListener(logger, opts...)
+
 	addr := fmt.Sprintf("%s:%d", host, port)
-	listener, err := net.Listen("tcp", addr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	acceptors := max(tl.acceptors, 1)
+	for i := 0; i < acceptors; i++ {
+		listener, err := listenTCP(addr, tl.acceptors > 0, tl.backlog)
+		if err != nil {
+			tl.Close()
+			return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+		}
+		tl.listeners = append(tl.listeners, listener)
+		// Port 0 picks a port on the first socket; the rest must share it.
+		addr = listener.Addr().String()
+	}
+
+	return tl, nil
+}
+
+func newListener(logger *logger.Logger, opts ...Option) *TCPListener {
+	tl := &TCPListener{
+		closed:             make(chan struct{}),
+		logger:             logger,
+		handshakeTimeout:   DefaultHandshakeTimeout,
+		proxyHeaderTimeout: DefaultProxyHeaderTimeout,
+	}
+	for _, opt := range opts {
+		opt(tl)
 	}
+	return tl
+}
 
-	return &TCPListener{
-		listener: listener,
-		logger:   logger,
-	}, nil
+func (tl *TCPListener) Addr() net.Addr {
+	return tl.listeners[0].Addr()
 }
 
 func (tl *TCPListener) Listen(ctx context.Context, handler port.ConnectionHandler) error {
-	tl.logger.Infof("Listening on %v", tl.listener.Addr())
+	if len(tl.listeners) == 1 {
+		tl.logger.Infof("Listening on %v", tl.Addr())
+		return tl.accept(ctx, tl.listeners[0], handler)
+	}
+
+	tl.logger.Infof("Listening on %v with %d acceptors", tl.Addr(), len(tl.listeners))
+
+	errs := make(chan error, len(tl.listeners))
+	for _, listener := range tl.listeners {
+		go func() {
+			errs <- tl.accept(ctx, listener, handler)
+		}()
+	}
+
+	var firstErr error
+	for range tl.listeners {
+		if err := <-errs; err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
 
+func (tl *TCPListener) accept(ctx context.Context, listener net.Listener, handler port.ConnectionHandler) error {
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 		default:
-			conn, err := tl.listener.Accept()
+			if !tl.acquireSlot(ctx) {
+				return nil
+			}
+
+			conn, err := listener.Accept()
+			accepted := time.Now()
 			if err != nil {
-				if ctx.Err() != nil {
+				tl.releaseSlot()
+				if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
 					return nil
 				}
 				tl.logger.Warnf("Error accepting connection: %v", err)
 				continue
 			}
 
+			// Behind the PROXY protocol the client address is only known
+			// once the header is read, so serve admits the client instead.
+			release, ok := func() {}, true
+			if !tl.proxyProtocol {
+				release, ok = tl.admit(conn)
+			}
+			if !ok {
+				tl.releaseSlot()
+				continue
+			}
+
+			// Cancelling ctx stops accepting; connections already accepted
+			// are left to finish and can be waited for with Drain.
+			tl.conns.Add(1)
 			go func() {
-				if err := handler.Handle(ctx, conn); err != nil {
-					tl.logger.Debugf("Error handling connection: %v", err)
-				}
+				defer tl.conns.Done()
+				defer tl.releaseSlot()
+				defer release()
+				tl.serve(port.WithAcceptTime(context.WithoutCancel(ctx), accepted), conn, handler)
 			}()
 		}
 	}
 }
 
+// acquireSlot blocks while the listener is at its connection limit, leaving
+// further clients in the kernel accept queue. It returns false once ctx is
+// done or the listener is closed.
+func (tl *TCPListener) acquireSlot(ctx context.Context) bool {
+	if tl.slots == nil {
+		return true
+	}
+	select {
+	case tl.slots <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-tl.closed:
+		return false
+	}
+}
+
+func (tl *TCPListener) releaseSlot() {
+	if tl.slots != nil {
+		<-tl.slots
+	}
+}
+
+func (tl *TCPListener) serve(ctx context.Context, conn net.Conn, handler port.ConnectionHandler) {
+	if tl.proxyProtocol && tl.trusted(conn.RemoteAddr()) {
+		proxyConn, err := tl.readProxyHeader(conn)
+		if err != nil {
+			tl.logger.Debugf("Invalid PROXY header from %s: %v", conn.RemoteAddr(), err)
+			if tl.metrics != nil {
+				tl.metrics.IncConnectionErrors("all", "proxy_protocol_invalid")
+			}
+			conn.Close()
+			return
+		}
+		conn = proxyConn
+	}
+	if tl.proxyProtocol {
+		release, ok := tl.admit(conn)
+		if !ok {
+			return
+		}
+		defer release()
+	}
+
+	if tl.tlsConfig != nil {
+		tlsConn, err := tl.handshake(ctx, conn)
+		if err != nil {
+			reason := tlsutil.HandshakeErrorReason(err)
+			tl.logger.Debugf("TLS handshake with %s failed (%s): %v", conn.RemoteAddr(), reason, err)
+			if tl.metrics != nil {
+				tl.metrics.IncTLSHandshakeErrors(reason)
+			}
+			conn.Close()
+			return
+		}
+		conn = tlsConn
+	}
+
+	if err := handler.Handle(ctx, conn); err != nil {
+		tl.logger.Debugf("Error handling connection: %v", err)
+	}
+}
+
+// admit applies the access list and then the rate limiter to conn, closing
+// it if either refuses.
+func (tl *TCPListener) admit(conn net.Conn) (func(), bool) {
+	if tl.acl != nil {
+		allowed, rule := tl.acl.Check(conn.RemoteAddr())
+		if !allowed {
+			// Denials are counted and published by reject; logging each
+			// one at Info would let a scan flood the log.
+			tl.logger.Debugf("Denied connection from %s by %s", conn.RemoteAddr(), rule)
+			tl.reject(conn, "acl_denied")
+			return nil, false
+		}
+		if rule != "" {
+			tl.logger.Debugf("Allowed connection from %s by %s", conn.RemoteAddr(), rule)
+		}
+	}
+
+	if tl.limiter == nil {
+		return func() {}, true
+	}
+	release, reason := tl.limiter.Allow(conn.RemoteAddr())
+	if reason != "" {
+		tl.logger.Debugf("Refusing connection from %s: %s", conn.RemoteAddr(), reason)
+		tl.reject(conn, reason)
+		return nil, false
+	}
+	return release, true
+}
+
+func (tl *TCPListener) reject(conn net.Conn, reason string) {
+	if tl.metrics != nil {
+		tl.metrics.IncRejectedConnections(reason)
+	}
+	if tl.events != nil {
+		tl.events.Publish(model.Event{
+			Type:   model.EventConnectionRejected,
+			Client: conn.RemoteAddr().String(),
+			Reason: reason,
+		})
+	}
+	conn.Close()
+}
+
+func (tl *TCPListener) trusted(addr net.Addr) bool {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	for _, network := range tl.proxyTrusted {
+		if network.Contains(tcpAddr.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+func (tl *TCPListener) readProxyHeader(conn net.Conn) (net.Conn, error) {
+	conn.SetReadDeadline(time.Now().Add(tl.proxyHeaderTimeout))
+	defer conn.SetReadDeadline(time.Time{})
+
+	reader := bufio.NewReader(conn)
+	header, err := proxyproto.Read(reader)
+	if err != nil {
+		return nil, err
+	}
+	return proxyproto.NewConn(conn, reader, header), nil
+}
+
+func (tl *TCPListener) handshake(ctx context.Context, conn net.Conn) (*tls.Conn, error) {
+	handshakeCtx, cancel := context.WithTimeout(ctx, tl.handshakeTimeout)
+	defer cancel()
+
+	tlsConn := tls.Server(conn, tl.tlsConfig)
+	if err := tlsConn.HandshakeContext(handshakeCtx); err != nil {
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
 func (tl *TCPListener) Close() error {
-	return tl.listener.Close()
+	tl.closeOnce.Do(func() { close(tl.closed) })
+
+	var firstErr error
+	for _, listener := range tl.listeners {
+		if err := listener.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// NewFromFiles serves already listening sockets, such as ones passed by
+// systemd socket activation or a hot restart, with one accept loop each. The
+// files are closed; the listener keeps its own descriptors. WithReusePort and
+// WithBacklog have no effect.
+func NewFromFiles(files []*os.File, logger *logger.Logger, opts ...Option) (*TCPListener, error) {
+	tl := newListener(logger, opts...)
+	for _, file := range files {
+		listener, err := net.FileListener(file)
+		file.Close()
+		if err != nil {
+			tl.Close()
+			return nil, fmt.Errorf("failed to use inherited socket %s: %w", file.Name(), err)
+		}
+		tl.listeners = append(tl.listeners, listener)
+	}
+	if len(tl.listeners) == 0 {
+		return nil, fmt.Errorf("no sockets to listen on")
+	}
+	return tl, nil
+}
+
+// Files returns duplicates of the listening sockets for handing over to
+// another process. Call HandedOver once the other process serves them.
+func (tl *TCPListener) Files() ([]*os.File, error) {
+	files := make([]*os.File, 0, len(tl.listeners))
+	closeFiles := func() {
+		for _, file := range files {
+			file.Close()
+		}
+	}
+	for _, listener := range tl.listeners {
+		fl, ok := listener.(interface{ File() (*os.File, error) })
+		if !ok {
+			closeFiles()
+			return nil, fmt.Errorf("listener %v cannot be handed over", listener.Addr())
+		}
+		file, err := fl.File()
+		if err != nil {
+			closeFiles()
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// HandedOver stops Close from removing the socket files of Unix listeners,
+// since another process now serves them.
+func (tl *TCPListener) HandedOver() {
+	for _, listener := range tl.listeners {
+		if unixListener, ok := listener.(*net.UnixListener); ok {
+			unixListener.SetUnlinkOnClose(false)
+		}
+	}
+}
+
+// Drain waits until every connection accepted by the listener has been
+// handled, or until ctx is done. Call it after Close.
+func (tl *TCPListener) Drain(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		tl.conns.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
@@ -0,0 +1,93 @@
+package listener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/port"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+)
+
+const maxDatagramSize = 64 * 1024
+
+type UDPListener struct {
+	conn   *net.UDPConn
+	logger *logger.Logger
+}
+
+func NewUDP(host string, port int, logger *logger.Logger) (*UDPListener, error) {
+	addr, err := net.ResolveUDPAddr("udp", fmt.Sprintf("%s:%d", host, port))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s:%d: %w", host, port, err)
+	}
+
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on udp %s: %w", addr, err)
+	}
+
+	return &UDPListener{
+		conn:   conn,
+		logger: logger,
+	}, nil
+}
+
+// NewUDPFromFile serves an already bound UDP socket, such as one passed by
+// systemd socket activation. The file is closed afterwards.
+func NewUDPFromFile(file *os.File, logger *logger.Logger) (*UDPListener, error) {
+	packetConn, err := net.FilePacketConn(file)
+	file.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to use inherited socket %s: %w", file.Name(), err)
+	}
+
+	conn, ok := packetConn.(*net.UDPConn)
+	if !ok {
+		packetConn.Close()
+		return nil, fmt.Errorf("inherited socket %s is not a UDP socket", file.Name())
+	}
+
+	return &UDPListener{
+		conn:   conn,
+		logger: logger,
+	}, nil
+}
+
+// File returns a duplicate of the socket for handing over to another process.
+func (ul *UDPListener) File() (*os.File, error) {
+	return ul.conn.File()
+}
+
+func (ul *UDPListener) Addr() net.Addr {
+	return ul.conn.LocalAddr()
+}
+
+func (ul *UDPListener) Listen(ctx context.Context, handler port.DatagramHandler) error {
+	ul.logger.Infof("Listening on udp %v", ul.conn.LocalAddr())
+
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, client, err := ul.conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			ul.logger.Warnf("Error reading datagram: %v", err)
+			continue
+		}
+
+		payload := make([]byte, n)
+		copy(payload, buf[:n])
+
+		if err := handler.HandleDatagram(ctx, ul.conn, client, payload); err != nil {
+			ul.logger.Debugf("Error handling datagram from %s: %v", client, err)
+		}
+	}
+}
+
+func (ul *UDPListener) Close() error {
+	return ul.conn.Close()
+}
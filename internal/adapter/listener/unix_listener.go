@@ -0,0 +1,138 @@
+package listener
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+)
+
+// UnixSocket describes the socket file a Unix listener creates. Mode, User
+// and Group are applied after binding; empty values keep the defaults.
+type UnixSocket struct {
+	Path  string
+	Mode  os.FileMode
+	User  string
+	Group string
+}
+
+// NewUnix listens on a Unix socket. A socket file left behind by a previous
+// process is removed first; a path that is still in use or is not a socket
+// is an error.
+func NewUnix(socket UnixSocket, logger *logger.Logger, opts ...Option) (*TCPListener, error) {
+	tl := newListener(logger, opts...)
+	if tl.acceptors > 1 {
+		return nil, fmt.Errorf("SO_REUSEPORT is not supported for unix sockets")
+	}
+
+	if err := removeStaleSocket(socket.Path); err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("unix", socket.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", socket.Path, err)
+	}
+	tl.listeners = []net.Listener{listener}
+
+	if err := applySocketPermissions(socket); err != nil {
+		tl.Close()
+		return nil, err
+	}
+
+	if tl.backlog > 0 {
+		if err := setBacklog(listener.(*net.UnixListener), tl.backlog); err != nil {
+			tl.Close()
+			return nil, err
+		}
+	}
+
+	return tl, nil
+}
+
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err == nil {
+		conn.Close()
+		return fmt.Errorf("socket %s is already in use", path)
+	}
+	if !errors.Is(err, syscall.ECONNREFUSED) {
+		return fmt.Errorf("failed to probe socket %s: %w", path, err)
+	}
+
+	return os.Remove(path)
+}
+
+func applySocketPermissions(socket UnixSocket) error {
+	if socket.Mode != 0 {
+		if err := os.Chmod(socket.Path, socket.Mode); err != nil {
+			return fmt.Errorf("failed to chmod %s: %w", socket.Path, err)
+		}
+	}
+
+	if socket.User == "" && socket.Group == "" {
+		return nil
+	}
+
+	uid, gid := -1, -1
+	if socket.User != "" {
+		id, err := lookupID(socket.User, func(name string) (string, error) {
+			u, err := user.Lookup(name)
+			if err != nil {
+				return "", err
+			}
+			return u.Uid, nil
+		})
+		if err != nil {
+			return fmt.Errorf("unknown socket owner %q: %w", socket.User, err)
+		}
+		uid = id
+	}
+	if socket.Group != "" {
+		id, err := lookupID(socket.Group, func(name string) (string, error) {
+			g, err := user.LookupGroup(name)
+			if err != nil {
+				return "", err
+			}
+			return g.Gid, nil
+		})
+		if err != nil {
+			return fmt.Errorf("unknown socket group %q: %w", socket.Group, err)
+		}
+		gid = id
+	}
+
+	if err := os.Chown(socket.Path, uid, gid); err != nil {
+		return fmt.Errorf("failed to chown %s: %w", socket.Path, err)
+	}
+	return nil
+}
+
+// lookupID accepts either a numeric ID or a name resolved with lookup.
+func lookupID(value string, lookup func(string) (string, error)) (int, error) {
+	if id, err := strconv.Atoi(value); err == nil {
+		return id, nil
+	}
+	id, err := lookup(value)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(id)
+}
@@ -6,81 +6,222 @@ import (
 	"github.com/reybrally/TCP-Load-Balancer/internal/domain/port"
 )
 
+const DefaultFrontend = "default"
+
 type PrometheusMetrics struct {
+	frontend            string
 	connectionsTotal    *prometheus.CounterVec
 	connectionsActive   *prometheus.GaugeVec
 	connectionErrors    *prometheus.CounterVec
 	connectionDuration  *prometheus.HistogramVec
+	bytesSent           *prometheus.CounterVec
+	bytesReceived       *prometheus.CounterVec
+	dialLatency         *prometheus.HistogramVec
+	timeToFirstByte     *prometheus.HistogramVec
 	backendHealthStatus *prometheus.GaugeVec
 	healthChecksTotal   *prometheus.CounterVec
+	tlsHandshakeErrors  *prometheus.CounterVec
+	datagramsTotal      *prometheus.CounterVec
+	datagramBytesTotal  *prometheus.CounterVec
+	queueDepth          *prometheus.GaugeVec
+	queueWait           *prometheus.HistogramVec
+	rejectedConnections *prometheus.CounterVec
+
+	// backendSeries are the vectors with a backend label.
+	backendSeries []*prometheus.MetricVec
 }
 
-func NewPrometheusMetrics() port.MetricsCollector {
-	return &PrometheusMetrics{
-		connectionsTotal: promauto.NewCounterVec(
+// NewPrometheusMetrics registers the load balancer metrics with reg. Use a
+// fresh prometheus.Registry per instance to construct more than one, as in
+// tests.
+func NewPrometheusMetrics(reg prometheus.Registerer) *PrometheusMetrics {
+	factory := promauto.With(reg)
+	pm := &PrometheusMetrics{
+		frontend: DefaultFrontend,
+		connectionsTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "tcp_lb_connections_total",
 				Help: "Total number of TCP connections processed",
 			},
-			[]string{"backend"},
+			[]string{"frontend", "backend"},
 		),
-		connectionsActive: promauto.NewGaugeVec(
+		connectionsActive: factory.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Name: "tcp_lb_connections_active",
 				Help: "Number of currently active TCP connections",
 			},
-			[]string{"backend"},
+			[]string{"frontend", "backend"},
 		),
-		connectionErrors: promauto.NewCounterVec(
+		connectionErrors: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "tcp_lb_connection_errors_total",
 				Help: "Total number of connection errors",
 			},
-			[]string{"backend", "error_type"},
+			[]string{"frontend", "backend", "error_type"},
 		),
-		connectionDuration: promauto.NewHistogramVec(
+		connectionDuration: factory.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Name:    "tcp_lb_connection_duration_seconds",
 				Help:    "Duration of TCP connections in seconds",
 				Buckets: prometheus.DefBuckets,
 			},
-			[]string{"backend"},
+			[]string{"frontend", "backend"},
+		),
+		bytesSent: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "tcp_lb_bytes_sent_total",
+				Help: "Total number of bytes forwarded from clients to backends",
+			},
+			[]string{"frontend", "backend"},
+		),
+		bytesReceived: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "tcp_lb_bytes_received_total",
+				Help: "Total number of bytes forwarded from backends to clients",
+			},
+			[]string{"frontend", "backend"},
+		),
+		dialLatency: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "tcp_lb_backend_dial_duration_seconds",
+				Help:    "Time taken to establish connections to backends",
+				Buckets: prometheus.ExponentialBuckets(0.0005, 2, 14),
+			},
+			[]string{"frontend", "backend"},
+		),
+		timeToFirstByte: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "tcp_lb_backend_time_to_first_byte_seconds",
+				Help:    "Time from connecting to a backend until it sent its first byte",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"frontend", "backend"},
 		),
-		backendHealthStatus: promauto.NewGaugeVec(
+		backendHealthStatus: factory.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Name: "tcp_lb_backend_healthy",
 				Help: "Backend health status (1 = healthy, 0 = unhealthy)",
 			},
-			[]string{"backend"},
+			[]string{"frontend", "backend"},
 		),
-		healthChecksTotal: promauto.NewCounterVec(
+		healthChecksTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "tcp_lb_health_checks_total",
 				Help: "Total number of health checks performed",
 			},
-			[]string{"backend", "status"},
+			[]string{"frontend", "backend", "status"},
+		),
+		tlsHandshakeErrors: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "tcp_lb_tls_handshake_errors_total",
+				Help: "Total number of failed TLS handshakes on the listener",
+			},
+			[]string{"frontend", "reason"},
+		),
+		datagramsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "tcp_lb_udp_packets_total",
+				Help: "Total number of UDP datagrams forwarded",
+			},
+			[]string{"frontend", "backend", "direction"},
+		),
+		datagramBytesTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "tcp_lb_udp_bytes_total",
+				Help: "Total number of UDP payload bytes forwarded",
+			},
+			[]string{"frontend", "backend", "direction"},
+		),
+		queueDepth: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "tcp_lb_queue_depth",
+				Help: "Number of connections waiting for a backend with free capacity",
+			},
+			[]string{"frontend"},
+		),
+		queueWait: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "tcp_lb_queue_wait_seconds",
+				Help:    "Time connections spent waiting in the queue",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"frontend"},
+		),
+		rejectedConnections: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "tcp_lb_connections_rejected_total",
+				Help: "Total number of client connections refused by access lists or per-client limits",
+			},
+			[]string{"frontend", "reason"},
 		),
 	}
+	pm.backendSeries = []*prometheus.MetricVec{
+		pm.connectionsTotal.MetricVec,
+		pm.connectionsActive.MetricVec,
+		pm.connectionErrors.MetricVec,
+		pm.connectionDuration.MetricVec,
+		pm.backendHealthStatus.MetricVec,
+		pm.healthChecksTotal.MetricVec,
+		pm.datagramsTotal.MetricVec,
+		pm.datagramBytesTotal.MetricVec,
+		pm.bytesSent.MetricVec,
+		pm.bytesReceived.MetricVec,
+		pm.dialLatency.MetricVec,
+		pm.timeToFirstByte.MetricVec,
+	}
+	return pm
+}
+
+// RemoveBackend deletes every series labelled with backend, across all
+// frontends, so removed backends stop being exported.
+func (pm *PrometheusMetrics) RemoveBackend(backend string) {
+	for _, vec := range pm.backendSeries {
+		vec.DeletePartialMatch(prometheus.Labels{"backend": backend})
+	}
+}
+
+// ForFrontend returns a collector sharing the same series but reporting
+// under the given frontend label.
+func (pm *PrometheusMetrics) ForFrontend(frontend string) port.MetricsCollector {
+	scoped := *pm
+	scoped.frontend = frontend
+	return &scoped
 }
 
 func (pm *PrometheusMetrics) IncConnectionsTotal(backend string) {
-	pm.connectionsTotal.WithLabelValues(backend).Inc()
+	pm.connectionsTotal.WithLabelValues(pm.frontend, backend).Inc()
 }
 
 func (pm *PrometheusMetrics) IncConnectionsActive(backend string) {
-	pm.connectionsActive.WithLabelValues(backend).Inc()
+	pm.connectionsActive.WithLabelValues(pm.frontend, backend).Inc()
 }
 
 func (pm *PrometheusMetrics) DecConnectionsActive(backend string) {
-	pm.connectionsActive.WithLabelValues(backend).Dec()
+	pm.connectionsActive.WithLabelValues(pm.frontend, backend).Dec()
 }
 
 func (pm *PrometheusMetrics) IncConnectionErrors(backend string, errorType string) {
-	pm.connectionErrors.WithLabelValues(backend, errorType).Inc()
+	pm.connectionErrors.WithLabelValues(pm.frontend, backend, errorType).Inc()
 }
 
 func (pm *PrometheusMetrics) ObserveConnectionDuration(backend string, duration float64) {
-	pm.connectionDuration.WithLabelValues(backend).Observe(duration)
+	pm.connectionDuration.WithLabelValues(pm.frontend, backend).Observe(duration)
+}
+
+func (pm *PrometheusMetrics) AddBytesSent(backend string, n int) {
+	pm.bytesSent.WithLabelValues(pm.frontend, backend).Add(float64(n))
+}
+
+func (pm *PrometheusMetrics) AddBytesReceived(backend string, n int) {
+	pm.bytesReceived.WithLabelValues(pm.frontend, backend).Add(float64(n))
+}
+
+func (pm *PrometheusMetrics) ObserveDialLatency(backend string, seconds float64) {
+	pm.dialLatency.WithLabelValues(pm.frontend, backend).Observe(seconds)
+}
+
+func (pm *PrometheusMetrics) ObserveTimeToFirstByte(backend string, seconds float64) {
+	pm.timeToFirstByte.WithLabelValues(pm.frontend, backend).Observe(seconds)
 }
 
 func (pm *PrometheusMetrics) SetBackendHealthStatus(backend string, healthy bool) {
@@ -88,9 +229,34 @@ func (pm *PrometheusMetrics) SetBackendHealthStatus(backend string, healthy bool
 	if healthy {
 		value = 1.0
 	}
-	pm.backendHealthStatus.WithLabelValues(backend).Set(value)
+	pm.backendHealthStatus.WithLabelValues(pm.frontend, backend).Set(value)
 }
 
 func (pm *PrometheusMetrics) IncHealthChecksTotal(backend string, status string) {
-	pm.healthChecksTotal.WithLabelValues(backend, status).Inc()
+	pm.healthChecksTotal.WithLabelValues(pm.frontend, backend, status).Inc()
+}
+
+func (pm *PrometheusMetrics) IncTLSHandshakeErrors(reason string) {
+	pm.tlsHandshakeErrors.WithLabelValues(pm.frontend, reason).Inc()
+}
+
+func (pm *PrometheusMetrics) ObserveDatagram(backend string, direction string, size int) {
+	pm.datagramsTotal.WithLabelValues(pm.frontend, backend, direction).Inc()
+	pm.datagramBytesTotal.WithLabelValues(pm.frontend, backend, direction).Add(float64(size))
+}
+
+func (pm *PrometheusMetrics) IncQueueDepth() {
+	pm.queueDepth.WithLabelValues(pm.frontend).Inc()
+}
+
+func (pm *PrometheusMetrics) DecQueueDepth() {
+	pm.queueDepth.WithLabelValues(pm.frontend).Dec()
+}
+
+func (pm *PrometheusMetrics) ObserveQueueWait(seconds float64) {
+	pm.queueWait.WithLabelValues(pm.frontend).Observe(seconds)
+}
+
+func (pm *PrometheusMetrics) IncRejectedConnections(reason string) {
+	pm.rejectedConnections.WithLabelValues(pm.frontend, reason).Inc()
 }
@@ -0,0 +1,112 @@
+package metrics
+
+import "github.com/reybrally/TCP-Load-Balancer/internal/domain/port"
+
+type tee []port.MetricsCollector
+
+// Tee returns a collector reporting to every one of collectors.
+func Tee(collectors ...port.MetricsCollector) port.MetricsCollector {
+	return tee(collectors)
+}
+
+func (t tee) IncConnectionsTotal(backend string) {
+	for _, c := range t {
+		c.IncConnectionsTotal(backend)
+	}
+}
+
+func (t tee) IncConnectionsActive(backend string) {
+	for _, c := range t {
+		c.IncConnectionsActive(backend)
+	}
+}
+
+func (t tee) DecConnectionsActive(backend string) {
+	for _, c := range t {
+		c.DecConnectionsActive(backend)
+	}
+}
+
+func (t tee) IncConnectionErrors(backend string, errorType string) {
+	for _, c := range t {
+		c.IncConnectionErrors(backend, errorType)
+	}
+}
+
+func (t tee) ObserveConnectionDuration(backend string, duration float64) {
+	for _, c := range t {
+		c.ObserveConnectionDuration(backend, duration)
+	}
+}
+
+func (t tee) AddBytesSent(backend string, n int) {
+	for _, c := range t {
+		c.AddBytesSent(backend, n)
+	}
+}
+
+func (t tee) AddBytesReceived(backend string, n int) {
+	for _, c := range t {
+		c.AddBytesReceived(backend, n)
+	}
+}
+
+func (t tee) ObserveDialLatency(backend string, seconds float64) {
+	for _, c := range t {
+		c.ObserveDialLatency(backend, seconds)
+	}
+}
+
+func (t tee) ObserveTimeToFirstByte(backend string, seconds float64) {
+	for _, c := range t {
+		c.ObserveTimeToFirstByte(backend, seconds)
+	}
+}
+
+func (t tee) SetBackendHealthStatus(backend string, healthy bool) {
+	for _, c := range t {
+		c.SetBackendHealthStatus(backend, healthy)
+	}
+}
+
+func (t tee) IncHealthChecksTotal(backend string, status string) {
+	for _, c := range t {
+		c.IncHealthChecksTotal(backend, status)
+	}
+}
+
+func (t tee) IncTLSHandshakeErrors(reason string) {
+	for _, c := range t {
+		c.IncTLSHandshakeErrors(reason)
+	}
+}
+
+func (t tee) ObserveDatagram(backend string, direction string, size int) {
+	for _, c := range t {
+		c.ObserveDatagram(backend, direction, size)
+	}
+}
+
+func (t tee) IncQueueDepth() {
+	for _, c := range t {
+		c.IncQueueDepth()
+	}
+}
+
+func (t tee) DecQueueDepth() {
+	for _, c := range t {
+		c.DecQueueDepth()
+	}
+}
+
+func (t tee) ObserveQueueWait(seconds float64) {
+	for _, c := range t {
+		c.ObserveQueueWait(seconds)
+	}
+}
+
+func (t tee) IncRejectedConnections(reason string) {
+	for _, c := range t {
+		c.IncRejectedConnections(reason)
+	}
+}
@@ -0,0 +1,210 @@
+package ratelimit
+
+import (
+	"math"
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultIPv4Prefix = 24
+	DefaultIPv6Prefix = 64
+	DefaultMaxTracked = 100000
+)
+
+// Rejection reasons reported by Allow.
+const (
+	ReasonIPRate        = "rate_limited_ip"
+	ReasonPrefixRate    = "rate_limited_prefix"
+	ReasonIPConcurrency = "too_many_connections"
+)
+
+// Config limits new connections per source. Zero rates and limits are off.
+type Config struct {
+	// Rate and Burst bound new connections per second from one IP.
+	Rate  float64
+	Burst int
+
+	// PrefixRate and PrefixBurst bound new connections per second from one
+	// network, grouped by IPv4Prefix and IPv6Prefix bits.
+	PrefixRate  float64
+	PrefixBurst int
+	IPv4Prefix  int
+	IPv6Prefix  int
+
+	// MaxConnectionsPerIP caps open connections from one IP.
+	MaxConnectionsPerIP int
+
+	// MaxTracked bounds the number of token buckets kept per kind; the least
+	// recently seen sources are forgotten first.
+	MaxTracked int
+}
+
+// Limiter applies Config to incoming connections. It is safe for
+// concurrent use.
+type Limiter struct {
+	cfg Config
+	now func() time.Time
+
+	mu       sync.Mutex
+	ips      *lru[netip.Addr, *bucket]
+	prefixes *lru[netip.Prefix, *bucket]
+	active   map[netip.Addr]int
+}
+
+type Option func(*Limiter)
+
+// WithClock replaces time.Now, for tests.
+func WithClock(now func() time.Time) Option {
+	return func(l *Limiter) {
+		l.now = now
+	}
+}
+
+func New(cfg Config, opts ...Option) *Limiter {
+	if cfg.Burst <= 0 {
+		cfg.Burst = int(math.Max(1, math.Ceil(cfg.Rate)))
+	}
+	if cfg.PrefixBurst <= 0 {
+		cfg.PrefixBurst = int(math.Max(1, math.Ceil(cfg.PrefixRate)))
+	}
+	if cfg.IPv4Prefix <= 0 {
+		cfg.IPv4Prefix = DefaultIPv4Prefix
+	}
+	if cfg.IPv6Prefix <= 0 {
+		cfg.IPv6Prefix = DefaultIPv6Prefix
+	}
+	if cfg.MaxTracked <= 0 {
+		cfg.MaxTracked = DefaultMaxTracked
+	}
+
+	l := &Limiter{
+		cfg:      cfg,
+		now:      time.Now,
+		ips:      newLRU[netip.Addr, *bucket](cfg.MaxTracked),
+		prefixes: newLRU[netip.Prefix, *bucket](cfg.MaxTracked),
+		active:   make(map[netip.Addr]int),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Allow decides whether a new connection from addr may be served. If so, the
+// returned release func must be called when the connection ends; otherwise
+// reason says which limit was hit. Addresses without an IP are not limited.
+func (l *Limiter) Allow(addr net.Addr) (release func(), reason string) {
+	ip, ok := sourceIP(addr)
+	if !ok {
+		return func() {}, ""
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.cfg.MaxConnectionsPerIP > 0 && l.active[ip] >= l.cfg.MaxConnectionsPerIP {
+		return nil, ReasonIPConcurrency
+	}
+
+	now := l.now()
+	var ipBucket, prefixBucket *bucket
+	if l.cfg.Rate > 0 {
+		ipBucket = l.ips.getOrAdd(ip, func() *bucket { return newBucket(l.cfg.Burst, now) })
+		if !ipBucket.available(now, l.cfg.Rate, l.cfg.Burst) {
+			return nil, ReasonIPRate
+		}
+	}
+	if l.cfg.PrefixRate > 0 {
+		prefixBucket = l.prefixes.getOrAdd(l.prefix(ip), func() *bucket { return newBucket(l.cfg.PrefixBurst, now) })
+		if !prefixBucket.available(now, l.cfg.PrefixRate, l.cfg.PrefixBurst) {
+			return nil, ReasonPrefixRate
+		}
+	}
+
+	// Take tokens only once every limit has passed, so a connection refused
+	// by one limit doesn't count against the others.
+	if ipBucket != nil {
+		ipBucket.tokens--
+	}
+	if prefixBucket != nil {
+		prefixBucket.tokens--
+	}
+
+	if l.cfg.MaxConnectionsPerIP <= 0 {
+		return func() {}, ""
+	}
+	l.active[ip]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { l.release(ip) })
+	}, ""
+}
+
+// Tracked returns the number of IPs and prefixes with a token bucket.
+func (l *Limiter) Tracked() (ips, prefixes int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.ips.len(), l.prefixes.len()
+}
+
+func (l *Limiter) release(ip netip.Addr) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.active[ip] <= 1 {
+		delete(l.active, ip)
+		return
+	}
+	l.active[ip]--
+}
+
+func (l *Limiter) prefix(ip netip.Addr) netip.Prefix {
+	bits := l.cfg.IPv6Prefix
+	if ip.Is4() {
+		bits = l.cfg.IPv4Prefix
+	}
+	prefix, err := ip.Prefix(bits)
+	if err != nil {
+		return netip.PrefixFrom(ip, ip.BitLen())
+	}
+	return prefix
+}
+
+func sourceIP(addr net.Addr) (netip.Addr, bool) {
+	var ip net.IP
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		ip = a.IP
+	case *net.UDPAddr:
+		ip = a.IP
+	default:
+		return netip.Addr{}, false
+	}
+	parsed, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return netip.Addr{}, false
+	}
+	return parsed.Unmap(), true
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(burst int, now time.Time) *bucket {
+	return &bucket{tokens: float64(burst), last: now}
+}
+
+// available refills the bucket and reports whether it holds a whole token.
+func (b *bucket) available(now time.Time, rate float64, burst int) bool {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(burst), b.tokens+elapsed*rate)
+		b.last = now
+	}
+	return b.tokens >= 1
+}
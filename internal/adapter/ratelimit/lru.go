@@ -0,0 +1,46 @@
+package ratelimit
+
+import "container/list"
+
+// lru is a map bounded to a fixed number of entries that evicts the least
+// recently used one. It is not safe for concurrent use.
+type lru[K comparable, V any] struct {
+	capacity int
+	entries  map[K]*list.Element
+	order    *list.List
+}
+
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+func newLRU[K comparable, V any](capacity int) *lru[K, V] {
+	return &lru[K, V]{
+		capacity: capacity,
+		entries:  make(map[K]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// getOrAdd returns the value for key, creating it with create if missing.
+func (c *lru[K, V]) getOrAdd(key K, create func() V) V {
+	if element, ok := c.entries[key]; ok {
+		c.order.MoveToFront(element)
+		return element.Value.(*lruEntry[K, V]).value
+	}
+
+	if c.order.Len() >= c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry[K, V]).key)
+	}
+
+	value := create()
+	c.entries[key] = c.order.PushFront(&lruEntry[K, V]{key: key, value: value})
+	return value
+}
+
+func (c *lru[K, V]) len() int {
+	return c.order.Len()
+}
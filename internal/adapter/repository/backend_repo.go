@@ -3,55 +3,65 @@ package repository
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sync"
+	"sync/atomic"
 
 	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
 )
 
+// snapshot is an immutable view of the repository. Backends keep the order
+// they were added in.
+type snapshot struct {
+	version uint64
+	all     []*model.Backend
+	healthy []*model.Backend
+}
+
+// BackendRepo publishes a new snapshot on every change, so reads never lock
+// or allocate. The slices returned by GetAll and GetHealthy are shared and
+// must not be modified. Backends are healthy and enabled when added;
+// SetHealthy and SetAdminState are the only ways to change that.
 type BackendRepo struct {
-	backends map[string]*model.Backend
-	mu       sync.RWMutex
+	current atomic.Pointer[snapshot]
+	mu      sync.Mutex
+
+	// down holds the IDs of unhealthy backends, admin the state of every
+	// backend that isn't enabled.
+	down     map[string]bool
+	admin    map[string]string
+	watchers map[*watcher]struct{}
 }
 
 func New() *BackendRepo {
-	return &BackendRepo{
-		backends: make(map[string]*model.Backend),
+	r := &BackendRepo{
+		down:     make(map[string]bool),
+		admin:    make(map[string]string),
+		watchers: make(map[*watcher]struct{}),
 	}
+	r.current.Store(&snapshot{})
+	return r
 }
 
 func (r *BackendRepo) GetAll(ctx context.Context) []*model.Backend {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	backends := make([]*model.Backend, 0, len(r.backends))
-	for _, backend := range r.backends {
-		backends = append(backends, backend)
-	}
-	return backends
+	return r.current.Load().all
 }
 
 func (r *BackendRepo) GetHealthy(ctx context.Context) []*model.Backend {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	backends := make([]*model.Backend, 0)
-	for _, backend := range r.backends {
-		if backend.GetHealthy() {
-			backends = append(backends, backend)
-		}
-	}
-	return backends
+	return r.current.Load().healthy
 }
 
 func (r *BackendRepo) Add(ctx context.Context, backend *model.Backend) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, exists := r.backends[backend.ID]; exists {
+	all := r.current.Load().all
+	if index(all, backend.ID) >= 0 {
 		return fmt.Errorf("backend with ID %s already exists", backend.ID)
 	}
 
-	r.backends[backend.ID] = backend
+	r.publish(append(slices.Clip(all), backend))
+	r.notify(model.BackendAdded, backend, true)
 	return nil
 }
 
@@ -59,11 +69,17 @@ func (r *BackendRepo) Remove(ctx context.Context, id string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, exists := r.backends[id]; !exists {
-		return fmt.Errorf("backend with ID %s not found", id)
+	all := r.current.Load().all
+	i := index(all, id)
+	if i < 0 {
+		return notFound(id)
 	}
-
-	delete(r.backends, id)
+	backend := all[i]
+	healthy := !r.down[id]
+	delete(r.down, id)
+	r.publish(slices.Delete(slices.Clone(all), i, i+1))
+	r.notify(model.BackendRemoved, backend, healthy)
+	delete(r.admin, id)
 	return nil
 }
 
@@ -71,10 +87,113 @@ func (r *BackendRepo) Update(ctx context.Context, backend *model.Backend) error
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, exists := r.backends[backend.ID]; !exists {
-		return fmt.Errorf("backend with ID %s not found", backend.ID)
+	all := r.current.Load().all
+	i := index(all, backend.ID)
+	if i < 0 {
+		return notFound(backend.ID)
+	}
+
+	updated := slices.Clone(all)
+	updated[i] = backend
+	r.publish(updated)
+	r.notify(model.BackendUpdated, backend, !r.down[backend.ID])
+	return nil
+}
+
+func (r *BackendRepo) SetHealthy(ctx context.Context, id string, healthy bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	all := r.current.Load().all
+	i := index(all, id)
+	if i < 0 {
+		return notFound(id)
 	}
 
-	r.backends[backend.ID] = backend
+	if r.down[id] == healthy {
+		if healthy {
+			delete(r.down, id)
+		} else {
+			r.down[id] = true
+		}
+		r.publish(all)
+		r.notify(model.BackendHealthChanged, all[i], healthy)
+	}
 	return nil
 }
+
+func (r *BackendRepo) SetAdminState(ctx context.Context, id string, state string) error {
+	if err := checkAdminState(state); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	all := r.current.Load().all
+	i := index(all, id)
+	if i < 0 {
+		return notFound(id)
+	}
+
+	if r.adminState(id) != state {
+		if state == model.AdminEnabled {
+			delete(r.admin, id)
+		} else {
+			r.admin[id] = state
+		}
+		r.publish(all)
+		r.notify(model.BackendAdminChanged, all[i], !r.down[id])
+	}
+	return nil
+}
+
+func (r *BackendRepo) AdminState(ctx context.Context, id string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.adminState(id)
+}
+
+// adminState must be called with r.mu held.
+func (r *BackendRepo) adminState(id string) string {
+	if state, ok := r.admin[id]; ok {
+		return state
+	}
+	return model.AdminEnabled
+}
+
+func checkAdminState(state string) error {
+	if !model.ValidAdminState(state) {
+		return fmt.Errorf("unknown admin state %q", state)
+	}
+	return nil
+}
+
+func notFound(id string) error {
+	return fmt.Errorf("%w: %s", model.ErrBackendNotFound, id)
+}
+
+// Version returns the version of the latest change.
+func (r *BackendRepo) Version() uint64 {
+	return r.current.Load().version
+}
+
+// publish must be called with r.mu held. all must not be shared with a
+// previous snapshot unless it is unchanged.
+func (r *BackendRepo) publish(all []*model.Backend) {
+	healthy := make([]*model.Backend, 0, len(all))
+	for _, backend := range all {
+		if !r.down[backend.ID] && r.adminState(backend.ID) == model.AdminEnabled {
+			healthy = append(healthy, backend)
+		}
+	}
+	r.current.Store(&snapshot{
+		version: r.current.Load().version + 1,
+		all:     all,
+		healthy: healthy,
+	})
+}
+
+func index(backends []*model.Backend, id string) int {
+	return slices.IndexFunc(backends, func(b *model.Backend) bool { return b.ID == id })
+}
@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+)
+
+type backendState struct {
+	ID             string `json:"id"`
+	Network        string `json:"network,omitempty"`
+	Address        string `json:"address"`
+	Port           int    `json:"port,omitempty"`
+	Weight         int    `json:"weight"`
+	MaxConnections int    `json:"max_connections,omitempty"`
+	AdminState     string `json:"admin_state,omitempty"`
+}
+
+type fileState struct {
+	Backends []backendState `json:"backends"`
+}
+
+// FileRepo is a BackendRepo that saves its backends and their admin states
+// to a JSON state file, so they survive restarts. Every change is saved
+// before it is applied; if saving fails, the change is not made. Health is
+// not saved; backends start healthy and the health checks take over.
+type FileRepo struct {
+	*BackendRepo
+
+	path   string
+	loaded bool
+
+	// mu serializes changes, so that the state saved for a change is the
+	// one it is applied to.
+	mu sync.Mutex
+}
+
+// OpenFile loads the backends saved at path. A missing file gives an empty
+// repository that creates the file on its first change.
+func OpenFile(path string) (*FileRepo, error) {
+	r := &FileRepo{BackendRepo: New(), path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state fileState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	ctx := context.Background()
+	for _, b := range state.Backends {
+		backend := model.NewBackend(b.ID, b.Address, b.Port, b.Weight)
+		backend.Network = b.Network
+		backend.MaxConnections = b.MaxConnections
+		if err := r.BackendRepo.Add(ctx, backend); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if b.AdminState != "" {
+			if err := r.BackendRepo.SetAdminState(ctx, b.ID, b.AdminState); err != nil {
+				return nil, fmt.Errorf("%s: backend %s: %w", path, b.ID, err)
+			}
+		}
+	}
+	r.loaded = true
+	return r, nil
+}
+
+// Loaded reports whether the backends came from an existing state file.
+func (r *FileRepo) Loaded() bool {
+	return r.loaded
+}
+
+func (r *FileRepo) Path() string {
+	return r.path
+}
+
+func (r *FileRepo) Add(ctx context.Context, backend *model.Backend) error {
+	return r.change(func(states []backendState) ([]backendState, error) {
+		if stateIndex(states, backend.ID) >= 0 {
+			return nil, fmt.Errorf("backend with ID %s already exists", backend.ID)
+		}
+		return append(states, stateOf(backend, model.AdminEnabled)), nil
+	}, func() error {
+		return r.BackendRepo.Add(ctx, backend)
+	})
+}
+
+func (r *FileRepo) Remove(ctx context.Context, id string) error {
+	return r.change(func(states []backendState) ([]backendState, error) {
+		i := stateIndex(states, id)
+		if i < 0 {
+			return nil, notFound(id)
+		}
+		return slices.Delete(states, i, i+1), nil
+	}, func() error {
+		return r.BackendRepo.Remove(ctx, id)
+	})
+}
+
+func (r *FileRepo) Update(ctx context.Context, backend *model.Backend) error {
+	return r.change(func(states []backendState) ([]backendState, error) {
+		i := stateIndex(states, backend.ID)
+		if i < 0 {
+			return nil, notFound(backend.ID)
+		}
+		states[i] = stateOf(backend, states[i].AdminState)
+		return states, nil
+	}, func() error {
+		return r.BackendRepo.Update(ctx, backend)
+	})
+}
+
+func (r *FileRepo) SetAdminState(ctx context.Context, id string, state string) error {
+	return r.change(func(states []backendState) ([]backendState, error) {
+		if err := checkAdminState(state); err != nil {
+			return nil, err
+		}
+		i := stateIndex(states, id)
+		if i < 0 {
+			return nil, notFound(id)
+		}
+		states[i].AdminState = state
+		return states, nil
+	}, func() error {
+		return r.BackendRepo.SetAdminState(ctx, id, state)
+	})
+}
+
+// change saves the state edit makes of the current one, then applies the
+// change to the repository. edit rejects changes the repository would, such
+// as duplicate or unknown IDs, before anything is saved. Should apply fail
+// anyway, the previous state is saved back, so an error leaves both the
+// state file and the repository as they were.
+func (r *FileRepo) change(edit func([]backendState) ([]backendState, error), apply func() error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prev := r.states()
+	states, err := edit(slices.Clone(prev))
+	if err != nil {
+		return err
+	}
+	if err := r.save(states); err != nil {
+		return err
+	}
+	if err := apply(); err != nil {
+		if restoreErr := r.save(prev); restoreErr != nil {
+			return errors.Join(err, restoreErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *FileRepo) states() []backendState {
+	ctx := context.Background()
+	backends := r.GetAll(ctx)
+	states := make([]backendState, 0, len(backends))
+	for _, b := range backends {
+		states = append(states, stateOf(b, r.BackendRepo.AdminState(ctx, b.ID)))
+	}
+	return states
+}
+
+func stateOf(b *model.Backend, admin string) backendState {
+	if admin == model.AdminEnabled {
+		admin = ""
+	}
+	return backendState{
+		ID:             b.ID,
+		Network:        b.Network,
+		Address:        b.Address,
+		Port:           b.Port,
+		Weight:         b.Weight,
+		MaxConnections: b.MaxConnections,
+		AdminState:     admin,
+	}
+}
+
+func stateIndex(states []backendState, id string) int {
+	return slices.IndexFunc(states, func(s backendState) bool { return s.ID == id })
+}
+
+// save writes states to a temporary file and renames it over the state
+// file, so a crash leaves either the old or the new state.
+func (r *FileRepo) save(states []backendState) error {
+	data, err := json.MarshalIndent(fileState{Backends: states}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := writeAtomic(r.path, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to save backend state: %w", err)
+	}
+	return nil
+}
+
+func writeAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+)
+
+// watcher queues changes for one Watch channel so that a slow consumer
+// never blocks writers to the repository.
+type watcher struct {
+	mu     sync.Mutex
+	queue  []model.BackendChange
+	signal chan struct{}
+}
+
+func (w *watcher) push(change model.BackendChange) {
+	w.mu.Lock()
+	w.queue = append(w.queue, change)
+	w.mu.Unlock()
+
+	select {
+	case w.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (w *watcher) run(ctx context.Context, out chan<- model.BackendChange) {
+	defer close(out)
+
+	for {
+		w.mu.Lock()
+		pending := w.queue
+		w.queue = nil
+		w.mu.Unlock()
+
+		for _, change := range pending {
+			select {
+			case out <- change:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		select {
+		case <-w.signal:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *BackendRepo) Watch(ctx context.Context) <-chan model.BackendChange {
+	w := &watcher{signal: make(chan struct{}, 1)}
+
+	r.mu.Lock()
+	current := r.current.Load()
+	for _, backend := range current.all {
+		w.queue = append(w.queue, model.BackendChange{
+			Type:       model.BackendAdded,
+			Version:    current.version,
+			Backend:    backend,
+			Healthy:    !r.down[backend.ID],
+			AdminState: r.adminState(backend.ID),
+		})
+	}
+	r.watchers[w] = struct{}{}
+	r.mu.Unlock()
+
+	out := make(chan model.BackendChange)
+	go func() {
+		w.run(ctx, out)
+
+		r.mu.Lock()
+		delete(r.watchers, w)
+		r.mu.Unlock()
+	}()
+	return out
+}
+
+// notify must be called with r.mu held right after publish, so that
+// versions reach every watcher in order.
+func (r *BackendRepo) notify(kind string, backend *model.Backend, healthy bool) {
+	change := model.BackendChange{
+		Type:       kind,
+		Version:    r.current.Load().version,
+		Backend:    backend,
+		Healthy:    healthy,
+		AdminState: r.adminState(backend.ID),
+	}
+	for w := range r.watchers {
+		w.push(change)
+	}
+}
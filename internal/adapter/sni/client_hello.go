@@ -0,0 +1,191 @@
+package sni
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	recordTypeHandshake     = 22
+	handshakeTypeClientHelo = 1
+	recordHeaderLen         = 5
+	handshakeHeaderLen      = 4
+	maxClientHelloLen       = 64 * 1024
+
+	extensionServerName = 0
+	extensionALPN       = 16
+)
+
+var ErrNotTLS = errors.New("not a TLS handshake")
+
+type ClientHello struct {
+	ServerName string
+	ALPN       []string
+}
+
+// Peek reads the ClientHello from conn without consuming it: the returned
+// connection replays every byte read so far before continuing with conn.
+func Peek(conn net.Conn, timeout time.Duration) (*ClientHello, *PeekedConn, error) {
+	if timeout > 0 {
+		conn.SetReadDeadline(time.Now().Add(timeout))
+		defer conn.SetReadDeadline(time.Time{})
+	}
+
+	var raw bytes.Buffer
+	hello, err := readClientHello(io.TeeReader(conn, &raw))
+	peeked := &PeekedConn{
+		Conn:   conn,
+		reader: io.MultiReader(bytes.NewReader(raw.Bytes()), conn),
+	}
+	if err != nil {
+		return nil, peeked, err
+	}
+
+	peeked.hello = hello
+	return hello, peeked, nil
+}
+
+func readClientHello(r io.Reader) (*ClientHello, error) {
+	var handshake []byte
+	header := make([]byte, recordHeaderLen)
+
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, err
+		}
+		if header[0] != recordTypeHandshake {
+			return nil, ErrNotTLS
+		}
+		if header[1] != 3 {
+			return nil, fmt.Errorf("%w: unexpected record version %d.%d", ErrNotTLS, header[1], header[2])
+		}
+
+		length := int(binary.BigEndian.Uint16(header[3:5]))
+		if len(handshake)+length > maxClientHelloLen {
+			return nil, errors.New("client hello too large")
+		}
+
+		fragment := make([]byte, length)
+		if _, err := io.ReadFull(r, fragment); err != nil {
+			return nil, err
+		}
+		handshake = append(handshake, fragment...)
+
+		if len(handshake) < handshakeHeaderLen {
+			continue
+		}
+		if handshake[0] != handshakeTypeClientHelo {
+			return nil, fmt.Errorf("%w: expected client hello, got handshake type %d", ErrNotTLS, handshake[0])
+		}
+
+		msgLen := int(handshake[1])<<16 | int(handshake[2])<<8 | int(handshake[3])
+		if msgLen > maxClientHelloLen {
+			return nil, errors.New("client hello too large")
+		}
+		if len(handshake) >= handshakeHeaderLen+msgLen {
+			return parseClientHello(handshake[handshakeHeaderLen : handshakeHeaderLen+msgLen])
+		}
+	}
+}
+
+func parseClientHello(msg []byte) (*ClientHello, error) {
+	p := parser{data: msg}
+
+	p.skip(2 + 32) // legacy_version, random
+	p.skip(int(p.uint8()))
+	p.skip(int(p.uint16()))
+	p.skip(int(p.uint8()))
+
+	hello := &ClientHello{}
+	if p.empty() {
+		return hello, p.err
+	}
+
+	extensions := parser{data: p.bytes(int(p.uint16()))}
+	for p.err == nil && extensions.err == nil && !extensions.empty() {
+		extType := extensions.uint16()
+		ext := parser{data: extensions.bytes(int(extensions.uint16()))}
+
+		switch extType {
+		case extensionServerName:
+			names := parser{data: ext.bytes(int(ext.uint16()))}
+			for !names.empty() && names.err == nil {
+				nameType := names.uint8()
+				name := names.bytes(int(names.uint16()))
+				if nameType == 0 && names.err == nil {
+					hello.ServerName = strings.TrimSuffix(strings.ToLower(string(name)), ".")
+					break
+				}
+			}
+			if names.err != nil {
+				return nil, names.err
+			}
+		case extensionALPN:
+			protos := parser{data: ext.bytes(int(ext.uint16()))}
+			for !protos.empty() && protos.err == nil {
+				proto := protos.bytes(int(protos.uint8()))
+				if protos.err == nil {
+					hello.ALPN = append(hello.ALPN, string(proto))
+				}
+			}
+			if protos.err != nil {
+				return nil, protos.err
+			}
+		}
+		if ext.err != nil {
+			return nil, ext.err
+		}
+	}
+
+	if p.err != nil {
+		return nil, p.err
+	}
+	return hello, extensions.err
+}
+
+type parser struct {
+	data []byte
+	err  error
+}
+
+var errTruncated = errors.New("truncated client hello")
+
+func (p *parser) empty() bool {
+	return len(p.data) == 0
+}
+
+func (p *parser) bytes(n int) []byte {
+	if p.err != nil || n > len(p.data) {
+		p.err = errTruncated
+		return nil
+	}
+	b := p.data[:n]
+	p.data = p.data[n:]
+	return b
+}
+
+func (p *parser) skip(n int) {
+	p.bytes(n)
+}
+
+func (p *parser) uint8() uint8 {
+	b := p.bytes(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (p *parser) uint16() uint16 {
+	b := p.bytes(2)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint16(b)
+}
@@ -0,0 +1,30 @@
+package sni
+
+import (
+	"io"
+	"net"
+)
+
+// PeekedConn is a net.Conn whose reads first return the bytes consumed while
+// peeking at the ClientHello.
+type PeekedConn struct {
+	net.Conn
+	reader io.Reader
+	hello  *ClientHello
+}
+
+func (c *PeekedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+// ClientHello returns the parsed hello, or nil if it could not be parsed.
+func (c *PeekedConn) ClientHello() *ClientHello {
+	return c.hello
+}
+
+func (c *PeekedConn) ServerName() string {
+	if c.hello == nil {
+		return ""
+	}
+	return c.hello.ServerName
+}
@@ -0,0 +1,134 @@
+package sni
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/port"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+)
+
+const DefaultPeekTimeout = 5 * time.Second
+
+type Route struct {
+	Hosts   []string
+	ALPN    []string
+	Handler port.ConnectionHandler
+}
+
+// Router dispatches connections to a handler based on the TLS server name
+// without terminating TLS. Hosts are matched exactly first, then against
+// "*.domain" wildcards covering a single label. Connections that match no
+// route, or carry no SNI, go to the default handler.
+type Router struct {
+	routes         []Route
+	defaultHandler port.ConnectionHandler
+	peekTimeout    time.Duration
+	metrics        port.MetricsCollector
+	logger         *logger.Logger
+}
+
+func NewRouter(routes []Route, defaultHandler port.ConnectionHandler, peekTimeout time.Duration, metrics port.MetricsCollector, logger *logger.Logger) *Router {
+	if peekTimeout <= 0 {
+		peekTimeout = DefaultPeekTimeout
+	}
+	return &Router{
+		routes:         routes,
+		defaultHandler: defaultHandler,
+		peekTimeout:    peekTimeout,
+		metrics:        metrics,
+		logger:         logger,
+	}
+}
+
+func (r *Router) Handle(ctx context.Context, conn net.Conn) error {
+	var hello *ClientHello
+
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		// TLS was already terminated by the listener; route on what was negotiated.
+		state := tlsConn.ConnectionState()
+		hello = &ClientHello{ServerName: strings.ToLower(state.ServerName)}
+		if state.NegotiatedProtocol != "" {
+			hello.ALPN = []string{state.NegotiatedProtocol}
+		}
+	} else {
+		var (
+			peeked *PeekedConn
+			err    error
+		)
+		hello, peeked, err = Peek(conn, r.peekTimeout)
+		if err != nil {
+			r.logger.Debugf("Failed to read ClientHello from %s: %v", conn.RemoteAddr(), err)
+			r.metrics.IncConnectionErrors("all", "client_hello_invalid")
+			conn.Close()
+			return err
+		}
+		conn = peeked
+	}
+
+	handler := r.Match(hello)
+	if handler == nil {
+		r.logger.Debugf("No route for server name %q from %s", hello.ServerName, conn.RemoteAddr())
+		r.metrics.IncConnectionErrors("all", "no_route")
+		conn.Close()
+		return nil
+	}
+
+	r.logger.Debugf("Routing %s with server name %q", conn.RemoteAddr(), hello.ServerName)
+	return handler.Handle(ctx, conn)
+}
+
+func (r *Router) Match(hello *ClientHello) port.ConnectionHandler {
+	if hello.ServerName != "" {
+		for _, route := range r.routes {
+			if matchesALPN(route.ALPN, hello.ALPN) && matchesHost(route.Hosts, hello.ServerName, false) {
+				return route.Handler
+			}
+		}
+		for _, route := range r.routes {
+			if matchesALPN(route.ALPN, hello.ALPN) && matchesHost(route.Hosts, hello.ServerName, true) {
+				return route.Handler
+			}
+		}
+	}
+	return r.defaultHandler
+}
+
+func matchesHost(hosts []string, serverName string, wildcard bool) bool {
+	for _, host := range hosts {
+		host = strings.TrimSuffix(strings.ToLower(host), ".")
+		if !wildcard {
+			if host == serverName {
+				return true
+			}
+			continue
+		}
+
+		suffix, ok := strings.CutPrefix(host, "*")
+		if !ok {
+			continue
+		}
+		label, found := strings.CutSuffix(serverName, suffix)
+		if found && label != "" && !strings.Contains(label, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesALPN(allowed, offered []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		for _, o := range offered {
+			if a == o {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,238 @@
+package statsd
+
+import (
+	"math/rand/v2"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultFlushInterval = 10 * time.Second
+
+	// DefaultMaxPacketSize keeps packets within an Ethernet MTU.
+	DefaultMaxPacketSize = 1432
+
+	// MaxTimingSamples is how many timings of one metric are kept per
+	// flush.
+	MaxTimingSamples = 100
+)
+
+type Config struct {
+	// Address is the host:port of the StatsD agent.
+	Address string
+	// Prefix is prepended to every metric name, joined with a dot.
+	Prefix        string
+	FlushInterval time.Duration
+	// Tags are added to every metric, e.g. "env:prod".
+	Tags          []string
+	MaxPacketSize int
+}
+
+type metricKey struct {
+	name    string
+	tags    string
+	backend string
+}
+
+// reservoir is a uniform sample of at most MaxTimingSamples of the count
+// timings observed.
+type reservoir struct {
+	count   int64
+	samples []float64
+}
+
+func (r *reservoir) add(value float64) {
+	r.count++
+	if len(r.samples) < MaxTimingSamples {
+		r.samples = append(r.samples, value)
+		return
+	}
+	if i := rand.Int64N(r.count); i < MaxTimingSamples {
+		r.samples[i] = value
+	}
+}
+
+// Client aggregates metrics in memory and sends them to a StatsD agent as
+// DogStatsD lines once per flush interval. Counters are summed, gauges keep
+// their last value and are resent every flush. Timings are sampled, so at
+// most MaxTimingSamples per metric are sent, with a sample rate telling
+// the agent how many there were.
+type Client struct {
+	conn      net.Conn
+	prefix    string
+	tags      string
+	maxPacket int
+
+	mu       sync.Mutex
+	counters map[metricKey]int64
+	gauges   map[metricKey]float64
+	timings  map[metricKey]*reservoir
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func New(cfg Config) (*Client, error) {
+	conn, err := net.Dial("udp", cfg.Address)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = DefaultFlushInterval
+	}
+	if cfg.MaxPacketSize <= 0 {
+		cfg.MaxPacketSize = DefaultMaxPacketSize
+	}
+
+	c := &Client{
+		conn:      conn,
+		tags:      strings.Join(cfg.Tags, ","),
+		maxPacket: cfg.MaxPacketSize,
+		counters:  make(map[metricKey]int64),
+		gauges:    make(map[metricKey]float64),
+		timings:   make(map[metricKey]*reservoir),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	if cfg.Prefix != "" {
+		c.prefix = strings.TrimSuffix(cfg.Prefix, ".") + "."
+	}
+	go c.run(cfg.FlushInterval)
+	return c, nil
+}
+
+func (c *Client) run(interval time.Duration) {
+	defer close(c.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.Flush()
+		case <-c.stop:
+			c.Flush()
+			return
+		}
+	}
+}
+
+// Close sends what is left and closes the socket.
+func (c *Client) Close() error {
+	close(c.stop)
+	<-c.done
+	return c.conn.Close()
+}
+
+func (c *Client) count(key metricKey, n int64) {
+	c.mu.Lock()
+	c.counters[key] += n
+	c.mu.Unlock()
+}
+
+func (c *Client) gauge(key metricKey, value float64) {
+	c.mu.Lock()
+	c.gauges[key] = value
+	c.mu.Unlock()
+}
+
+func (c *Client) addGauge(key metricKey, delta float64) {
+	c.mu.Lock()
+	c.gauges[key] += delta
+	c.mu.Unlock()
+}
+
+func (c *Client) timing(key metricKey, seconds float64) {
+	c.mu.Lock()
+	r, ok := c.timings[key]
+	if !ok {
+		r = &reservoir{}
+		c.timings[key] = r
+	}
+	r.add(seconds * 1000)
+	c.mu.Unlock()
+}
+
+// removeBackend forgets every gauge reported for backend.
+func (c *Client) removeBackend(backend string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.gauges {
+		if key.backend == backend {
+			delete(c.gauges, key)
+		}
+	}
+}
+
+// Flush sends everything aggregated since the last flush. Send errors are
+// ignored, as StatsD is lossy anyway.
+func (c *Client) Flush() {
+	c.mu.Lock()
+	lines := make([]string, 0, len(c.counters)+len(c.gauges)+len(c.timings))
+	for key, n := range c.counters {
+		lines = append(lines, c.line(key, strconv.FormatInt(n, 10), "c"))
+	}
+	for key, value := range c.gauges {
+		lines = append(lines, c.line(key, formatFloat(value), "g"))
+	}
+	for key, r := range c.timings {
+		kind := "ms"
+		if n := int64(len(r.samples)); n < r.count {
+			kind += "|@" + strconv.FormatFloat(float64(n)/float64(r.count), 'g', 4, 64)
+		}
+		for _, value := range r.samples {
+			lines = append(lines, c.line(key, formatFloat(value), kind))
+		}
+	}
+	c.counters = make(map[metricKey]int64)
+	c.timings = make(map[metricKey]*reservoir)
+	c.mu.Unlock()
+
+	sort.Strings(lines)
+
+	packet := make([]byte, 0, c.maxPacket)
+	for _, line := range lines {
+		if len(packet) > 0 && len(packet)+1+len(line) > c.maxPacket {
+			c.conn.Write(packet)
+			packet = packet[:0]
+		}
+		if len(packet) > 0 {
+			packet = append(packet, '\n')
+		}
+		packet = append(packet, line...)
+	}
+	if len(packet) > 0 {
+		c.conn.Write(packet)
+	}
+}
+
+func (c *Client) line(key metricKey, value, kind string) string {
+	var b strings.Builder
+	b.WriteString(c.prefix)
+	b.WriteString(key.name)
+	b.WriteByte(':')
+	b.WriteString(value)
+	b.WriteByte('|')
+	b.WriteString(kind)
+
+	tags := key.tags
+	if c.tags != "" {
+		if tags != "" {
+			tags += ","
+		}
+		tags += c.tags
+	}
+	if tags != "" {
+		b.WriteString("|#")
+		b.WriteString(tags)
+	}
+	return b.String()
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,169 @@
+package statsd
+
+import (
+	"sync"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/port"
+)
+
+// Metrics is a port.MetricsCollector sending to a StatsD agent, with the
+// Prometheus labels as DogStatsD tags.
+type Metrics struct {
+	client   *Client
+	frontend string
+	tags     *tagCache
+}
+
+// tagKey identifies the tags of a metric: its frontend, backend and at
+// most one other tag.
+type tagKey struct {
+	frontend string
+	backend  string
+	name     string
+	value    string
+}
+
+// tagCache holds tag strings once built, as some metrics are reported for
+// every relayed chunk. It is shared by every frontend's Metrics.
+type tagCache struct {
+	mu   sync.RWMutex
+	tags map[tagKey]string
+}
+
+func NewMetrics(client *Client) *Metrics {
+	return &Metrics{
+		client:   client,
+		frontend: "default",
+		tags:     &tagCache{tags: make(map[tagKey]string)},
+	}
+}
+
+// ForFrontend returns a collector sharing the same client but tagging
+// metrics with the given frontend.
+func (m *Metrics) ForFrontend(frontend string) port.MetricsCollector {
+	scoped := *m
+	scoped.frontend = frontend
+	return &scoped
+}
+
+// RemoveBackend stops resending the gauges of backend.
+func (m *Metrics) RemoveBackend(backend string) {
+	m.client.removeBackend(backend)
+
+	m.tags.mu.Lock()
+	defer m.tags.mu.Unlock()
+	for key := range m.tags.tags {
+		if key.backend == backend {
+			delete(m.tags.tags, key)
+		}
+	}
+}
+
+// key returns the key of a metric, tagged with the frontend and, if given,
+// one extra tag name and value.
+func (m *Metrics) key(name string, extra ...string) metricKey {
+	return metricKey{name: name, tags: m.tagsFor("", extra)}
+}
+
+func (m *Metrics) backendKey(name, backend string, extra ...string) metricKey {
+	return metricKey{name: name, tags: m.tagsFor(backend, extra), backend: backend}
+}
+
+func (m *Metrics) tagsFor(backend string, extra []string) string {
+	key := tagKey{frontend: m.frontend, backend: backend}
+	if len(extra) == 2 {
+		key.name, key.value = extra[0], extra[1]
+	}
+
+	m.tags.mu.RLock()
+	tags, ok := m.tags.tags[key]
+	m.tags.mu.RUnlock()
+	if ok {
+		return tags
+	}
+
+	tags = "frontend:" + m.frontend
+	if backend != "" {
+		tags += ",backend:" + backend
+	}
+	if key.name != "" {
+		tags += "," + key.name + ":" + key.value
+	}
+	m.tags.mu.Lock()
+	m.tags.tags[key] = tags
+	m.tags.mu.Unlock()
+	return tags
+}
+
+func (m *Metrics) IncConnectionsTotal(backend string) {
+	m.client.count(m.backendKey("connections", backend), 1)
+}
+
+func (m *Metrics) IncConnectionsActive(backend string) {
+	m.client.addGauge(m.backendKey("connections.active", backend), 1)
+}
+
+func (m *Metrics) DecConnectionsActive(backend string) {
+	m.client.addGauge(m.backendKey("connections.active", backend), -1)
+}
+
+func (m *Metrics) IncConnectionErrors(backend string, errorType string) {
+	m.client.count(m.backendKey("connection.errors", backend, "error_type", errorType), 1)
+}
+
+func (m *Metrics) ObserveConnectionDuration(backend string, duration float64) {
+	m.client.timing(m.backendKey("connection.duration", backend), duration)
+}
+
+func (m *Metrics) AddBytesSent(backend string, n int) {
+	m.client.count(m.backendKey("bytes.sent", backend), int64(n))
+}
+
+func (m *Metrics) AddBytesReceived(backend string, n int) {
+	m.client.count(m.backendKey("bytes.received", backend), int64(n))
+}
+
+func (m *Metrics) ObserveDialLatency(backend string, seconds float64) {
+	m.client.timing(m.backendKey("backend.dial.duration", backend), seconds)
+}
+
+func (m *Metrics) ObserveTimeToFirstByte(backend string, seconds float64) {
+	m.client.timing(m.backendKey("backend.time_to_first_byte", backend), seconds)
+}
+
+func (m *Metrics) SetBackendHealthStatus(backend string, healthy bool) {
+	value := 0.0
+	if healthy {
+		value = 1
+	}
+	m.client.gauge(m.backendKey("backend.healthy", backend), value)
+}
+
+func (m *Metrics) IncHealthChecksTotal(backend string, status string) {
+	m.client.count(m.backendKey("health_checks", backend, "status", status), 1)
+}
+
+func (m *Metrics) IncTLSHandshakeErrors(reason string) {
+	m.client.count(m.key("tls.handshake_errors", "reason", reason), 1)
+}
+
+func (m *Metrics) ObserveDatagram(backend string, direction string, size int) {
+	m.client.count(m.backendKey("udp.packets", backend, "direction", direction), 1)
+	m.client.count(m.backendKey("udp.bytes", backend, "direction", direction), int64(size))
+}
+
+func (m *Metrics) IncQueueDepth() {
+	m.client.addGauge(m.key("queue.depth"), 1)
+}
+
+func (m *Metrics) DecQueueDepth() {
+	m.client.addGauge(m.key("queue.depth"), -1)
+}
+
+func (m *Metrics) ObserveQueueWait(seconds float64) {
+	m.client.timing(m.key("queue.wait"), seconds)
+}
+
+func (m *Metrics) IncRejectedConnections(reason string) {
+	m.client.count(m.key("connections.rejected", "reason", reason), 1)
+}
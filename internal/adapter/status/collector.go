@@ -0,0 +1,120 @@
+package status
+
+import (
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+)
+
+// noBackend is what the use cases report as the backend of failures that
+// happen before one is chosen.
+const noBackend = "all"
+
+type collector struct {
+	tracker  *Tracker
+	frontend *frontendStats
+	name     string
+}
+
+func (c *collector) backend(backend string) *backendStats {
+	return c.tracker.backend(c.name, backend)
+}
+
+func (c *collector) IncConnectionsTotal(backend string) {
+	c.frontend.total.Add(1)
+	c.backend(backend).total.Add(1)
+}
+
+func (c *collector) IncConnectionsActive(backend string) {
+	c.frontend.active.Add(1)
+	c.backend(backend).active.Add(1)
+}
+
+func (c *collector) DecConnectionsActive(backend string) {
+	c.frontend.active.Add(-1)
+	c.backend(backend).active.Add(-1)
+}
+
+func (c *collector) IncConnectionErrors(backend string, errorType string) {
+	if backend == noBackend {
+		c.frontend.mu.Lock()
+		c.frontend.errors[errorType]++
+		c.frontend.mu.Unlock()
+		return
+	}
+	stats := c.backend(backend)
+	stats.mu.Lock()
+	stats.errors[errorType]++
+	stats.mu.Unlock()
+}
+
+func (c *collector) ObserveConnectionDuration(backend string, duration float64) {}
+
+func (c *collector) AddBytesSent(backend string, n int) {
+	c.backend(backend).sent.Add(int64(n))
+}
+
+func (c *collector) AddBytesReceived(backend string, n int) {
+	c.backend(backend).received.Add(int64(n))
+}
+
+func (c *collector) ObserveDialLatency(backend string, seconds float64) {}
+
+func (c *collector) ObserveTimeToFirstByte(backend string, seconds float64) {}
+
+// SetBackendHealthStatus records when the health flips. Backends start out
+// healthy.
+func (c *collector) SetBackendHealthStatus(backend string, healthy bool) {
+	stats := c.backend(backend)
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	if stats.unhealthy == !healthy {
+		return
+	}
+	stats.unhealthy = !healthy
+	stats.lastChange = time.Now()
+}
+
+func (c *collector) IncHealthChecksTotal(backend string, status string) {
+	stats := c.backend(backend)
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	if len(stats.history) == HistorySize {
+		copy(stats.history, stats.history[1:])
+		stats.history = stats.history[:HistorySize-1]
+	}
+	stats.history = append(stats.history, model.HealthCheck{
+		Time:    time.Now(),
+		Healthy: status == "success",
+	})
+}
+
+func (c *collector) IncTLSHandshakeErrors(reason string) {
+	c.frontend.mu.Lock()
+	c.frontend.errors["tls_"+reason]++
+	c.frontend.mu.Unlock()
+}
+
+func (c *collector) ObserveDatagram(backend string, direction string, size int) {
+	if direction == "to_backend" {
+		c.backend(backend).sent.Add(int64(size))
+	} else {
+		c.backend(backend).received.Add(int64(size))
+	}
+}
+
+func (c *collector) IncQueueDepth() {
+	c.frontend.queue.Add(1)
+}
+
+func (c *collector) DecQueueDepth() {
+	c.frontend.queue.Add(-1)
+}
+
+func (c *collector) ObserveQueueWait(seconds float64) {}
+
+func (c *collector) IncRejectedConnections(reason string) {
+	c.frontend.mu.Lock()
+	c.frontend.rejected[reason]++
+	c.frontend.mu.Unlock()
+}
@@ -0,0 +1,230 @@
+package status
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/port"
+)
+
+// HistorySize is how many health checks are kept per backend.
+const HistorySize = 20
+
+// Frontend describes a frontend for the status page.
+type Frontend struct {
+	Name      string
+	Protocol  string
+	Listen    string
+	Algorithm string
+	Pools     []Pool
+}
+
+// Pool is a pool of a frontend. Admin, if set, reports the admin state of
+// its backends; without it they are all shown as enabled.
+type Pool struct {
+	Name       string
+	Repository port.BackendRepository
+	Admin      port.BackendAdmin
+}
+
+type backendKey struct {
+	frontend string
+	backend  string
+}
+
+type backendStats struct {
+	active   atomic.Int64
+	total    atomic.Int64
+	sent     atomic.Int64
+	received atomic.Int64
+
+	mu         sync.Mutex
+	errors     map[string]int64
+	unhealthy  bool
+	lastChange time.Time
+	history    []model.HealthCheck
+}
+
+type frontendStats struct {
+	active atomic.Int64
+	total  atomic.Int64
+	queue  atomic.Int64
+
+	mu       sync.Mutex
+	errors   map[string]int64
+	rejected map[string]int64
+}
+
+// Tracker keeps the in-memory counters behind the status page. It is fed
+// like any other metrics collector, through ForFrontend.
+type Tracker struct {
+	version string
+	started time.Time
+
+	mu        sync.RWMutex
+	frontends []Frontend
+	feStats   map[string]*frontendStats
+	backends  map[backendKey]*backendStats
+}
+
+func New(version string) *Tracker {
+	return &Tracker{
+		version:  version,
+		started:  time.Now(),
+		feStats:  make(map[string]*frontendStats),
+		backends: make(map[backendKey]*backendStats),
+	}
+}
+
+// AddFrontend lists fe on the status page, in the order added.
+func (t *Tracker) AddFrontend(fe Frontend) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.frontends = append(t.frontends, fe)
+}
+
+// ForFrontend returns a collector counting under the given frontend.
+func (t *Tracker) ForFrontend(frontend string) port.MetricsCollector {
+	return &collector{tracker: t, frontend: t.frontend(frontend), name: frontend}
+}
+
+// RemoveBackend drops the counters of backend for every frontend.
+func (t *Tracker) RemoveBackend(backend string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key := range t.backends {
+		if key.backend == backend {
+			delete(t.backends, key)
+		}
+	}
+}
+
+// TotalSessions returns the number of sessions handled by all frontends.
+func (t *Tracker) TotalSessions() int64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	var total int64
+	for _, stats := range t.feStats {
+		total += stats.total.Load()
+	}
+	return total
+}
+
+func (t *Tracker) frontend(name string) *frontendStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats, ok := t.feStats[name]
+	if !ok {
+		stats = &frontendStats{
+			errors:   make(map[string]int64),
+			rejected: make(map[string]int64),
+		}
+		t.feStats[name] = stats
+	}
+	return stats
+}
+
+func (t *Tracker) backend(frontend, backend string) *backendStats {
+	key := backendKey{frontend: frontend, backend: backend}
+
+	t.mu.RLock()
+	stats, ok := t.backends[key]
+	t.mu.RUnlock()
+	if ok {
+		return stats
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if stats, ok = t.backends[key]; !ok {
+		stats = &backendStats{errors: make(map[string]int64)}
+		t.backends[key] = stats
+	}
+	return stats
+}
+
+func (t *Tracker) Status(ctx context.Context) model.Status {
+	t.mu.RLock()
+	frontends := append([]Frontend(nil), t.frontends...)
+	t.mu.RUnlock()
+
+	status := model.Status{
+		Version:   t.version,
+		Started:   t.started,
+		Frontends: make([]model.FrontendStatus, 0, len(frontends)),
+	}
+	for _, fe := range frontends {
+		stats := t.frontend(fe.Name)
+		feStatus := model.FrontendStatus{
+			Name:           fe.Name,
+			Protocol:       fe.Protocol,
+			Listen:         fe.Listen,
+			Algorithm:      fe.Algorithm,
+			ActiveSessions: stats.active.Load(),
+			TotalSessions:  stats.total.Load(),
+			QueueDepth:     stats.queue.Load(),
+		}
+		stats.mu.Lock()
+		feStatus.Errors = copyCounts(stats.errors)
+		feStatus.Rejected = copyCounts(stats.rejected)
+		stats.mu.Unlock()
+
+		for _, pool := range fe.Pools {
+			feStatus.Pools = append(feStatus.Pools, t.poolStatus(ctx, fe.Name, pool))
+		}
+		status.Frontends = append(status.Frontends, feStatus)
+	}
+	return status
+}
+
+func (t *Tracker) poolStatus(ctx context.Context, frontend string, pool Pool) model.PoolStatus {
+	backends := pool.Repository.GetAll(ctx)
+	healthy := make(map[*model.Backend]bool, len(backends))
+	for _, b := range pool.Repository.GetHealthy(ctx) {
+		healthy[b] = true
+	}
+
+	poolStatus := model.PoolStatus{Name: pool.Name, Backends: make([]model.BackendStatus, 0, len(backends))}
+	for _, b := range backends {
+		addr := b.GetAddress()
+		stats := t.backend(frontend, addr)
+		backendStatus := model.BackendStatus{
+			ID:             b.GetID(),
+			Address:        addr,
+			Healthy:        healthy[b],
+			AdminState:     model.AdminEnabled,
+			Weight:         b.Weight,
+			MaxConnections: b.MaxConnections,
+			ActiveSessions: stats.active.Load(),
+			TotalSessions:  stats.total.Load(),
+			BytesSent:      stats.sent.Load(),
+			BytesReceived:  stats.received.Load(),
+		}
+		if pool.Admin != nil {
+			backendStatus.AdminState = pool.Admin.AdminState(ctx, b.ID)
+		}
+		stats.mu.Lock()
+		if backendStatus.AdminState != model.AdminEnabled {
+			// GetHealthy leaves out backends that aren't enabled, whatever
+			// their health, so the last health check tells instead.
+			backendStatus.Healthy = !stats.unhealthy
+		}
+		backendStatus.Errors = copyCounts(stats.errors)
+		backendStatus.LastChange = stats.lastChange
+		backendStatus.HealthChecks = append([]model.HealthCheck(nil), stats.history...)
+		stats.mu.Unlock()
+		poolStatus.Backends = append(poolStatus.Backends, backendStatus)
+	}
+	return poolStatus
+}
+
+func copyCounts(counts map[string]int64) map[string]int64 {
+	out := make(map[string]int64, len(counts))
+	for k, v := range counts {
+		out[k] = v
+	}
+	return out
+}
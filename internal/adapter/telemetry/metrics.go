@@ -0,0 +1,235 @@
+package telemetry
+
+import (
+	"context"
+	"sync"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/port"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const instrumentationName = "github.com/reybrally/TCP-Load-Balancer"
+
+// latencyBuckets matches the Prometheus dial and first byte histograms.
+var latencyBuckets = []float64{
+	0.0005, 0.001, 0.002, 0.004, 0.008, 0.016, 0.032, 0.064,
+	0.128, 0.256, 0.512, 1.024, 2.048, 4.096,
+}
+
+type instruments struct {
+	connectionsTotal    metric.Int64Counter
+	connectionsActive   metric.Int64UpDownCounter
+	connectionErrors    metric.Int64Counter
+	connectionDuration  metric.Float64Histogram
+	bytesSent           metric.Int64Counter
+	bytesReceived       metric.Int64Counter
+	dialLatency         metric.Float64Histogram
+	timeToFirstByte     metric.Float64Histogram
+	healthChecksTotal   metric.Int64Counter
+	tlsHandshakeErrors  metric.Int64Counter
+	datagramsTotal      metric.Int64Counter
+	datagramBytesTotal  metric.Int64Counter
+	queueDepth          metric.Int64UpDownCounter
+	queueWait           metric.Float64Histogram
+	rejectedConnections metric.Int64Counter
+
+	// Backend health is observed from this map so removed backends can be
+	// dropped; synchronous instruments can't forget a series.
+	mu     sync.Mutex
+	health map[healthKey]int64
+}
+
+type healthKey struct {
+	frontend string
+	backend  string
+}
+
+// Metrics is a port.MetricsCollector recording into OpenTelemetry
+// instruments, with the same attributes as the Prometheus labels.
+type Metrics struct {
+	*instruments
+	frontend string
+}
+
+func NewMetrics(provider metric.MeterProvider) (*Metrics, error) {
+	meter := provider.Meter(instrumentationName)
+	in := &instruments{health: make(map[healthKey]int64)}
+
+	var err error
+	counter := func(name, unit, desc string) metric.Int64Counter {
+		var c metric.Int64Counter
+		if err == nil {
+			c, err = meter.Int64Counter(name, metric.WithUnit(unit), metric.WithDescription(desc))
+		}
+		return c
+	}
+	upDown := func(name, desc string) metric.Int64UpDownCounter {
+		var c metric.Int64UpDownCounter
+		if err == nil {
+			c, err = meter.Int64UpDownCounter(name, metric.WithUnit("{connection}"), metric.WithDescription(desc))
+		}
+		return c
+	}
+	histogram := func(name, desc string, buckets ...float64) metric.Float64Histogram {
+		var h metric.Float64Histogram
+		if err == nil {
+			opts := []metric.Float64HistogramOption{metric.WithUnit("s"), metric.WithDescription(desc)}
+			if len(buckets) > 0 {
+				opts = append(opts, metric.WithExplicitBucketBoundaries(buckets...))
+			}
+			h, err = meter.Float64Histogram(name, opts...)
+		}
+		return h
+	}
+
+	in.connectionsTotal = counter("tcp_lb.connections", "{connection}", "Total number of connections handled")
+	in.connectionsActive = upDown("tcp_lb.connections.active", "Number of active connections")
+	in.connectionErrors = counter("tcp_lb.connection.errors", "{error}", "Total number of connection errors")
+	in.connectionDuration = histogram("tcp_lb.connection.duration", "Connection duration")
+	in.bytesSent = counter("tcp_lb.bytes.sent", "By", "Bytes forwarded from clients to backends")
+	in.bytesReceived = counter("tcp_lb.bytes.received", "By", "Bytes forwarded from backends to clients")
+	in.dialLatency = histogram("tcp_lb.backend.dial.duration", "Time to connect to a backend", latencyBuckets...)
+	in.timeToFirstByte = histogram("tcp_lb.backend.time_to_first_byte", "Time from connecting until the backend's first byte", latencyBuckets...)
+	in.healthChecksTotal = counter("tcp_lb.health_checks", "{check}", "Total number of health checks performed")
+	in.tlsHandshakeErrors = counter("tcp_lb.tls.handshake_errors", "{error}", "Total number of failed TLS handshakes")
+	in.datagramsTotal = counter("tcp_lb.udp.packets", "{packet}", "Total number of UDP datagrams relayed")
+	in.datagramBytesTotal = counter("tcp_lb.udp.bytes", "By", "Total bytes of UDP datagrams relayed")
+	in.queueDepth = upDown("tcp_lb.queue.depth", "Connections waiting for a backend with free capacity")
+	in.queueWait = histogram("tcp_lb.queue.wait", "Time connections spent waiting in the queue")
+	in.rejectedConnections = counter("tcp_lb.connections.rejected", "{connection}", "Connections refused before reaching a backend")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = meter.Int64ObservableGauge("tcp_lb.backend.healthy",
+		metric.WithDescription("Backend health status (1 = healthy, 0 = unhealthy)"),
+		metric.WithInt64Callback(in.observeHealth))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Metrics{instruments: in, frontend: "default"}, nil
+}
+
+func (in *instruments) observeHealth(_ context.Context, o metric.Int64Observer) error {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	for key, value := range in.health {
+		o.Observe(value, metric.WithAttributes(
+			attribute.String("frontend", key.frontend),
+			attribute.String("backend", key.backend),
+		))
+	}
+	return nil
+}
+
+// ForFrontend returns a collector sharing the same instruments but
+// reporting under the given frontend attribute.
+func (m *Metrics) ForFrontend(frontend string) port.MetricsCollector {
+	scoped := *m
+	scoped.frontend = frontend
+	return &scoped
+}
+
+// RemoveBackend stops reporting the health of backend. Counters and
+// histograms keep their last cumulative values.
+func (m *Metrics) RemoveBackend(backend string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key := range m.health {
+		if key.backend == backend {
+			delete(m.health, key)
+		}
+	}
+}
+
+func (m *Metrics) attrs(kv ...attribute.KeyValue) metric.MeasurementOption {
+	return metric.WithAttributes(append([]attribute.KeyValue{attribute.String("frontend", m.frontend)}, kv...)...)
+}
+
+func (m *Metrics) backend(backend string) metric.MeasurementOption {
+	return m.attrs(attribute.String("backend", backend))
+}
+
+func (m *Metrics) IncConnectionsTotal(backend string) {
+	m.connectionsTotal.Add(context.Background(), 1, m.backend(backend))
+}
+
+func (m *Metrics) IncConnectionsActive(backend string) {
+	m.connectionsActive.Add(context.Background(), 1, m.backend(backend))
+}
+
+func (m *Metrics) DecConnectionsActive(backend string) {
+	m.connectionsActive.Add(context.Background(), -1, m.backend(backend))
+}
+
+func (m *Metrics) IncConnectionErrors(backend string, errorType string) {
+	m.connectionErrors.Add(context.Background(), 1, m.attrs(
+		attribute.String("backend", backend),
+		attribute.String("error_type", errorType),
+	))
+}
+
+func (m *Metrics) ObserveConnectionDuration(backend string, duration float64) {
+	m.connectionDuration.Record(context.Background(), duration, m.backend(backend))
+}
+
+func (m *Metrics) AddBytesSent(backend string, n int) {
+	m.bytesSent.Add(context.Background(), int64(n), m.backend(backend))
+}
+
+func (m *Metrics) AddBytesReceived(backend string, n int) {
+	m.bytesReceived.Add(context.Background(), int64(n), m.backend(backend))
+}
+
+func (m *Metrics) ObserveDialLatency(backend string, seconds float64) {
+	m.dialLatency.Record(context.Background(), seconds, m.backend(backend))
+}
+
+func (m *Metrics) ObserveTimeToFirstByte(backend string, seconds float64) {
+	m.timeToFirstByte.Record(context.Background(), seconds, m.backend(backend))
+}
+
+func (m *Metrics) SetBackendHealthStatus(backend string, healthy bool) {
+	var value int64
+	if healthy {
+		value = 1
+	}
+	m.mu.Lock()
+	m.health[healthKey{frontend: m.frontend, backend: backend}] = value
+	m.mu.Unlock()
+}
+
+func (m *Metrics) IncHealthChecksTotal(backend string, status string) {
+	m.healthChecksTotal.Add(context.Background(), 1, m.attrs(
+		attribute.String("backend", backend),
+		attribute.String("status", status),
+	))
+}
+
+func (m *Metrics) IncTLSHandshakeErrors(reason string) {
+	m.tlsHandshakeErrors.Add(context.Background(), 1, m.attrs(attribute.String("reason", reason)))
+}
+
+func (m *Metrics) ObserveDatagram(backend string, direction string, size int) {
+	attrs := m.attrs(attribute.String("backend", backend), attribute.String("direction", direction))
+	m.datagramsTotal.Add(context.Background(), 1, attrs)
+	m.datagramBytesTotal.Add(context.Background(), int64(size), attrs)
+}
+
+func (m *Metrics) IncQueueDepth() {
+	m.queueDepth.Add(context.Background(), 1, m.attrs())
+}
+
+func (m *Metrics) DecQueueDepth() {
+	m.queueDepth.Add(context.Background(), -1, m.attrs())
+}
+
+func (m *Metrics) ObserveQueueWait(seconds float64) {
+	m.queueWait.Record(context.Background(), seconds, m.attrs())
+}
+
+func (m *Metrics) IncRejectedConnections(reason string) {
+	m.rejectedConnections.Add(context.Background(), 1, m.attrs(attribute.String("reason", reason)))
+}
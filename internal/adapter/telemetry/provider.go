@@ -0,0 +1,99 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+type Options struct {
+	// Endpoint is the host:port of an OTLP/HTTP receiver.
+	Endpoint    string
+	Insecure    bool
+	Headers     map[string]string
+	Interval    time.Duration
+	ServiceName string
+	Traces      bool
+}
+
+// Provider exports metrics, and traces if enabled, over OTLP/HTTP.
+type Provider struct {
+	meters  *sdkmetric.MeterProvider
+	tracers *sdktrace.TracerProvider
+}
+
+func NewProvider(ctx context.Context, opts Options) (*Provider, error) {
+	res := resource.NewSchemaless(attribute.String("service.name", opts.ServiceName))
+
+	metricOpts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(opts.Endpoint)}
+	if opts.Insecure {
+		metricOpts = append(metricOpts, otlpmetrichttp.WithInsecure())
+	}
+	if len(opts.Headers) > 0 {
+		metricOpts = append(metricOpts, otlpmetrichttp.WithHeaders(opts.Headers))
+	}
+	metricExporter, err := otlpmetrichttp.New(ctx, metricOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var readerOpts []sdkmetric.PeriodicReaderOption
+	if opts.Interval > 0 {
+		readerOpts = append(readerOpts, sdkmetric.WithInterval(opts.Interval))
+	}
+	p := &Provider{
+		meters: sdkmetric.NewMeterProvider(
+			sdkmetric.WithResource(res),
+			sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter, readerOpts...)),
+		),
+	}
+
+	if opts.Traces {
+		traceOpts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(opts.Endpoint)}
+		if opts.Insecure {
+			traceOpts = append(traceOpts, otlptracehttp.WithInsecure())
+		}
+		if len(opts.Headers) > 0 {
+			traceOpts = append(traceOpts, otlptracehttp.WithHeaders(opts.Headers))
+		}
+		traceExporter, err := otlptracehttp.New(ctx, traceOpts...)
+		if err != nil {
+			p.meters.Shutdown(ctx)
+			return nil, err
+		}
+		p.tracers = sdktrace.NewTracerProvider(
+			sdktrace.WithResource(res),
+			sdktrace.WithBatcher(traceExporter),
+		)
+	}
+
+	return p, nil
+}
+
+func (p *Provider) Metrics() (*Metrics, error) {
+	return NewMetrics(p.meters)
+}
+
+// Tracer returns nil unless traces are enabled.
+func (p *Provider) Tracer() *Tracer {
+	if p.tracers == nil {
+		return nil
+	}
+	return NewTracer(p.tracers)
+}
+
+// Shutdown flushes pending metrics and spans and stops the exporters.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	err := p.meters.Shutdown(ctx)
+	if p.tracers != nil {
+		err = errors.Join(err, p.tracers.Shutdown(ctx))
+	}
+	return err
+}
@@ -0,0 +1,98 @@
+package telemetry
+
+import (
+	"context"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/port"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracer records each TCP session as a "session" span, started when the
+// connection is accepted and ended when it closes, with a child span per
+// phase. The time between accept and the handler starting is recorded as a
+// "handshake" child span.
+type Tracer struct {
+	tracer   trace.Tracer
+	frontend string
+}
+
+func NewTracer(provider trace.TracerProvider) *Tracer {
+	return &Tracer{
+		tracer:   provider.Tracer(instrumentationName),
+		frontend: "default",
+	}
+}
+
+// ForFrontend returns a tracer tagging sessions with the given frontend.
+func (t *Tracer) ForFrontend(frontend string) port.SessionTracer {
+	scoped := *t
+	scoped.frontend = frontend
+	return &scoped
+}
+
+func (t *Tracer) StartSession(ctx context.Context, client string, accepted time.Time) port.SessionSpan {
+	now := time.Now()
+	if accepted.IsZero() || accepted.After(now) {
+		accepted = now
+	}
+	ctx, span := t.tracer.Start(ctx, "session",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithTimestamp(accepted),
+		trace.WithAttributes(
+			attribute.String("frontend", t.frontend),
+			attribute.String("client", client),
+		))
+	_, handshake := t.tracer.Start(ctx, port.PhaseHandshake, trace.WithTimestamp(accepted))
+	handshake.End(trace.WithTimestamp(now))
+	return &sessionSpan{tracer: t.tracer, ctx: ctx, span: span}
+}
+
+type sessionSpan struct {
+	tracer trace.Tracer
+	ctx    context.Context
+	span   trace.Span
+}
+
+func (s *sessionSpan) Phase(name string) func(err error) {
+	_, span := s.tracer.Start(s.ctx, name)
+	return func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+func (s *sessionSpan) End(record *model.SessionRecord) {
+	attrs := []attribute.KeyValue{
+		attribute.String("close_reason", record.CloseReason),
+		attribute.Int64("bytes_sent", record.BytesSent),
+		attribute.Int64("bytes_received", record.BytesReceived),
+		attribute.Int("retries", record.Retries),
+	}
+	if record.Backend != "" {
+		attrs = append(attrs, attribute.String("backend", record.Backend))
+	}
+	if record.TLSVersion != "" {
+		attrs = append(attrs, attribute.String("tls_version", record.TLSVersion))
+	}
+	if record.ServerName != "" {
+		attrs = append(attrs, attribute.String("sni", record.ServerName))
+	}
+	if record.ALPN != "" {
+		attrs = append(attrs, attribute.String("alpn", record.ALPN))
+	}
+	s.span.SetAttributes(attrs...)
+
+	switch record.CloseReason {
+	case model.CloseClientClosed, model.CloseBackendClosed, model.CloseKilled, model.CloseCanceled, model.CloseIdleTimeout:
+	default:
+		s.span.SetStatus(codes.Error, record.CloseReason)
+	}
+	s.span.End()
+}
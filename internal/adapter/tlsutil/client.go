@@ -0,0 +1,48 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"fmt"
+)
+
+type ClientOptions struct {
+	ServerName         string
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	MinVersion         string
+	InsecureSkipVerify bool
+}
+
+// NewClientConfig builds the tls.Config used to dial backends. When
+// ServerName is empty the backend host is sent as SNI and verified.
+func NewClientConfig(opts ClientOptions) (*tls.Config, error) {
+	minVersion, err := ParseVersion(opts.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &tls.Config{
+		ServerName:         opts.ServerName,
+		MinVersion:         minVersion,
+		InsecureSkipVerify: opts.InsecureSkipVerify,
+	}
+
+	if opts.CAFile != "" {
+		pool, err := LoadCertPool(opts.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = pool
+	}
+
+	if opts.CertFile != "" || opts.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
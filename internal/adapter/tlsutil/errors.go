@@ -0,0 +1,53 @@
+package tlsutil
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"strings"
+)
+
+// HandshakeErrorReason maps a failed server handshake to a short, bounded
+// label suitable for metrics.
+func HandshakeErrorReason(err error) string {
+	var (
+		netErr        net.Error
+		recordErr     tls.RecordHeaderError
+		alertErr      tls.AlertError
+		unknownAuth   x509.UnknownAuthorityError
+		invalidCert   x509.CertificateInvalidError
+		hostnameError x509.HostnameError
+	)
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, net.ErrClosed):
+		return "client_closed"
+	case errors.As(err, &recordErr):
+		return "not_tls"
+	case errors.As(err, &alertErr):
+		return "remote_alert"
+	case errors.As(err, &unknownAuth):
+		return "unknown_ca"
+	case errors.As(err, &invalidCert), errors.As(err, &hostnameError):
+		return "bad_certificate"
+	}
+
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "didn't provide a certificate"):
+		return "no_client_certificate"
+	case strings.Contains(msg, "protocol version"), strings.Contains(msg, "unsupported versions"):
+		return "protocol_version"
+	case strings.Contains(msg, "no cipher suite"):
+		return "no_shared_cipher"
+	case strings.Contains(msg, "connection reset"):
+		return "client_closed"
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,145 @@
+package tlsutil
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/filewatch"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+)
+
+type ServerOptions struct {
+	CertFile     string
+	KeyFile      string
+	MinVersion   string
+	MaxVersion   string
+	CipherSuites []string
+	ClientAuth   string
+	ClientCAFile string
+
+	// OnReload, if set, is called after every reload done by Watch.
+	OnReload func(err error)
+}
+
+// ServerReloader keeps the current server tls.Config built from files on
+// disk and swaps it atomically when they change. Handshakes already in
+// progress and established sessions keep the config they started with.
+type ServerReloader struct {
+	opts    ServerOptions
+	current atomic.Pointer[tls.Config]
+	watcher *filewatch.Watcher
+	logger  *logger.Logger
+}
+
+func NewServerReloader(opts ServerOptions, logger *logger.Logger) (*ServerReloader, error) {
+	paths := []string{opts.CertFile, opts.KeyFile}
+	if opts.ClientCAFile != "" {
+		paths = append(paths, opts.ClientCAFile)
+	}
+
+	r := &ServerReloader{
+		opts:    opts,
+		watcher: filewatch.New(paths...),
+		logger:  logger,
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *ServerReloader) Reload() error {
+	cfg, err := buildServerConfig(r.opts)
+	if err != nil {
+		return err
+	}
+	r.current.Store(cfg)
+	return nil
+}
+
+// Config returns the tls.Config to hand to tls.Server. It resolves the
+// actual settings per handshake, so it never needs to be replaced.
+func (r *ServerReloader) Config() *tls.Config {
+	return &tls.Config{
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return r.current.Load(), nil
+		},
+	}
+}
+
+func (r *ServerReloader) Watch(ctx context.Context, interval time.Duration) {
+	r.watcher.Run(ctx, interval, func() {
+		err := r.Reload()
+		if err != nil {
+			r.logger.Warnf("Failed to reload TLS certificate, keeping previous one: %v", err)
+		} else {
+			r.logger.Infof("TLS certificate reloaded from %s", r.opts.CertFile)
+		}
+		if r.opts.OnReload != nil {
+			r.opts.OnReload(err)
+		}
+	})
+}
+
+func buildServerConfig(opts ServerOptions) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load certificate: %w", err)
+	}
+
+	minVersion, err := ParseVersion(opts.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	maxVersion, err := ParseVersion(opts.MaxVersion)
+	if err != nil {
+		return nil, err
+	}
+	cipherSuites, err := ParseCipherSuites(opts.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+	clientAuth, err := ParseClientAuth(opts.ClientAuth)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   minVersion,
+		MaxVersion:   maxVersion,
+		CipherSuites: cipherSuites,
+		ClientAuth:   clientAuth,
+	}
+
+	if opts.ClientCAFile != "" {
+		pool, err := LoadCertPool(opts.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ClientCAs = pool
+		if clientAuth == tls.NoClientCert {
+			cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+
+	return cfg, nil
+}
+
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+	return pool, nil
+}
@@ -2,12 +2,32 @@ package usecase
 
 import (
 	"context"
-	"io"
+	"crypto/tls"
+	"errors"
 	"net"
 	"time"
 
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
 	"github.com/reybrally/TCP-Load-Balancer/internal/domain/port"
 	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/proxyproto"
+)
+
+const (
+	BackendHandshakeTimeout = 10 * time.Second
+	DefaultQueueTimeout     = 5 * time.Second
+
+	// queuePollInterval bounds how long a queued connection waits before
+	// checking again, since capacity can also be freed by other frontends
+	// sharing the pool.
+	queuePollInterval = 100 * time.Millisecond
+)
+
+var (
+	errNoHealthyBackends = errors.New("no healthy backends available")
+	errBackendsFull      = errors.New("all backends are at their connection limit")
+	errQueueFull         = errors.New("connection queue is full")
+	errQueueTimeout      = errors.New("timed out waiting in connection queue")
 )
 
 type HandleConnectionUseCase struct {
@@ -15,81 +35,446 @@ type HandleConnectionUseCase struct {
 	repository port.BackendRepository
 	metrics    port.MetricsCollector
 	logger     *logger.Logger
+	backendTLS *tls.Config
+
+	proxyProtocolVersion int
+	queue                *waitQueue
+	accessLog            port.AccessLogger
+	sessions             *SessionRegistry
+	frontend             string
+	tracer               port.SessionTracer
+	events               port.EventPublisher
+}
+
+type Option func(*HandleConnectionUseCase)
+
+// WithBackendTLS makes the use case dial backends over TLS. If the config has
+// no ServerName, the backend host is used for SNI and verification.
+func WithBackendTLS(config *tls.Config) Option {
+	return func(hc *HandleConnectionUseCase) {
+		hc.backendTLS = config
+	}
+}
+
+// WithProxyProtocol sends a PROXY protocol header of the given version (1 or
+// 2) to the backend right after connecting, before any TLS handshake.
+func WithProxyProtocol(version int) Option {
+	return func(hc *HandleConnectionUseCase) {
+		hc.proxyProtocolVersion = version
+	}
+}
+
+// WithQueue lets up to size connections wait, for at most timeout, when
+// every healthy backend is at its connection limit. Without a queue such
+// connections are rejected right away.
+func WithQueue(size int, timeout time.Duration) Option {
+	return func(hc *HandleConnectionUseCase) {
+		if size <= 0 {
+			return
+		}
+		if timeout <= 0 {
+			timeout = DefaultQueueTimeout
+		}
+		hc.queue = newWaitQueue(size, timeout)
+	}
+}
+
+// WithAccessLog records every connection to logger once it ends.
+func WithAccessLog(logger port.AccessLogger) Option {
+	return func(hc *HandleConnectionUseCase) {
+		hc.accessLog = logger
+	}
+}
+
+// WithSessions registers every proxied session in registry under the given
+// frontend name, so it can be listed and terminated while active.
+func WithSessions(registry *SessionRegistry, frontend string) Option {
+	return func(hc *HandleConnectionUseCase) {
+		hc.sessions = registry
+		hc.frontend = frontend
+	}
+}
+
+// WithTracer traces every connection as a session span with a child span
+// per phase.
+func WithTracer(tracer port.SessionTracer) Option {
+	return func(hc *HandleConnectionUseCase) {
+		hc.tracer = tracer
+	}
+}
+
+// WithEvents publishes a connection_rejected event for every connection
+// turned away because no backend could take it.
+func WithEvents(events port.EventPublisher) Option {
+	return func(hc *HandleConnectionUseCase) {
+		hc.events = events
+	}
 }
 
-func New(balancer port.LoadBalancer, repository port.BackendRepository, metrics port.MetricsCollector, logger *logger.Logger) *HandleConnectionUseCase {
-	return &HandleConnectionUseCase{
+func New(balancer port.LoadBalancer, repository port.BackendRepository, metrics port.MetricsCollector, logger *logger.Logger, opts ...Option) *HandleConnectionUseCase {
+	hc := &HandleConnectionUseCase{
 		balancer:   balancer,
 		repository: repository,
 		metrics:    metrics,
 		logger:     logger,
 	}
+	for _, opt := range opts {
+		opt(hc)
+	}
+	return hc
 }
 
 func (hc *HandleConnectionUseCase) Handle(ctx context.Context, clientConn net.Conn) error {
 	defer clientConn.Close()
 
+	// The session runs from accept, so listener-side PROXY, TLS and SNI
+	// work counts towards its duration.
 	startTime := time.Now()
+	if accepted, ok := port.AcceptTime(ctx); ok {
+		startTime = accepted
+	}
 
 	hc.logger.Debugf("New connection from %s", clientConn.RemoteAddr().String())
 
-	healthyBackends := hc.repository.GetHealthy(ctx)
-	if len(healthyBackends) == 0 {
+	record := &model.SessionRecord{
+		Client: clientConn.RemoteAddr().String(),
+		Start:  startTime,
+	}
+	record.TLSVersion, record.ServerName, record.ALPN = tlsInfo(clientConn)
+	span := hc.startSpan(ctx, record.Client, startTime)
+	defer func() {
+		record.Duration = time.Since(startTime)
+		if hc.accessLog != nil {
+			hc.accessLog.LogSession(record)
+		}
+		span.End(record)
+	}()
+
+	endSelect := span.Phase(port.PhaseSelect)
+	backend, err := hc.acquireBackend(ctx, &record.Retries)
+	endSelect(err)
+	switch {
+	case err == errNoHealthyBackends:
 		hc.logger.Warnf("No healthy backends available for client %s", clientConn.RemoteAddr().String())
-		hc.metrics.IncConnectionErrors("all", "no_healthy_backends")
+		hc.metrics.IncConnectionErrors("all", model.CloseNoHealthyBackends)
+		record.CloseReason = model.CloseNoHealthyBackends
+		hc.publishRejection(record)
 		clientConn.Write([]byte("No backends available\n"))
 		return nil
-	}
-
-	backend, err := hc.balancer.SelectBackend(healthyBackends)
-	if err != nil {
+	case err == errBackendsFull || err == errQueueFull || err == errQueueTimeout:
+		hc.logger.Warnf("Rejecting client %s: %v", clientConn.RemoteAddr().String(), err)
+		hc.metrics.IncConnectionErrors("all", rejectReason(err))
+		record.CloseReason = rejectReason(err)
+		hc.publishRejection(record)
+		clientConn.Write([]byte("Backends busy\n"))
+		return nil
+	case err != nil:
 		hc.logger.Errorf("Failed to select backend: %v", err)
 		hc.metrics.IncConnectionErrors("all", "backend_selection_failed")
+		record.CloseReason = model.CloseError
+		if ctx.Err() != nil {
+			record.CloseReason = model.CloseCanceled
+		}
 		return err
 	}
+	defer hc.release(backend)
 
 	backendAddr := backend.GetAddress()
+	record.Backend = backendAddr
 
 	hc.logger.Debugf("Routing connection from %s to backend %s", clientConn.RemoteAddr().String(), backendAddr)
 
-	backendConn, err := net.Dial("tcp", backendAddr)
+	endDial := span.Phase(port.PhaseDial)
+	backendConn, err := hc.dialBackend(ctx, clientConn, backend)
+	endDial(err)
 	if err != nil {
-		hc.logger.Errorf("Failed to connect to backend %s: %v", backendAddr, err)
-		hc.metrics.IncConnectionErrors(backendAddr, "connection_failed")
+		record.CloseReason = model.CloseDialFailed
 		clientConn.Write([]byte("Backend unavailable\n"))
 		return err
 	}
 	defer backendConn.Close()
+	connectedAt := time.Now()
 
 	hc.metrics.IncConnectionsTotal(backendAddr)
 	hc.metrics.IncConnectionsActive(backendAddr)
 	defer hc.metrics.DecConnectionsActive(backendAddr)
 
-	backend.IncreaseConnections()
-	defer backend.DecreaseConnections()
+	s := newSession(hc.frontend, record.Client, backendAddr, clientConn, backendConn)
+	if hc.sessions != nil {
+		hc.sessions.add(s)
+		defer hc.sessions.remove(s)
+	}
 
-	err = hc.proxyConnections(clientConn, backendConn)
+	endProxy := span.Phase(port.PhaseProxy)
+	hc.proxyConnections(s, clientConn, backendConn, connectedAt, record)
+	endProxy(nil)
 
 	duration := time.Since(startTime).Seconds()
 	hc.metrics.ObserveConnectionDuration(backendAddr, duration)
 
-	return err
+	return nil
+}
+
+func (hc *HandleConnectionUseCase) startSpan(ctx context.Context, client string, accepted time.Time) port.SessionSpan {
+	if hc.tracer == nil {
+		return noopSpan{}
+	}
+	return hc.tracer.StartSession(ctx, client, accepted)
+}
+
+func (hc *HandleConnectionUseCase) publishRejection(record *model.SessionRecord) {
+	if hc.events == nil {
+		return
+	}
+	hc.events.Publish(model.Event{
+		Type:   model.EventConnectionRejected,
+		Client: record.Client,
+		Reason: record.CloseReason,
+	})
+}
+
+type noopSpan struct{}
+
+func (noopSpan) Phase(string) func(error) { return func(error) {} }
+func (noopSpan) End(*model.SessionRecord) {}
+
+// acquireBackend selects a healthy backend with free capacity and counts the
+// connection against it. When every backend is full the connection waits in
+// the queue, if there is one. New connections queue behind waiting ones.
+// Each failed attempt by a queued connection is added to retries.
+func (hc *HandleConnectionUseCase) acquireBackend(ctx context.Context, retries *int) (*model.Backend, error) {
+	if hc.queue == nil {
+		return hc.tryAcquire(ctx)
+	}
+
+	try := func() (*model.Backend, error) { return hc.tryAcquire(ctx) }
+	backend, waiter, err := hc.queue.enter(try)
+	if waiter == nil {
+		return backend, err
+	}
+
+	start := time.Now()
+	hc.metrics.IncQueueDepth()
+	defer func() {
+		hc.metrics.DecQueueDepth()
+		hc.metrics.ObserveQueueWait(time.Since(start).Seconds())
+	}()
+
+	timeout := time.NewTimer(hc.queue.timeout)
+	defer timeout.Stop()
+	poll := time.NewTicker(queuePollInterval)
+	defer poll.Stop()
+
+	for {
+		select {
+		case <-waiter.Value.(chan struct{}):
+		case <-poll.C:
+			if !hc.queue.isHead(waiter) {
+				continue
+			}
+		case <-timeout.C:
+			hc.queue.leave(waiter)
+			return nil, errQueueTimeout
+		case <-ctx.Done():
+			hc.queue.leave(waiter)
+			return nil, ctx.Err()
+		}
+
+		backend, err := hc.queue.retry(waiter, try)
+		if err != errBackendsFull {
+			return backend, err
+		}
+		*retries++
+	}
+}
+
+func (hc *HandleConnectionUseCase) tryAcquire(ctx context.Context) (*model.Backend, error) {
+	healthyBackends := hc.repository.GetHealthy(ctx)
+	if len(healthyBackends) == 0 {
+		return nil, errNoHealthyBackends
+	}
+	return acquire(hc.balancer, healthyBackends)
+}
+
+func (hc *HandleConnectionUseCase) release(backend *model.Backend) {
+	backend.DecreaseConnections()
+	if hc.queue != nil {
+		hc.queue.wake()
+	}
+}
+
+// acquire picks a backend below its connection limit and counts a
+// connection against it, retrying if another connection took the last slot.
+func acquire(balancer port.LoadBalancer, backends []*model.Backend) (*model.Backend, error) {
+	for {
+		available := withCapacity(backends)
+		if len(available) == 0 {
+			return nil, errBackendsFull
+		}
+		backend, err := balancer.SelectBackend(available)
+		if err != nil {
+			return nil, err
+		}
+		if backend.TryAcquire() {
+			return backend, nil
+		}
+	}
+}
+
+func withCapacity(backends []*model.Backend) []*model.Backend {
+	for i, backend := range backends {
+		if backend.HasCapacity() {
+			continue
+		}
+		available := make([]*model.Backend, 0, len(backends)-1)
+		available = append(available, backends[:i]...)
+		for _, b := range backends[i+1:] {
+			if b.HasCapacity() {
+				available = append(available, b)
+			}
+		}
+		return available
+	}
+	return backends
 }
 
-func (hc *HandleConnectionUseCase) proxyConnections(clientConn, backendConn net.Conn) error {
-	errChan := make(chan error, 2)
+func rejectReason(err error) string {
+	switch err {
+	case errQueueFull:
+		return model.CloseQueueFull
+	case errQueueTimeout:
+		return model.CloseQueueTimeout
+	default:
+		return model.CloseBackendsFull
+	}
+}
+
+func (hc *HandleConnectionUseCase) dialBackend(ctx context.Context, clientConn net.Conn, backend *model.Backend) (net.Conn, error) {
+	backendAddr := backend.GetAddress()
+
+	dialStart := time.Now()
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, backend.GetNetwork(), backendAddr)
+	if err != nil {
+		hc.logger.Errorf("Failed to connect to backend %s: %v", backendAddr, err)
+		hc.metrics.IncConnectionErrors(backendAddr, "connection_failed")
+		return nil, err
+	}
+	hc.metrics.ObserveDialLatency(backendAddr, time.Since(dialStart).Seconds())
+
+	if hc.proxyProtocolVersion != 0 {
+		if _, err := proxyHeader(hc.proxyProtocolVersion, clientConn).WriteTo(conn); err != nil {
+			conn.Close()
+			hc.logger.Errorf("Failed to send PROXY header to backend %s: %v", backendAddr, err)
+			hc.metrics.IncConnectionErrors(backendAddr, "proxy_protocol_failed")
+			return nil, err
+		}
+	}
+
+	if hc.backendTLS == nil {
+		return conn, nil
+	}
+
+	config := hc.backendTLS
+	if config.ServerName == "" && backend.GetNetwork() == model.NetworkTCP {
+		config = config.Clone()
+		config.ServerName = backend.Address
+	}
+
+	handshakeCtx, cancel := context.WithTimeout(ctx, BackendHandshakeTimeout)
+	defer cancel()
+
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(handshakeCtx); err != nil {
+		conn.Close()
+		hc.logger.Errorf("TLS handshake with backend %s failed: %v", backendAddr, err)
+		hc.metrics.IncConnectionErrors(backendAddr, "tls_handshake_failed")
+		return nil, err
+	}
+
+	return tlsConn, nil
+}
+
+// proxyHeader describes the client connection, including the TLS server name
+// and ALPN protocol when the listener terminated or peeked at TLS.
+func proxyHeader(version int, clientConn net.Conn) *proxyproto.Header {
+	header := &proxyproto.Header{
+		Version:     version,
+		Command:     proxyproto.CommandProxy,
+		Source:      clientConn.RemoteAddr(),
+		Destination: clientConn.LocalAddr(),
+	}
+
+	_, serverName, alpn := tlsInfo(clientConn)
+	if alpn != "" {
+		header.TLVs = append(header.TLVs, proxyproto.TLV{Type: proxyproto.TLVTypeALPN, Value: []byte(alpn)})
+	}
+	if serverName != "" {
+		header.TLVs = append(header.TLVs, proxyproto.TLV{Type: proxyproto.TLVTypeAuthority, Value: []byte(serverName)})
+	}
+	return header
+}
+
+// tlsInfo returns the TLS version, server name and ALPN protocol of a
+// connection the listener terminated TLS on, or the server name the SNI
+// router peeked at.
+func tlsInfo(clientConn net.Conn) (version, serverName, alpn string) {
+	switch conn := clientConn.(type) {
+	case *tls.Conn:
+		state := conn.ConnectionState()
+		return tls.VersionName(state.Version), state.ServerName, state.NegotiatedProtocol
+	case interface{ ServerName() string }:
+		return "", conn.ServerName(), ""
+	}
+	return "", "", ""
+}
+
+// proxyConnections relays data both ways until either side is done,
+// counting bytes per direction and the time from connectedAt until the
+// backend's first byte. The totals and close reason go into record.
+func (hc *HandleConnectionUseCase) proxyConnections(s *session, clientConn, backendConn net.Conn, connectedAt time.Time, record *model.SessionRecord) {
+	type result struct {
+		fromClient bool
+		err        error
+	}
+	results := make(chan result, 2)
 
 	go func() {
-		_, err := io.Copy(backendConn, clientConn)
-		errChan <- err
+		err := relay(backendConn, clientConn, nil, func(n int) {
+			s.addSent(n)
+			hc.metrics.AddBytesSent(s.backend, n)
+		})
+		results <- result{fromClient: true, err: err}
 	}()
 
 	go func() {
-		_, err := io.Copy(clientConn, backendConn)
-		errChan <- err
+		err := relay(clientConn, backendConn, func() {
+			hc.metrics.ObserveTimeToFirstByte(s.backend, time.Since(connectedAt).Seconds())
+		}, func(n int) {
+			s.addReceived(n)
+			hc.metrics.AddBytesReceived(s.backend, n)
+		})
+		results <- result{fromClient: false, err: err}
 	}()
 
-	<-errChan
+	first := <-results
 
-	return nil
+	// Stop the other direction and wait for it, so the byte counts are final.
+	clientConn.Close()
+	backendConn.Close()
+	<-results
+
+	record.BytesSent, record.BytesReceived = s.bytesSent.Load(), s.bytesReceived.Load()
+	switch {
+	case s.killed.Load():
+		record.CloseReason = model.CloseKilled
+	case first.fromClient && first.err == nil:
+		record.CloseReason = model.CloseClientClosed
+	case first.fromClient:
+		record.CloseReason = model.CloseClientError
+	case first.err == nil:
+		record.CloseReason = model.CloseBackendClosed
+	default:
+		record.CloseReason = model.CloseBackendError
+	}
 }
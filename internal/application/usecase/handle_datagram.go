@@ -0,0 +1,255 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/port"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+)
+
+const (
+	DefaultUDPSessionTimeout = 30 * time.Second
+	maxDatagramSize          = 64 * 1024
+
+	// maxPendingDatagrams is how many datagrams of a new session are kept
+	// while its backend socket is being dialed; later ones are dropped.
+	maxPendingDatagrams = 16
+)
+
+// HandleDatagramUseCase balances UDP traffic. Datagrams from the same client
+// address form a session that sticks to one backend until it has been idle
+// for the session timeout.
+type HandleDatagramUseCase struct {
+	balancer       port.LoadBalancer
+	repository     port.BackendRepository
+	metrics        port.MetricsCollector
+	logger         *logger.Logger
+	sessionTimeout time.Duration
+	accessLog      port.AccessLogger
+
+	mu       sync.Mutex
+	sessions map[string]*udpSession
+}
+
+type udpSession struct {
+	client     net.Addr
+	backend    *model.Backend
+	conn       net.Conn
+	lastActive atomic.Int64
+	startTime  time.Time
+
+	// ready is set once conn is dialed; until then datagrams wait in
+	// pending. Both are guarded by HandleDatagramUseCase.mu.
+	ready   bool
+	pending [][]byte
+
+	bytesSent     atomic.Int64
+	bytesReceived atomic.Int64
+}
+
+type DatagramOption func(*HandleDatagramUseCase)
+
+// WithDatagramAccessLog records every UDP session to logger once it ends.
+func WithDatagramAccessLog(logger port.AccessLogger) DatagramOption {
+	return func(hd *HandleDatagramUseCase) {
+		hd.accessLog = logger
+	}
+}
+
+func NewDatagram(balancer port.LoadBalancer, repository port.BackendRepository, metrics port.MetricsCollector, logger *logger.Logger, sessionTimeout time.Duration, opts ...DatagramOption) *HandleDatagramUseCase {
+	if sessionTimeout <= 0 {
+		sessionTimeout = DefaultUDPSessionTimeout
+	}
+	hd := &HandleDatagramUseCase{
+		balancer:       balancer,
+		repository:     repository,
+		metrics:        metrics,
+		logger:         logger,
+		sessionTimeout: sessionTimeout,
+		sessions:       make(map[string]*udpSession),
+	}
+	for _, opt := range opts {
+		opt(hd)
+	}
+	return hd
+}
+
+// HandleDatagram forwards payload to the client's backend. It is called
+// from the listener's read loop, so a new session's backend socket is
+// dialed in the background rather than holding up other clients.
+func (hd *HandleDatagramUseCase) HandleDatagram(ctx context.Context, conn net.PacketConn, client net.Addr, payload []byte) error {
+	key := client.String()
+
+	hd.mu.Lock()
+	session, ok := hd.sessions[key]
+	if !ok {
+		var err error
+		session, err = hd.newSession(ctx, client)
+		if err != nil {
+			hd.mu.Unlock()
+			return err
+		}
+		hd.sessions[key] = session
+		go hd.open(ctx, conn, session)
+	}
+	session.lastActive.Store(time.Now().UnixNano())
+	if !session.ready {
+		if len(session.pending) < maxPendingDatagrams {
+			session.pending = append(session.pending, payload)
+		} else {
+			hd.metrics.IncConnectionErrors(session.backend.GetAddress(), "datagram_dropped")
+		}
+		hd.mu.Unlock()
+		return nil
+	}
+	hd.mu.Unlock()
+
+	return hd.forward(session, payload)
+}
+
+func (hd *HandleDatagramUseCase) forward(session *udpSession, payload []byte) error {
+	backendAddr := session.backend.GetAddress()
+	if _, err := session.conn.Write(payload); err != nil {
+		hd.metrics.IncConnectionErrors(backendAddr, "datagram_write_failed")
+		return err
+	}
+	session.bytesSent.Add(int64(len(payload)))
+	hd.metrics.ObserveDatagram(backendAddr, "to_backend", len(payload))
+	return nil
+}
+
+func (hd *HandleDatagramUseCase) ActiveSessions() int {
+	hd.mu.Lock()
+	defer hd.mu.Unlock()
+	return len(hd.sessions)
+}
+
+// newSession picks a backend for client. hd.mu must be held.
+func (hd *HandleDatagramUseCase) newSession(ctx context.Context, client net.Addr) (*udpSession, error) {
+	healthyBackends := hd.repository.GetHealthy(ctx)
+	if len(healthyBackends) == 0 {
+		hd.metrics.IncConnectionErrors("all", "no_healthy_backends")
+		return nil, errNoHealthyBackends
+	}
+
+	backend, err := acquire(hd.balancer, healthyBackends)
+	if err == errBackendsFull {
+		hd.metrics.IncConnectionErrors("all", "backends_full")
+		return nil, err
+	}
+	if err != nil {
+		hd.metrics.IncConnectionErrors("all", "backend_selection_failed")
+		return nil, err
+	}
+
+	return &udpSession{
+		client:    client,
+		backend:   backend,
+		startTime: time.Now(),
+	}, nil
+}
+
+// open dials the session's backend without holding hd.mu, sends the
+// datagrams that arrived meanwhile and relays the replies.
+func (hd *HandleDatagramUseCase) open(ctx context.Context, conn net.PacketConn, session *udpSession) {
+	key := session.client.String()
+	backendAddr := session.backend.GetAddress()
+
+	backendConn, err := net.Dial("udp", backendAddr)
+	if err != nil {
+		hd.mu.Lock()
+		delete(hd.sessions, key)
+		hd.mu.Unlock()
+		session.backend.DecreaseConnections()
+		hd.metrics.IncConnectionErrors(backendAddr, "connection_failed")
+		hd.logger.Debugf("UDP session %s -> %s failed: %v", key, backendAddr, err)
+		return
+	}
+
+	hd.metrics.IncConnectionsTotal(backendAddr)
+	hd.metrics.IncConnectionsActive(backendAddr)
+	hd.logger.Debugf("New UDP session %s -> %s", key, backendAddr)
+
+	// Pending datagrams are sent under the lock, so that none arriving
+	// later can overtake them.
+	hd.mu.Lock()
+	session.conn = backendConn
+	for _, payload := range session.pending {
+		hd.forward(session, payload)
+	}
+	session.pending = nil
+	session.ready = true
+	hd.mu.Unlock()
+
+	hd.relayReplies(ctx, conn, session)
+}
+
+// relayReplies copies backend responses to the client and ends the session
+// once no datagram has been seen in either direction for the timeout.
+func (hd *HandleDatagramUseCase) relayReplies(ctx context.Context, conn net.PacketConn, session *udpSession) {
+	closeReason := model.CloseCanceled
+	defer func() { hd.closeSession(session, closeReason) }()
+
+	backendAddr := session.backend.GetAddress()
+	buf := make([]byte, maxDatagramSize)
+
+	for ctx.Err() == nil {
+		idle := time.Since(time.Unix(0, session.lastActive.Load()))
+		if idle >= hd.sessionTimeout {
+			closeReason = model.CloseIdleTimeout
+			return
+		}
+
+		session.conn.SetReadDeadline(time.Now().Add(hd.sessionTimeout - idle))
+		n, err := session.conn.Read(buf)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			hd.logger.Debugf("UDP session %s -> %s ended: %v", session.client, backendAddr, err)
+			closeReason = model.CloseBackendError
+			return
+		}
+
+		session.lastActive.Store(time.Now().UnixNano())
+		if _, err := conn.WriteTo(buf[:n], session.client); err != nil {
+			hd.metrics.IncConnectionErrors(backendAddr, "datagram_reply_failed")
+			continue
+		}
+		session.bytesReceived.Add(int64(n))
+		hd.metrics.ObserveDatagram(backendAddr, "to_client", n)
+	}
+}
+
+func (hd *HandleDatagramUseCase) closeSession(session *udpSession, reason string) {
+	hd.mu.Lock()
+	delete(hd.sessions, session.client.String())
+	hd.mu.Unlock()
+
+	session.conn.Close()
+
+	backendAddr := session.backend.GetAddress()
+	session.backend.DecreaseConnections()
+	hd.metrics.DecConnectionsActive(backendAddr)
+	hd.metrics.ObserveConnectionDuration(backendAddr, time.Since(session.startTime).Seconds())
+	hd.logger.Debugf("UDP session %s -> %s expired", session.client, backendAddr)
+
+	if hd.accessLog != nil {
+		hd.accessLog.LogSession(&model.SessionRecord{
+			Client:        session.client.String(),
+			Backend:       backendAddr,
+			Start:         session.startTime,
+			Duration:      time.Since(session.startTime),
+			BytesSent:     session.bytesSent.Load(),
+			BytesReceived: session.bytesReceived.Load(),
+			CloseReason:   reason,
+		})
+	}
+}
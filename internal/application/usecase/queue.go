@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+)
+
+// waitQueue holds connections waiting, in arrival order, for a backend with
+// free capacity. Finished connections wake the head of the queue.
+type waitQueue struct {
+	mu      sync.Mutex
+	waiters *list.List
+	size    int
+	timeout time.Duration
+}
+
+func newWaitQueue(size int, timeout time.Duration) *waitQueue {
+	return &waitQueue{
+		waiters: list.New(),
+		size:    size,
+		timeout: timeout,
+	}
+}
+
+// enter calls try for a new connection unless others are already waiting,
+// and queues the connection at the back if every backend is full. The
+// check and try happen under the queue lock, so a newcomer can't take a
+// slot a woken waiter is about to claim. The returned waiter is nil when
+// try settled the connection or the queue is full (errQueueFull).
+func (q *waitQueue) enter(try func() (*model.Backend, error)) (*model.Backend, *list.Element, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.waiters.Len() == 0 {
+		backend, err := try()
+		if err != errBackendsFull {
+			return backend, nil, err
+		}
+	}
+	if q.waiters.Len() >= q.size {
+		return nil, nil, errQueueFull
+	}
+	return nil, q.waiters.PushBack(make(chan struct{}, 1)), nil
+}
+
+// retry calls try for a woken waiter if it is at the head of the queue. It
+// leaves the queue once it has a backend or a final error; on
+// errBackendsFull it stays at the head so no one can overtake it.
+func (q *waitQueue) retry(waiter *list.Element, try func() (*model.Backend, error)) (*model.Backend, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.waiters.Front() != waiter {
+		return nil, errBackendsFull
+	}
+	backend, err := try()
+	if err == errBackendsFull {
+		return nil, err
+	}
+	q.waiters.Remove(waiter)
+	// Other waiters may fit too if more than one slot was freed.
+	q.wakeLocked()
+	return backend, err
+}
+
+func (q *waitQueue) leave(waiter *list.Element) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.waiters.Remove(waiter)
+}
+
+func (q *waitQueue) wake() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.wakeLocked()
+}
+
+func (q *waitQueue) wakeLocked() {
+	if head := q.waiters.Front(); head != nil {
+		select {
+		case head.Value.(chan struct{}) <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (q *waitQueue) isHead(waiter *list.Element) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.waiters.Front() == waiter
+}
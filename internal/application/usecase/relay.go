@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"io"
+)
+
+const relayBufferSize = 32 << 10
+
+// relay copies src to dst until EOF or an error, calling count after every
+// write so byte counts and idle times stay current for live sessions. If
+// firstByte is set, it is called once before the first write.
+func relay(dst io.Writer, src io.Reader, firstByte func(), count func(int)) error {
+	buf := make([]byte, relayBufferSize)
+	for {
+		n, err := src.Read(buf)
+		if n > 0 {
+			if firstByte != nil {
+				firstByte()
+				firstByte = nil
+			}
+			written, werr := dst.Write(buf[:n])
+			count(written)
+			if werr != nil {
+				return werr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+)
+
+// SessionRegistry tracks the sessions proxied by one or more use cases so
+// they can be listed and terminated while active. It implements
+// port.SessionManager.
+type SessionRegistry struct {
+	nextID atomic.Uint64
+
+	mu       sync.RWMutex
+	sessions map[uint64]*session
+}
+
+type session struct {
+	id       uint64
+	frontend string
+	client   string
+	backend  string
+	start    time.Time
+	conns    []net.Conn
+
+	bytesSent     atomic.Int64
+	bytesReceived atomic.Int64
+	lastActive    atomic.Int64
+	killed        atomic.Bool
+}
+
+func NewSessionRegistry() *SessionRegistry {
+	return &SessionRegistry{sessions: make(map[uint64]*session)}
+}
+
+func newSession(frontend, client, backend string, conns ...net.Conn) *session {
+	now := time.Now()
+	s := &session{
+		frontend: frontend,
+		client:   client,
+		backend:  backend,
+		start:    now,
+		conns:    conns,
+	}
+	s.lastActive.Store(now.UnixNano())
+	return s
+}
+
+func (r *SessionRegistry) add(s *session) {
+	s.id = r.nextID.Add(1)
+
+	r.mu.Lock()
+	r.sessions[s.id] = s
+	r.mu.Unlock()
+}
+
+func (r *SessionRegistry) remove(s *session) {
+	r.mu.Lock()
+	delete(r.sessions, s.id)
+	r.mu.Unlock()
+}
+
+// Sessions returns the active sessions matching filter, oldest first.
+func (r *SessionRegistry) Sessions(filter model.SessionFilter) []model.SessionInfo {
+	now := time.Now()
+
+	r.mu.RLock()
+	infos := make([]model.SessionInfo, 0, len(r.sessions))
+	for _, s := range r.sessions {
+		info := s.info(now)
+		if matches(info, filter) {
+			infos = append(infos, info)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos
+}
+
+func (r *SessionRegistry) Kill(id uint64) bool {
+	r.mu.RLock()
+	s, ok := r.sessions[id]
+	r.mu.RUnlock()
+
+	if ok {
+		s.kill()
+	}
+	return ok
+}
+
+func (r *SessionRegistry) KillBackend(backend string) int {
+	r.mu.RLock()
+	var victims []*session
+	for _, s := range r.sessions {
+		if s.backend == backend {
+			victims = append(victims, s)
+		}
+	}
+	r.mu.RUnlock()
+
+	for _, s := range victims {
+		s.kill()
+	}
+	return len(victims)
+}
+
+func (s *session) info(now time.Time) model.SessionInfo {
+	return model.SessionInfo{
+		ID:            s.id,
+		Frontend:      s.frontend,
+		Client:        s.client,
+		Backend:       s.backend,
+		Start:         s.start,
+		BytesSent:     s.bytesSent.Load(),
+		BytesReceived: s.bytesReceived.Load(),
+		Idle:          now.Sub(time.Unix(0, s.lastActive.Load())),
+	}
+}
+
+// kill closes the session's connections, which ends the relay.
+func (s *session) kill() {
+	s.killed.Store(true)
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+}
+
+func (s *session) addSent(n int) {
+	s.bytesSent.Add(int64(n))
+	s.lastActive.Store(time.Now().UnixNano())
+}
+
+func (s *session) addReceived(n int) {
+	s.bytesReceived.Add(int64(n))
+	s.lastActive.Store(time.Now().UnixNano())
+}
+
+func matches(info model.SessionInfo, filter model.SessionFilter) bool {
+	if filter.Frontend != "" && info.Frontend != filter.Frontend {
+		return false
+	}
+	if filter.Backend != "" && info.Backend != filter.Backend {
+		return false
+	}
+	if filter.Client != "" && info.Client != filter.Client {
+		host, _, err := net.SplitHostPort(info.Client)
+		if err != nil || host != filter.Client {
+			return false
+		}
+	}
+	return info.Idle >= filter.MinIdle
+}
@@ -1,9 +1,125 @@
 package config
 
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultFrontendName      = "default"
+	DefaultPoolName          = "default"
+	DefaultAlgorithm         = "round_robin"
+	DefaultUDPSessionTimeout = 30 * time.Second
+
+	ProtocolTCP                = "tcp"
+	ProtocolUDP                = "udp"
+	DefaultHealthCheckInterval = 10 * time.Second
+	DefaultHealthCheckTimeout  = 2 * time.Second
+	DefaultTLSReloadInterval   = 30 * time.Second
+	DefaultQueueTimeout        = 5 * time.Second
+	DefaultACLReloadInterval   = 10 * time.Second
+
+	AccessLogFormatJSON        = "json"
+	AccessLogFormatText        = "text"
+	AccessLogStdout            = "stdout"
+	DefaultAccessLogMaxBackups = 5
+
+	DefaultMetricsAddress = ":9090"
+	DefaultMetricsPath    = "/metrics"
+	DefaultAdminAddress   = "127.0.0.1:9092"
+
+	DefaultOTelEndpoint    = "localhost:4318"
+	DefaultOTelInterval    = 10 * time.Second
+	DefaultOTelServiceName = "tcp-load-balancer"
+
+	DefaultStatsDAddress       = "127.0.0.1:8125"
+	DefaultStatsDPrefix        = "tcp_lb"
+	DefaultStatsDFlushInterval = 10 * time.Second
+)
+
 type Config struct {
-	Server   ServerConfig    `mapstructure:"server"`
-	Backends []BackendConfig `mapstructure:"backends"`
-	App      AppConfig       `mapstructure:"app"`
+	Server    ServerConfig     `mapstructure:"server"`
+	Backends  []BackendConfig  `mapstructure:"backends"`
+	Frontends []FrontendConfig `mapstructure:"frontends"`
+	Pools     []PoolConfig     `mapstructure:"pools"`
+	AccessLog AccessLogConfig  `mapstructure:"access_log"`
+	Metrics   MetricsConfig    `mapstructure:"metrics"`
+	Admin     AdminConfig      `mapstructure:"admin"`
+	OTel      OTelConfig       `mapstructure:"otel"`
+	StatsD    StatsDConfig     `mapstructure:"statsd"`
+	App       AppConfig        `mapstructure:"app"`
+}
+
+// AccessLogConfig writes a line per finished session, as JSON or through a
+// text/template, to stdout or a file. Files rotate once they exceed
+// MaxSizeMB and are reopened on SIGUSR1.
+type AccessLogConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	Format     string `mapstructure:"format"`
+	Template   string `mapstructure:"template"`
+	Output     string `mapstructure:"output"`
+	MaxSizeMB  int    `mapstructure:"max_size_mb"`
+	MaxBackups int    `mapstructure:"max_backups"`
+}
+
+// MetricsConfig configures the HTTP server exposing Prometheus metrics and
+// /health. The loader turns both runtime collectors on unless the config
+// file says otherwise.
+type MetricsConfig struct {
+	Address          string `mapstructure:"address"`
+	Path             string `mapstructure:"path"`
+	GoCollector      bool   `mapstructure:"go_collector"`
+	ProcessCollector bool   `mapstructure:"process_collector"`
+}
+
+// AdminConfig configures the HTTP server for the admin endpoints, which can
+// terminate sessions. With Token set, requests must carry it as a bearer
+// token; without one the server must listen on a loopback address.
+type AdminConfig struct {
+	Address string `mapstructure:"address"`
+	Token   string `mapstructure:"token"`
+}
+
+func (a AdminConfig) validate() error {
+	if a.Token != "" {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(a.Address)
+	if err != nil {
+		return fmt.Errorf("invalid admin.address %q: %w", a.Address, err)
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return fmt.Errorf("admin.address %q is not a loopback address, so admin.token must be set", a.Address)
+	}
+	return nil
+}
+
+// OTelConfig sends metrics to an OTLP/HTTP receiver instead of the
+// Prometheus endpoint and, with Traces, a span per TCP session.
+type OTelConfig struct {
+	Enabled     bool              `mapstructure:"enabled"`
+	Endpoint    string            `mapstructure:"endpoint"`
+	Insecure    bool              `mapstructure:"insecure"`
+	Headers     map[string]string `mapstructure:"headers"`
+	Interval    time.Duration     `mapstructure:"interval"`
+	ServiceName string            `mapstructure:"service_name"`
+	Traces      bool              `mapstructure:"traces"`
+}
+
+// StatsDConfig sends metrics to a StatsD agent, with DogStatsD tags,
+// instead of the Prometheus endpoint. Metrics are aggregated in memory,
+// timings sampled, and sent once per FlushInterval.
+type StatsDConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	Address       string        `mapstructure:"address"`
+	Prefix        string        `mapstructure:"prefix"`
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+	Tags          []string      `mapstructure:"tags"`
+	MaxPacketSize int           `mapstructure:"max_packet_size"`
 }
 
 type ServerConfig struct {
@@ -11,13 +127,485 @@ type ServerConfig struct {
 	Host string `mapstructure:"host"`
 }
 
+// BackendConfig is either a host:port pair or, with Socket set, the path of
+// a Unix socket.
 type BackendConfig struct {
-	Address string `mapstructure:"address"`
-	Port    int    `mapstructure:"port"`
-	Weight  int    `mapstructure:"weight"`
+	Address        string `mapstructure:"address"`
+	Port           int    `mapstructure:"port"`
+	Socket         string `mapstructure:"socket"`
+	Weight         int    `mapstructure:"weight"`
+	MaxConnections int    `mapstructure:"max_connections"`
+}
+
+type FrontendConfig struct {
+	Name        string            `mapstructure:"name"`
+	Protocol    string            `mapstructure:"protocol"`
+	Host        string            `mapstructure:"host"`
+	Port        int               `mapstructure:"port"`
+	Algorithm   string            `mapstructure:"algorithm"`
+	Pool        string            `mapstructure:"pool"`
+	HealthCheck HealthCheckConfig `mapstructure:"health_check"`
+	TLS         TLSConfig         `mapstructure:"tls"`
+	SNI         SNIConfig         `mapstructure:"sni"`
+	AcceptProxy AcceptProxyConfig `mapstructure:"accept_proxy"`
+	UDP         UDPConfig         `mapstructure:"udp"`
+	Unix        UnixSocketConfig  `mapstructure:"unix"`
+	Acceptors   int               `mapstructure:"acceptors"`
+	Backlog     int               `mapstructure:"backlog"`
+
+	MaxConnections int             `mapstructure:"max_connections"`
+	Queue          QueueConfig     `mapstructure:"queue"`
+	RateLimit      RateLimitConfig `mapstructure:"rate_limit"`
+	ACL            ACLConfig       `mapstructure:"acl"`
+}
+
+// ACLConfig allows or denies clients by address. Entries are CIDRs or single
+// IPs; File holds more rules as "allow <cidr>" or "deny <cidr>" lines and is
+// reloaded when it changes.
+type ACLConfig struct {
+	Allow          []string      `mapstructure:"allow"`
+	Deny           []string      `mapstructure:"deny"`
+	File           string        `mapstructure:"file"`
+	ReloadInterval time.Duration `mapstructure:"reload_interval"`
+}
+
+func (a ACLConfig) Enabled() bool {
+	return len(a.Allow) > 0 || len(a.Deny) > 0 || a.File != ""
+}
+
+func (a ACLConfig) validate() error {
+	for _, entry := range append(append([]string{}, a.Allow...), a.Deny...) {
+		if _, _, err := net.ParseCIDR(entry); err == nil {
+			continue
+		}
+		if net.ParseIP(entry) == nil {
+			return fmt.Errorf("invalid acl entry %q", entry)
+		}
+	}
+	return nil
+}
+
+// RateLimitConfig limits new connections per second from one client IP and
+// from one network prefix, and open connections per client IP. Zero values
+// disable the corresponding limit.
+type RateLimitConfig struct {
+	Rate                float64 `mapstructure:"rate"`
+	Burst               int     `mapstructure:"burst"`
+	PrefixRate          float64 `mapstructure:"prefix_rate"`
+	PrefixBurst         int     `mapstructure:"prefix_burst"`
+	IPv4Prefix          int     `mapstructure:"ipv4_prefix"`
+	IPv6Prefix          int     `mapstructure:"ipv6_prefix"`
+	MaxConnectionsPerIP int     `mapstructure:"max_connections_per_ip"`
+	MaxTracked          int     `mapstructure:"max_tracked"`
+}
+
+func (r RateLimitConfig) Enabled() bool {
+	return r.Rate > 0 || r.PrefixRate > 0 || r.MaxConnectionsPerIP > 0
+}
+
+func (r RateLimitConfig) validate() error {
+	if r.Rate < 0 || r.Burst < 0 || r.PrefixRate < 0 || r.PrefixBurst < 0 || r.MaxConnectionsPerIP < 0 || r.MaxTracked < 0 {
+		return fmt.Errorf("rate_limit values must not be negative")
+	}
+	if r.IPv4Prefix < 0 || r.IPv4Prefix > 32 {
+		return fmt.Errorf("rate_limit.ipv4_prefix must be between 0 and 32")
+	}
+	if r.IPv6Prefix < 0 || r.IPv6Prefix > 128 {
+		return fmt.Errorf("rate_limit.ipv6_prefix must be between 0 and 128")
+	}
+	return nil
+}
+
+// QueueConfig lets connections wait for a free backend slot when every
+// backend is at max_connections, instead of being rejected.
+type QueueConfig struct {
+	Size    int           `mapstructure:"size"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// UnixSocketConfig makes a TCP frontend listen on a Unix socket instead of
+// host:port. Mode is an octal permission string such as "0660".
+type UnixSocketConfig struct {
+	Path  string `mapstructure:"path"`
+	Mode  string `mapstructure:"mode"`
+	User  string `mapstructure:"user"`
+	Group string `mapstructure:"group"`
+}
+
+func (u UnixSocketConfig) FileMode() (os.FileMode, error) {
+	if u.Mode == "" {
+		return 0, nil
+	}
+	mode, err := strconv.ParseUint(u.Mode, 8, 32)
+	if err != nil || mode > 0o777 {
+		return 0, fmt.Errorf("invalid unix socket mode %q", u.Mode)
+	}
+	return os.FileMode(mode), nil
+}
+
+type UDPConfig struct {
+	SessionTimeout time.Duration `mapstructure:"session_timeout"`
+}
+
+// AcceptProxyConfig makes the frontend read a PROXY protocol header sent by
+// an upstream load balancer. Only sources in TrustedCIDRs are expected to
+// send one, so at least one is required.
+type AcceptProxyConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	TrustedCIDRs  []string      `mapstructure:"trusted_cidrs"`
+	HeaderTimeout time.Duration `mapstructure:"header_timeout"`
+}
+
+// SNIConfig routes TLS connections by server name without terminating them.
+// The frontend pool, if set, receives connections that match no route.
+type SNIConfig struct {
+	Routes      []SNIRouteConfig `mapstructure:"routes"`
+	PeekTimeout time.Duration    `mapstructure:"peek_timeout"`
+}
+
+type SNIRouteConfig struct {
+	Hosts []string `mapstructure:"hosts"`
+	ALPN  []string `mapstructure:"alpn"`
+	Pool  string   `mapstructure:"pool"`
+}
+
+type TLSConfig struct {
+	Enabled          bool          `mapstructure:"enabled"`
+	CertFile         string        `mapstructure:"cert_file"`
+	KeyFile          string        `mapstructure:"key_file"`
+	MinVersion       string        `mapstructure:"min_version"`
+	MaxVersion       string        `mapstructure:"max_version"`
+	CipherSuites     []string      `mapstructure:"cipher_suites"`
+	ClientAuth       string        `mapstructure:"client_auth"`
+	ClientCAFile     string        `mapstructure:"client_ca_file"`
+	HandshakeTimeout time.Duration `mapstructure:"handshake_timeout"`
+	ReloadInterval   time.Duration `mapstructure:"reload_interval"`
+}
+
+type HealthCheckConfig struct {
+	Disabled bool          `mapstructure:"disabled"`
+	Interval time.Duration `mapstructure:"interval"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+}
+
+type PoolConfig struct {
+	Name          string              `mapstructure:"name"`
+	Backends      []BackendConfig     `mapstructure:"backends"`
+	TLS           BackendTLSConfig    `mapstructure:"tls"`
+	ProxyProtocol ProxyProtocolConfig `mapstructure:"proxy_protocol"`
+
+	// StateFile persists the pool's backends across restarts. Once it
+	// exists it replaces Backends, which only seed it.
+	StateFile string `mapstructure:"state_file"`
+}
+
+// ProxyProtocolConfig sends a HAProxy PROXY protocol header to the pool's
+// backends. With HealthCheck set, health checks send a LOCAL header too.
+type ProxyProtocolConfig struct {
+	Version     int  `mapstructure:"version"`
+	HealthCheck bool `mapstructure:"health_check"`
+}
+
+// BackendTLSConfig makes the balancer dial the pool's backends over TLS.
+type BackendTLSConfig struct {
+	Enabled            bool   `mapstructure:"enabled"`
+	ServerName         string `mapstructure:"server_name"`
+	CAFile             string `mapstructure:"ca_file"`
+	CertFile           string `mapstructure:"cert_file"`
+	KeyFile            string `mapstructure:"key_file"`
+	MinVersion         string `mapstructure:"min_version"`
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"`
 }
 
 type AppConfig struct {
 	Environment string `mapstructure:"environment"`
 	LogLevel    string `mapstructure:"log_level"`
 }
+
+// ApplyDefaults turns the legacy single server/backends layout into a
+// "default" frontend and pool, and fills in unset frontend settings.
+func (c *Config) ApplyDefaults() {
+	if len(c.Frontends) == 0 {
+		c.Frontends = []FrontendConfig{{
+			Name: DefaultFrontendName,
+			Host: c.Server.Host,
+			Port: c.Server.Port,
+			Pool: DefaultPoolName,
+		}}
+		if c.Pool(DefaultPoolName) == nil {
+			c.Pools = append(c.Pools, PoolConfig{
+				Name:     DefaultPoolName,
+				Backends: c.Backends,
+			})
+		}
+	}
+
+	if c.AccessLog.Enabled {
+		if c.AccessLog.Format == "" {
+			c.AccessLog.Format = AccessLogFormatJSON
+		}
+		if c.AccessLog.Output == "" {
+			c.AccessLog.Output = AccessLogStdout
+		}
+		if c.AccessLog.MaxBackups <= 0 {
+			c.AccessLog.MaxBackups = DefaultAccessLogMaxBackups
+		}
+	}
+
+	if c.Metrics.Address == "" {
+		c.Metrics.Address = DefaultMetricsAddress
+	}
+	if c.Metrics.Path == "" {
+		c.Metrics.Path = DefaultMetricsPath
+	}
+	if c.Admin.Address == "" {
+		c.Admin.Address = DefaultAdminAddress
+	}
+
+	if c.OTel.Enabled {
+		if c.OTel.Endpoint == "" {
+			c.OTel.Endpoint = DefaultOTelEndpoint
+		}
+		if c.OTel.Interval <= 0 {
+			c.OTel.Interval = DefaultOTelInterval
+		}
+		if c.OTel.ServiceName == "" {
+			c.OTel.ServiceName = DefaultOTelServiceName
+		}
+	}
+
+	if c.StatsD.Enabled {
+		if c.StatsD.Address == "" {
+			c.StatsD.Address = DefaultStatsDAddress
+		}
+		if c.StatsD.Prefix == "" {
+			c.StatsD.Prefix = DefaultStatsDPrefix
+		}
+		if c.StatsD.FlushInterval <= 0 {
+			c.StatsD.FlushInterval = DefaultStatsDFlushInterval
+		}
+	}
+
+	for i := range c.Frontends {
+		fe := &c.Frontends[i]
+		if fe.Protocol == "" {
+			fe.Protocol = ProtocolTCP
+		}
+		if fe.Protocol == ProtocolUDP {
+			if fe.UDP.SessionTimeout <= 0 {
+				fe.UDP.SessionTimeout = DefaultUDPSessionTimeout
+			}
+			// Health checks connect over TCP, which UDP services such as
+			// DNS or syslog often don't accept.
+			fe.HealthCheck.Disabled = true
+		}
+		if fe.Algorithm == "" {
+			fe.Algorithm = DefaultAlgorithm
+		}
+		if fe.Pool == "" && len(fe.SNI.Routes) == 0 {
+			fe.Pool = fe.Name
+		}
+		if fe.HealthCheck.Interval <= 0 {
+			fe.HealthCheck.Interval = DefaultHealthCheckInterval
+		}
+		if fe.HealthCheck.Timeout <= 0 {
+			fe.HealthCheck.Timeout = DefaultHealthCheckTimeout
+		}
+		if fe.Queue.Size > 0 && fe.Queue.Timeout <= 0 {
+			fe.Queue.Timeout = DefaultQueueTimeout
+		}
+		if fe.TLS.Enabled && fe.TLS.ReloadInterval <= 0 {
+			fe.TLS.ReloadInterval = DefaultTLSReloadInterval
+		}
+		if fe.ACL.File != "" && fe.ACL.ReloadInterval <= 0 {
+			fe.ACL.ReloadInterval = DefaultACLReloadInterval
+		}
+	}
+}
+
+func (c *Config) Validate() error {
+	switch c.AccessLog.Format {
+	case "", AccessLogFormatJSON, AccessLogFormatText:
+	default:
+		return fmt.Errorf("access_log has unknown format %q", c.AccessLog.Format)
+	}
+	if c.AccessLog.MaxSizeMB < 0 {
+		return fmt.Errorf("access_log.max_size_mb must not be negative")
+	}
+	if c.Metrics.Path != "" && !strings.HasPrefix(c.Metrics.Path, "/") {
+		return fmt.Errorf("metrics.path %q must start with /", c.Metrics.Path)
+	}
+	if c.Admin.Address != "" {
+		if err := c.Admin.validate(); err != nil {
+			return err
+		}
+	}
+	if c.OTel.Traces && !c.OTel.Enabled {
+		return fmt.Errorf("otel.traces requires otel.enabled")
+	}
+	if c.OTel.Enabled && c.StatsD.Enabled {
+		return fmt.Errorf("otel and statsd can't both be enabled")
+	}
+	if c.StatsD.MaxPacketSize < 0 {
+		return fmt.Errorf("statsd.max_packet_size must not be negative")
+	}
+
+	pools := make(map[string]bool, len(c.Pools))
+	stateFiles := make(map[string]string, len(c.Pools))
+	for _, p := range c.Pools {
+		if p.Name == "" {
+			return fmt.Errorf("pool name must not be empty")
+		}
+		if pools[p.Name] {
+			return fmt.Errorf("duplicate pool %q", p.Name)
+		}
+		pools[p.Name] = true
+
+		if p.StateFile != "" {
+			if other, ok := stateFiles[p.StateFile]; ok {
+				return fmt.Errorf("pools %q and %q share state_file %s", other, p.Name, p.StateFile)
+			}
+			stateFiles[p.StateFile] = p.Name
+		}
+
+		if p.ProxyProtocol.Version < 0 || p.ProxyProtocol.Version > 2 {
+			return fmt.Errorf("pool %q has unsupported proxy_protocol.version %d", p.Name, p.ProxyProtocol.Version)
+		}
+
+		if (p.TLS.CertFile == "") != (p.TLS.KeyFile == "") {
+			return fmt.Errorf("pool %q must set both tls.cert_file and tls.key_file", p.Name)
+		}
+
+		for _, b := range p.Backends {
+			if b.MaxConnections < 0 {
+				return fmt.Errorf("pool %q has a backend with negative max_connections", p.Name)
+			}
+			if b.Socket != "" && (b.Address != "" || b.Port != 0) {
+				return fmt.Errorf("pool %q backend %s sets both socket and address", p.Name, b.Socket)
+			}
+			if b.Socket != "" && p.TLS.Enabled && p.TLS.ServerName == "" && !p.TLS.InsecureSkipVerify {
+				return fmt.Errorf("pool %q needs tls.server_name to verify socket backend %s", p.Name, b.Socket)
+			}
+		}
+	}
+
+	frontends := make(map[string]bool, len(c.Frontends))
+	listens := make(map[string]string, len(c.Frontends))
+	for _, fe := range c.Frontends {
+		if fe.Name == "" {
+			return fmt.Errorf("frontend name must not be empty")
+		}
+		if frontends[fe.Name] {
+			return fmt.Errorf("duplicate frontend %q", fe.Name)
+		}
+		frontends[fe.Name] = true
+
+		switch fe.Protocol {
+		case "", ProtocolTCP:
+		case ProtocolUDP:
+			if fe.TLS.Enabled || len(fe.SNI.Routes) > 0 || fe.AcceptProxy.Enabled || fe.Unix.Path != "" || fe.RateLimit.Enabled() || fe.ACL.Enabled() {
+				return fmt.Errorf("frontend %q: tls, sni, accept_proxy, unix, rate_limit and acl are not supported for udp", fe.Name)
+			}
+			if p := c.Pool(fe.Pool); p != nil && p.hasSocketBackends() {
+				return fmt.Errorf("frontend %q: udp cannot use socket backends of pool %q", fe.Name, fe.Pool)
+			}
+		default:
+			return fmt.Errorf("frontend %q has unknown protocol %q", fe.Name, fe.Protocol)
+		}
+
+		if fe.Acceptors < 0 || fe.Backlog < 0 || fe.MaxConnections < 0 || fe.Queue.Size < 0 {
+			return fmt.Errorf("frontend %q: acceptors, backlog, max_connections and queue.size must not be negative", fe.Name)
+		}
+		if fe.Acceptors > 1 && (fe.Protocol == ProtocolUDP || fe.Unix.Path != "") {
+			return fmt.Errorf("frontend %q: multiple acceptors need a tcp host:port listener", fe.Name)
+		}
+
+		if _, err := fe.Unix.FileMode(); err != nil {
+			return fmt.Errorf("frontend %q: %w", fe.Name, err)
+		}
+		if err := fe.RateLimit.validate(); err != nil {
+			return fmt.Errorf("frontend %q: %w", fe.Name, err)
+		}
+		if err := fe.ACL.validate(); err != nil {
+			return fmt.Errorf("frontend %q: %w", fe.Name, err)
+		}
+
+		if fe.TLS.Enabled && (fe.TLS.CertFile == "" || fe.TLS.KeyFile == "") {
+			return fmt.Errorf("frontend %q enables TLS without cert_file and key_file", fe.Name)
+		}
+
+		if fe.AcceptProxy.Enabled && len(fe.AcceptProxy.TrustedCIDRs) == 0 {
+			return fmt.Errorf("frontend %q accepts the PROXY protocol without trusted_cidrs", fe.Name)
+		}
+		for _, cidr := range fe.AcceptProxy.TrustedCIDRs {
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				return fmt.Errorf("frontend %q has invalid trusted CIDR %q: %w", fe.Name, cidr, err)
+			}
+		}
+
+		if fe.Pool != "" && !pools[fe.Pool] {
+			return fmt.Errorf("frontend %q references unknown pool %q", fe.Name, fe.Pool)
+		}
+
+		for _, route := range fe.SNI.Routes {
+			if len(route.Hosts) == 0 {
+				return fmt.Errorf("frontend %q has an SNI route without hosts", fe.Name)
+			}
+			if !pools[route.Pool] {
+				return fmt.Errorf("frontend %q routes %v to unknown pool %q", fe.Name, route.Hosts, route.Pool)
+			}
+		}
+
+		addr := fe.ListenAddress()
+		if other, ok := listens[addr]; ok {
+			return fmt.Errorf("frontends %q and %q both listen on %s", other, fe.Name, addr)
+		}
+		listens[addr] = fe.Name
+	}
+
+	return nil
+}
+
+func (c *Config) Pool(name string) *PoolConfig {
+	for i := range c.Pools {
+		if c.Pools[i].Name == name {
+			return &c.Pools[i]
+		}
+	}
+	return nil
+}
+
+// ListenAddress identifies the socket the frontend listens on, such as
+// "tcp/0.0.0.0:8080" or "unix//run/lb.sock".
+func (fe *FrontendConfig) ListenAddress() string {
+	if fe.Unix.Path != "" {
+		return "unix/" + fe.Unix.Path
+	}
+	return fmt.Sprintf("%s/%s:%d", fe.Protocol, fe.Host, fe.Port)
+}
+
+func (p *PoolConfig) hasSocketBackends() bool {
+	for _, b := range p.Backends {
+		if b.Socket != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// PoolNames returns every pool the frontend can send traffic to.
+func (fe *FrontendConfig) PoolNames() []string {
+	names := make([]string, 0, len(fe.SNI.Routes)+1)
+	seen := make(map[string]bool)
+	if fe.Pool != "" {
+		names = append(names, fe.Pool)
+		seen[fe.Pool] = true
+	}
+	for _, route := range fe.SNI.Routes {
+		if !seen[route.Pool] {
+			names = append(names, route.Pool)
+			seen[route.Pool] = true
+		}
+	}
+	return names
+}
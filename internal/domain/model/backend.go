@@ -1,27 +1,65 @@
 package model
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 )
 
+const (
+	NetworkTCP  = "tcp"
+	NetworkUnix = "unix"
+)
+
+// Admin states an operator can put a backend in. Only enabled backends get
+// new connections. Draining backends keep the ones they have and are still
+// health checked; disabled backends are not checked at all.
+const (
+	AdminEnabled  = "enabled"
+	AdminDrain    = "drain"
+	AdminDisabled = "disabled"
+)
+
+// ErrBackendNotFound is returned by repositories for IDs they don't hold.
+var ErrBackendNotFound = errors.New("backend not found")
+
+func ValidAdminState(state string) bool {
+	switch state {
+	case AdminEnabled, AdminDrain, AdminDisabled:
+		return true
+	}
+	return false
+}
+
+// Backend is a server traffic can be sent to. Its health and admin state are
+// kept by the repository holding it; see port.BackendRepository.
 type Backend struct {
 	ID                string
+	Network           string
 	Address           string
 	Port              int
 	Weight            int
-	IsHealthy         bool
+	MaxConnections    int
 	ActiveConnections int
 	mu                sync.RWMutex
 }
 
 func NewBackend(id, address string, port, weight int) *Backend {
 	return &Backend{
-		ID:        id,
-		Address:   address,
-		Port:      port,
-		Weight:    weight,
-		IsHealthy: true,
+		ID:      id,
+		Address: address,
+		Port:    port,
+		Weight:  weight,
+	}
+}
+
+// NewUnixBackend creates a backend reached through the Unix socket at path.
+func NewUnixBackend(id, path string, weight int) *Backend {
+	return &Backend{
+		ID:      id,
+		Network: NetworkUnix,
+		Address: path,
+		Weight:  weight,
 	}
 }
 
@@ -31,7 +69,21 @@ func (b *Backend) GetID() string {
 	return b.ID
 }
 
+// GetNetwork returns the network to dial the backend on, as accepted by
+// net.Dial.
+func (b *Backend) GetNetwork() string {
+	if b.Network == "" {
+		return NetworkTCP
+	}
+	return b.Network
+}
+
+// GetAddress returns host:port for TCP backends and the socket path for Unix
+// backends.
 func (b *Backend) GetAddress() string {
+	if b.Network == NetworkUnix {
+		return b.Address
+	}
 	return fmt.Sprintf("%s:%d", b.Address, b.Port)
 }
 
@@ -49,20 +101,26 @@ func (b *Backend) DecreaseConnections() {
 	}
 }
 
-func (b *Backend) GetActiveConnections() int {
-	b.mu.RLock()
-	defer b.mu.RUnlock()
-	return b.ActiveConnections
-}
-
-func (b *Backend) SetHealthy(healthy bool) {
+// TryAcquire counts a new connection unless the backend already has
+// MaxConnections active ones. Zero MaxConnections means no limit.
+func (b *Backend) TryAcquire() bool {
 	b.mu.Lock()
 	defer b.mu.Unlock()
-	b.IsHealthy = healthy
+	if b.MaxConnections > 0 && b.ActiveConnections >= b.MaxConnections {
+		return false
+	}
+	b.ActiveConnections++
+	return true
 }
 
-func (b *Backend) GetHealthy() bool {
+func (b *Backend) HasCapacity() bool {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
-	return b.IsHealthy
+	return b.MaxConnections <= 0 || b.ActiveConnections < b.MaxConnections
+}
+
+func (b *Backend) GetActiveConnections() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.ActiveConnections
 }
@@ -0,0 +1,21 @@
+package model
+
+// Kinds of change reported by a backend repository watch.
+const (
+	BackendAdded         = "added"
+	BackendRemoved       = "removed"
+	BackendUpdated       = "updated"
+	BackendHealthChanged = "health_changed"
+	BackendAdminChanged  = "admin_changed"
+)
+
+// BackendChange describes one change to a repository. Version increases by
+// one with every change, so consumers can tell the order of changes and
+// whether a snapshot is current.
+type BackendChange struct {
+	Type       string
+	Version    uint64
+	Backend    *Backend
+	Healthy    bool
+	AdminState string
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// Event types published on the event stream.
+const (
+	EventBackendUp           = "backend_up"
+	EventBackendDown         = "backend_down"
+	EventBackendAdminChanged = "backend_admin_changed"
+	EventBackendEjected      = "backend_ejected"
+	EventSessionKilled       = "session_killed"
+	EventConfigReloaded      = "config_reloaded"
+	EventConfigReloadFailed  = "config_reload_failed"
+	EventConnectionRejected  = "connection_rejected"
+)
+
+// Event is a state change operators may want to react to. ID and Time are
+// assigned when it is published; fields that don't apply are left empty.
+type Event struct {
+	ID       uint64
+	Time     time.Time
+	Type     string
+	Frontend string
+	Pool     string
+	Backend  string
+	Client   string
+	Reason   string
+	Message  string
+}
+
+// EventFilter selects events. Empty fields match everything.
+type EventFilter struct {
+	Types    []string
+	Frontend string
+	Pool     string
+	Backend  string
+}
+
+func (f EventFilter) Matches(e Event) bool {
+	if len(f.Types) > 0 && !slices.Contains(f.Types, e.Type) {
+		return false
+	}
+	if f.Frontend != "" && f.Frontend != e.Frontend {
+		return false
+	}
+	if f.Pool != "" && f.Pool != e.Pool {
+		return false
+	}
+	if f.Backend != "" && f.Backend != e.Backend {
+		return false
+	}
+	return true
+}
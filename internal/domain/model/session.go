@@ -0,0 +1,60 @@
+package model
+
+import "time"
+
+// Close reasons recorded for a session.
+const (
+	CloseClientClosed      = "client_closed"
+	CloseBackendClosed     = "backend_closed"
+	CloseClientError       = "client_error"
+	CloseBackendError      = "backend_error"
+	CloseNoHealthyBackends = "no_healthy_backends"
+	CloseBackendsFull      = "backends_full"
+	CloseQueueFull         = "queue_full"
+	CloseQueueTimeout      = "queue_timeout"
+	CloseDialFailed        = "backend_unavailable"
+	CloseIdleTimeout       = "idle_timeout"
+	CloseKilled            = "killed"
+	CloseCanceled          = "canceled"
+	CloseError             = "error"
+)
+
+// SessionRecord summarizes a client connection once it has ended. Backend
+// is empty if the connection was never routed. BytesSent counts bytes from
+// the client to the backend and BytesReceived the other way.
+type SessionRecord struct {
+	Client        string
+	Frontend      string
+	Backend       string
+	Start         time.Time
+	Duration      time.Duration
+	BytesSent     int64
+	BytesReceived int64
+	CloseReason   string
+	Retries       int
+
+	TLSVersion string
+	ServerName string
+	ALPN       string
+}
+
+// SessionInfo describes an active session.
+type SessionInfo struct {
+	ID            uint64
+	Frontend      string
+	Client        string
+	Backend       string
+	Start         time.Time
+	BytesSent     int64
+	BytesReceived int64
+	Idle          time.Duration
+}
+
+// SessionFilter selects active sessions. Empty fields match everything;
+// Client matches either the full address or just its IP.
+type SessionFilter struct {
+	Frontend string
+	Backend  string
+	Client   string
+	MinIdle  time.Duration
+}
@@ -0,0 +1,56 @@
+package model
+
+import "time"
+
+// Status is a point-in-time view of the load balancer for the status page.
+type Status struct {
+	Version   string
+	Started   time.Time
+	Frontends []FrontendStatus
+}
+
+// FrontendStatus holds a frontend's totals. Errors counts failures not tied
+// to a backend, e.g. no_healthy_backends, and Rejected counts connections
+// refused by access lists or rate limits.
+type FrontendStatus struct {
+	Name           string
+	Protocol       string
+	Listen         string
+	Algorithm      string
+	ActiveSessions int64
+	TotalSessions  int64
+	QueueDepth     int64
+	Errors         map[string]int64
+	Rejected       map[string]int64
+	Pools          []PoolStatus
+}
+
+type PoolStatus struct {
+	Name     string
+	Backends []BackendStatus
+}
+
+// BackendStatus is a backend as seen by one frontend. Healthy reports its
+// health alone, whatever its AdminState. LastChange is zero if its health
+// has not changed since start. HealthChecks lists the most recent checks,
+// oldest first.
+type BackendStatus struct {
+	ID             string
+	Address        string
+	Healthy        bool
+	AdminState     string
+	Weight         int
+	MaxConnections int
+	ActiveSessions int64
+	TotalSessions  int64
+	BytesSent      int64
+	BytesReceived  int64
+	Errors         map[string]int64
+	LastChange     time.Time
+	HealthChecks   []HealthCheck
+}
+
+type HealthCheck struct {
+	Time    time.Time
+	Healthy bool
+}
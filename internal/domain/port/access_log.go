@@ -0,0 +1,7 @@
+package port
+
+import "github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+
+type AccessLogger interface {
+	LogSession(record *model.SessionRecord)
+}
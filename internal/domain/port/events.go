@@ -0,0 +1,16 @@
+package port
+
+import "github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+
+type EventPublisher interface {
+	Publish(event model.Event)
+}
+
+// EventStream delivers published events to subscribers.
+type EventStream interface {
+	// Subscribe returns the matching events published after the one with ID
+	// after, as far as they are still retained, followed by new ones. The
+	// channel is closed if the subscriber falls behind or the stream shuts
+	// down. cancel must be called once the subscriber is done.
+	Subscribe(filter model.EventFilter, after uint64) (events <-chan model.Event, cancel func())
+}
@@ -3,14 +3,54 @@ package port
 import (
 	"context"
 	"net"
+	"time"
 )
 
+type acceptTimeKey struct{}
+
+// WithAcceptTime records on ctx when the connection being handled was
+// accepted, before any PROXY header, TLS handshake or SNI routing.
+func WithAcceptTime(ctx context.Context, accepted time.Time) context.Context {
+	return context.WithValue(ctx, acceptTimeKey{}, accepted)
+}
+
+// AcceptTime returns the time recorded by WithAcceptTime, if any.
+func AcceptTime(ctx context.Context) (time.Time, bool) {
+	accepted, ok := ctx.Value(acceptTimeKey{}).(time.Time)
+	return accepted, ok
+}
+
 type ConnectionHandler interface {
 	Handle(ctx context.Context, conn net.Conn) error
 }
 
+// ConnectionLimiter decides whether a new client connection may be served.
+// If it may, release must be called once the connection ends; otherwise
+// reason names the limit that was hit.
+type ConnectionLimiter interface {
+	Allow(client net.Addr) (release func(), reason string)
+}
+
+// AccessControl decides whether a client may connect at all, describing the
+// rule that matched.
+type AccessControl interface {
+	Check(client net.Addr) (allowed bool, rule string)
+}
+
 type TCPListener interface {
 	Listen(ctx context.Context, handler ConnectionHandler) error
 
 	Close() error
 }
+
+// DatagramHandler processes a datagram received from client. Replies are
+// sent back to the client through conn.
+type DatagramHandler interface {
+	HandleDatagram(ctx context.Context, conn net.PacketConn, client net.Addr, payload []byte) error
+}
+
+type UDPListener interface {
+	Listen(ctx context.Context, handler DatagramHandler) error
+
+	Close() error
+}
@@ -11,7 +11,29 @@ type MetricsCollector interface {
 
 	ObserveConnectionDuration(backend string, duration float64)
 
+	// AddBytesSent counts bytes forwarded from clients to backend.
+	AddBytesSent(backend string, n int)
+
+	// AddBytesReceived counts bytes forwarded from backend to clients.
+	AddBytesReceived(backend string, n int)
+
+	ObserveDialLatency(backend string, seconds float64)
+
+	ObserveTimeToFirstByte(backend string, seconds float64)
+
 	SetBackendHealthStatus(backend string, healthy bool)
 
 	IncHealthChecksTotal(backend string, status string)
+
+	IncTLSHandshakeErrors(reason string)
+
+	ObserveDatagram(backend string, direction string, size int)
+
+	IncQueueDepth()
+
+	DecQueueDepth()
+
+	ObserveQueueWait(seconds float64)
+
+	IncRejectedConnections(reason string)
 }
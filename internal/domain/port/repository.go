@@ -16,4 +16,25 @@ type BackendRepository interface {
 	Remove(ctx context.Context, id string) error
 
 	Update(ctx context.Context, backend *model.Backend) error
+
+	// SetHealthy records the outcome of a health check, reporting a
+	// health change to watchers if the state flipped.
+	SetHealthy(ctx context.Context, id string, healthy bool) error
+
+	// Watch delivers an add for every current backend followed by every
+	// later change, until ctx is done and the channel is closed.
+	Watch(ctx context.Context) <-chan model.BackendChange
+}
+
+// BackendAdmin lets operators take backends out of rotation. Repositories
+// that implement it leave backends that aren't model.AdminEnabled out of
+// GetHealthy.
+type BackendAdmin interface {
+	// SetAdminState puts a backend in one of the model.Admin* states,
+	// reporting an admin change to watchers if the state changed.
+	SetAdminState(ctx context.Context, id string, state string) error
+
+	// AdminState returns the admin state of a backend, model.AdminEnabled
+	// for backends it doesn't hold.
+	AdminState(ctx context.Context, id string) string
 }
@@ -0,0 +1,14 @@
+package port
+
+import "github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+
+// SessionManager lists and terminates active sessions.
+type SessionManager interface {
+	Sessions(filter model.SessionFilter) []model.SessionInfo
+
+	// Kill closes the session with the given ID, reporting whether it existed.
+	Kill(id uint64) bool
+
+	// KillBackend closes every session to backend and returns how many.
+	KillBackend(backend string) int
+}
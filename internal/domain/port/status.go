@@ -0,0 +1,11 @@
+package port
+
+import (
+	"context"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+)
+
+type StatusProvider interface {
+	Status(ctx context.Context) model.Status
+}
@@ -0,0 +1,33 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+)
+
+// Phases of a traced session, in order.
+const (
+	// PhaseHandshake covers the listener's work between accept and the
+	// handler: PROXY header, access control, TLS handshake and SNI routing.
+	PhaseHandshake = "handshake"
+	PhaseSelect    = "select"
+	PhaseDial      = "dial"
+	PhaseProxy     = "proxy"
+)
+
+// SessionTracer traces a TCP session from the moment it is accepted until
+// it closes. accepted is when the connection was accepted, which may be
+// well before the handler started.
+type SessionTracer interface {
+	StartSession(ctx context.Context, client string, accepted time.Time) SessionSpan
+}
+
+type SessionSpan interface {
+	// Phase starts a child span for one phase; the returned func ends it.
+	Phase(name string) func(err error)
+
+	// End closes the session span with the final record.
+	End(record *model.SessionRecord)
+}
@@ -0,0 +1,76 @@
+package filewatch
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+	exists  bool
+}
+
+// Watcher detects changes to a set of files by polling their modification
+// time and size. Polling is used instead of inotify so that atomic symlink
+// swaps (as done by Kubernetes secret mounts) are picked up too.
+type Watcher struct {
+	paths  []string
+	states []fileState
+}
+
+// New snapshots the current state of paths. Changes made after New returns
+// are reported by Run, even if Run is started later.
+func New(paths ...string) *Watcher {
+	w := &Watcher{
+		paths:  paths,
+		states: make([]fileState, len(paths)),
+	}
+	for i, path := range paths {
+		w.states[i] = stat(path)
+	}
+	return w
+}
+
+// Run calls onChange whenever any watched file changes. It blocks until ctx
+// is cancelled.
+func (w *Watcher) Run(ctx context.Context, interval time.Duration, onChange func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if w.changed() {
+				onChange()
+			}
+		}
+	}
+}
+
+func (w *Watcher) changed() bool {
+	changed := false
+	for i, path := range w.paths {
+		current := stat(path)
+		if current != w.states[i] {
+			w.states[i] = current
+			changed = true
+		}
+	}
+	return changed
+}
+
+func stat(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{
+		modTime: info.ModTime(),
+		size:    info.Size(),
+		exists:  true,
+	}
+}
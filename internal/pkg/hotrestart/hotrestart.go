@@ -0,0 +1,29 @@
+// Package hotrestart hands listening sockets from a running process to a
+// freshly started copy of the binary, so an upgrade never refuses a
+// connection. The parent execs the child with one end of a Unix socket pair,
+// sends the sockets over it with SCM_RIGHTS and waits for the child to report
+// that it is serving before it stops accepting.
+package hotrestart
+
+import (
+	"errors"
+	"os"
+)
+
+// envFD names the descriptor of the socket pair in the child.
+const envFD = "LB_HOT_RESTART_FD"
+
+const readyMessage = "READY"
+
+// maxFiles is the kernel limit on descriptors in one message (SCM_MAX_FD).
+const maxFiles = 253
+
+var ErrUnsupported = errors.New("hot restart is only supported on linux")
+
+// Files maps a name, such as a frontend name, to its sockets.
+type Files map[string][]*os.File
+
+type socketGroup struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
@@ -0,0 +1,213 @@
+//go:build linux
+
+package hotrestart
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// Start execs the current binary with the same arguments and environment,
+// hands it files and waits up to timeout for the child to call Ready. On
+// failure the child is killed and the caller keeps serving. It returns the
+// child's PID.
+func Start(files Files, timeout time.Duration) (int, error) {
+	var groups []socketGroup
+	var fds []int
+	for name, group := range files {
+		groups = append(groups, socketGroup{Name: name, Count: len(group)})
+		for _, file := range group {
+			fd, err := rawFD(file)
+			if err != nil {
+				return 0, err
+			}
+			fds = append(fds, fd)
+		}
+	}
+	if len(fds) > maxFiles {
+		return 0, fmt.Errorf("cannot hand over %d sockets, the limit is %d", len(fds), maxFiles)
+	}
+
+	pair, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_SEQPACKET|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create socket pair: %w", err)
+	}
+	parentFile := os.NewFile(uintptr(pair[0]), "hotrestart-parent")
+	childFile := os.NewFile(uintptr(pair[1]), "hotrestart-child")
+	defer childFile.Close()
+
+	conn, err := fileConn(parentFile)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	executable, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Env = append(os.Environ(), envFD+"=3")
+	cmd.ExtraFiles = []*os.File{childFile}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start %s: %w", executable, err)
+	}
+	childFile.Close()
+
+	fail := func(err error) (int, error) {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return 0, err
+	}
+
+	data, err := json.Marshal(groups)
+	if err != nil {
+		return fail(err)
+	}
+	var rights []byte
+	if len(fds) > 0 {
+		rights = unix.UnixRights(fds...)
+	}
+	if _, _, err := conn.WriteMsgUnix(data, rights, nil); err != nil {
+		return fail(fmt.Errorf("failed to send sockets: %w", err))
+	}
+
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	buf := make([]byte, len(readyMessage))
+	n, err := conn.Read(buf)
+	if err != nil {
+		return fail(fmt.Errorf("child did not become ready: %w", err))
+	}
+	if string(buf[:n]) != readyMessage {
+		return fail(fmt.Errorf("unexpected message from child: %q", buf[:n]))
+	}
+
+	go cmd.Wait()
+	return cmd.Process.Pid, nil
+}
+
+// Child is the receiving end of a hot restart.
+type Child struct {
+	conn  *net.UnixConn
+	files Files
+}
+
+// Inherit receives the sockets handed over by the parent. It returns nil
+// when the process was not started by Start.
+func Inherit() (*Child, error) {
+	value := os.Getenv(envFD)
+	if value == "" {
+		return nil, nil
+	}
+	os.Unsetenv(envFD)
+
+	fd, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q", envFD, value)
+	}
+	conn, err := fileConn(os.NewFile(uintptr(fd), "hotrestart"))
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, 64*1024)
+	oob := make([]byte, unix.CmsgSpace(maxFiles*4))
+	n, oobn, _, _, err := conn.ReadMsgUnix(data, oob)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to receive sockets: %w", err)
+	}
+
+	var groups []socketGroup
+	if err := json.Unmarshal(data[:n], &groups); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("invalid socket list: %w", err)
+	}
+
+	fds, err := parseRights(oob[:oobn])
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	files := make(Files, len(groups))
+	for _, group := range groups {
+		if group.Count > len(fds) {
+			conn.Close()
+			return nil, fmt.Errorf("parent sent fewer sockets than listed")
+		}
+		for _, fd := range fds[:group.Count] {
+			files[group.Name] = append(files[group.Name], os.NewFile(uintptr(fd), group.Name))
+		}
+		fds = fds[group.Count:]
+	}
+
+	return &Child{conn: conn, files: files}, nil
+}
+
+// Files returns the inherited sockets. The caller owns them.
+func (c *Child) Files() Files {
+	return c.files
+}
+
+// Ready tells the parent that the child is serving, after which the parent
+// stops accepting and drains its connections.
+func (c *Child) Ready() error {
+	defer c.conn.Close()
+	_, err := c.conn.Write([]byte(readyMessage))
+	return err
+}
+
+// rawFD returns the descriptor of file without File.Fd, which would switch
+// the socket, shared with the running listener, to blocking mode.
+func rawFD(file *os.File) (int, error) {
+	rc, err := file.SyscallConn()
+	if err != nil {
+		return 0, err
+	}
+	var fd int
+	err = rc.Control(func(f uintptr) {
+		fd = int(f)
+	})
+	return fd, err
+}
+
+func fileConn(file *os.File) (*net.UnixConn, error) {
+	conn, err := net.FileConn(file)
+	file.Close()
+	if err != nil {
+		return nil, err
+	}
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		conn.Close()
+		return nil, fmt.Errorf("%s is not a unix socket", file.Name())
+	}
+	return unixConn, nil
+}
+
+func parseRights(oob []byte) ([]int, error) {
+	messages, err := unix.ParseSocketControlMessage(oob)
+	if err != nil {
+		return nil, fmt.Errorf("invalid control message: %w", err)
+	}
+	var fds []int
+	for i := range messages {
+		rights, err := unix.ParseUnixRights(&messages[i])
+		if err != nil {
+			return nil, err
+		}
+		fds = append(fds, rights...)
+	}
+	return fds, nil
+}
@@ -0,0 +1,23 @@
+//go:build !linux
+
+package hotrestart
+
+import "time"
+
+func Start(files Files, timeout time.Duration) (int, error) {
+	return 0, ErrUnsupported
+}
+
+type Child struct{}
+
+func Inherit() (*Child, error) {
+	return nil, nil
+}
+
+func (c *Child) Files() Files {
+	return nil
+}
+
+func (c *Child) Ready() error {
+	return ErrUnsupported
+}
@@ -0,0 +1,44 @@
+package proxyproto
+
+import (
+	"bufio"
+	"net"
+)
+
+// Conn is a connection whose PROXY header has been consumed. RemoteAddr and
+// LocalAddr report the addresses from the header when it carried any.
+type Conn struct {
+	net.Conn
+	reader *bufio.Reader
+	header *Header
+}
+
+func NewConn(conn net.Conn, reader *bufio.Reader, header *Header) *Conn {
+	return &Conn{
+		Conn:   conn,
+		reader: reader,
+		header: header,
+	}
+}
+
+func (c *Conn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+func (c *Conn) Header() *Header {
+	return c.header
+}
+
+func (c *Conn) RemoteAddr() net.Addr {
+	if c.header.Command == CommandProxy && c.header.Source != nil {
+		return c.header.Source
+	}
+	return c.Conn.RemoteAddr()
+}
+
+func (c *Conn) LocalAddr() net.Addr {
+	if c.header.Command == CommandProxy && c.header.Destination != nil {
+		return c.header.Destination
+	}
+	return c.Conn.LocalAddr()
+}
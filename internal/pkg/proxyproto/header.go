@@ -0,0 +1,168 @@
+package proxyproto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+)
+
+type Command byte
+
+const (
+	CommandLocal Command = 0x0
+	CommandProxy Command = 0x1
+)
+
+// TLV types defined by the PROXY protocol v2 specification.
+const (
+	TLVTypeALPN      byte = 0x01
+	TLVTypeAuthority byte = 0x02
+	TLVTypeCRC32C    byte = 0x03
+	TLVTypeNoop      byte = 0x04
+	TLVTypeUniqueID  byte = 0x05
+	TLVTypeSSL       byte = 0x20
+	TLVTypeNetNS     byte = 0x30
+)
+
+const (
+	familyUnspec = 0x00
+	familyTCP4   = 0x11
+	familyTCP6   = 0x21
+	familyUnix   = 0x31
+
+	// The high nibble of the family byte is the address family, the low
+	// nibble the transport.
+	addrFamilyInet  = 0x1
+	addrFamilyInet6 = 0x2
+	addrFamilyUnix  = 0x3
+	transportDgram  = 0x2
+
+	v2HeaderLen   = 16
+	v1MaxLen      = 107
+	unixAddrLen   = 108
+	maxV2Payload  = 0xFFFF
+	versionV2Mask = 0x20
+)
+
+var v2Signature = []byte{0x0D, 0x0A, 0x0D, 0x0A, 0x00, 0x0D, 0x0A, 0x51, 0x55, 0x49, 0x54, 0x0A}
+
+type TLV struct {
+	Type  byte
+	Value []byte
+}
+
+type Header struct {
+	Version     int
+	Command     Command
+	Source      net.Addr
+	Destination net.Addr
+	TLVs        []TLV
+}
+
+// TLV returns the value of the first TLV of the given type.
+func (h *Header) TLV(t byte) ([]byte, bool) {
+	for _, tlv := range h.TLVs {
+		if tlv.Type == t {
+			return tlv.Value, true
+		}
+	}
+	return nil, false
+}
+
+func (h *Header) WriteTo(w io.Writer) (int64, error) {
+	data, err := h.Format()
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+func (h *Header) Format() ([]byte, error) {
+	switch h.Version {
+	case 1:
+		return h.formatV1(), nil
+	case 2:
+		return h.formatV2()
+	default:
+		return nil, fmt.Errorf("unsupported PROXY protocol version %d", h.Version)
+	}
+}
+
+func (h *Header) formatV1() []byte {
+	src, srcOK := h.Source.(*net.TCPAddr)
+	dst, dstOK := h.Destination.(*net.TCPAddr)
+	if h.Command == CommandLocal || !srcOK || !dstOK {
+		return []byte("PROXY UNKNOWN\r\n")
+	}
+
+	proto := "TCP4"
+	srcIP, dstIP := src.IP.To4(), dst.IP.To4()
+	if srcIP == nil || dstIP == nil {
+		proto = "TCP6"
+		srcIP, dstIP = src.IP.To16(), dst.IP.To16()
+	}
+
+	return []byte(fmt.Sprintf("PROXY %s %s %s %d %d\r\n", proto, srcIP, dstIP, src.Port, dst.Port))
+}
+
+func (h *Header) formatV2() ([]byte, error) {
+	var addrs bytes.Buffer
+	family := byte(familyUnspec)
+
+	if h.Command == CommandProxy {
+		switch src := h.Source.(type) {
+		case *net.TCPAddr:
+			dst, ok := h.Destination.(*net.TCPAddr)
+			if !ok {
+				break
+			}
+			if src.IP.To4() != nil && dst.IP.To4() != nil {
+				family = familyTCP4
+				addrs.Write(src.IP.To4())
+				addrs.Write(dst.IP.To4())
+			} else {
+				family = familyTCP6
+				addrs.Write(src.IP.To16())
+				addrs.Write(dst.IP.To16())
+			}
+			binary.Write(&addrs, binary.BigEndian, uint16(src.Port))
+			binary.Write(&addrs, binary.BigEndian, uint16(dst.Port))
+		case *net.UnixAddr:
+			dst, ok := h.Destination.(*net.UnixAddr)
+			if !ok {
+				break
+			}
+			family = familyUnix
+			addrs.Write(unixPath(src.Name))
+			addrs.Write(unixPath(dst.Name))
+		}
+	}
+
+	for _, tlv := range h.TLVs {
+		if len(tlv.Value) > maxV2Payload {
+			return nil, fmt.Errorf("TLV 0x%02x too large", tlv.Type)
+		}
+		addrs.WriteByte(tlv.Type)
+		binary.Write(&addrs, binary.BigEndian, uint16(len(tlv.Value)))
+		addrs.Write(tlv.Value)
+	}
+
+	if addrs.Len() > maxV2Payload {
+		return nil, fmt.Errorf("PROXY v2 header too large")
+	}
+
+	out := make([]byte, 0, v2HeaderLen+addrs.Len())
+	out = append(out, v2Signature...)
+	out = append(out, versionV2Mask|byte(h.Command), family)
+	out = binary.BigEndian.AppendUint16(out, uint16(addrs.Len()))
+	return append(out, addrs.Bytes()...), nil
+}
+
+func unixPath(name string) []byte {
+	path := make([]byte, unixAddrLen)
+	copy(path, name)
+	return path
+}
@@ -0,0 +1,168 @@
+package proxyproto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrNoHeader      = errors.New("no PROXY protocol header")
+	ErrInvalidHeader = errors.New("invalid PROXY protocol header")
+)
+
+var v1Prefix = []byte("PROXY ")
+
+// Read parses a v1 or v2 header from r. Bytes following the header are left
+// in r.
+func Read(r *bufio.Reader) (*Header, error) {
+	start, err := r.Peek(len(v1Prefix))
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.Equal(start, v1Prefix) {
+		return readV1(r)
+	}
+	if bytes.Equal(start, v2Signature[:len(v1Prefix)]) {
+		return readV2(r)
+	}
+	return nil, ErrNoHeader
+}
+
+func readV1(r *bufio.Reader) (*Header, error) {
+	var line []byte
+	for len(line) < v1MaxLen {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+	}
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, fmt.Errorf("%w: v1 header not terminated by CRLF", ErrInvalidHeader)
+	}
+
+	fields := strings.Split(string(line[:len(line)-2]), " ")
+	header := &Header{Version: 1, Command: CommandProxy}
+
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		header.Command = CommandLocal
+		return header, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, fmt.Errorf("%w: malformed v1 header %q", ErrInvalidHeader, line)
+	}
+
+	src, err := parseV1Addr(fields[1], fields[2], fields[4])
+	if err != nil {
+		return nil, err
+	}
+	dst, err := parseV1Addr(fields[1], fields[3], fields[5])
+	if err != nil {
+		return nil, err
+	}
+
+	header.Source, header.Destination = src, dst
+	return header, nil
+}
+
+func parseV1Addr(proto, ip, port string) (*net.TCPAddr, error) {
+	addr := net.ParseIP(ip)
+	if addr == nil || (proto == "TCP4") != (addr.To4() != nil) {
+		return nil, fmt.Errorf("%w: bad %s address %q", ErrInvalidHeader, proto, ip)
+	}
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad port %q", ErrInvalidHeader, port)
+	}
+	return &net.TCPAddr{IP: addr, Port: int(p)}, nil
+}
+
+func readV2(r *bufio.Reader) (*Header, error) {
+	fixed := make([]byte, v2HeaderLen)
+	if _, err := io.ReadFull(r, fixed); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(fixed[:12], v2Signature) {
+		return nil, fmt.Errorf("%w: bad v2 signature", ErrInvalidHeader)
+	}
+	if fixed[12]&0xF0 != versionV2Mask {
+		return nil, fmt.Errorf("%w: unsupported version 0x%x", ErrInvalidHeader, fixed[12]>>4)
+	}
+
+	header := &Header{Version: 2, Command: Command(fixed[12] & 0x0F)}
+	if header.Command != CommandLocal && header.Command != CommandProxy {
+		return nil, fmt.Errorf("%w: unknown command 0x%x", ErrInvalidHeader, header.Command)
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint16(fixed[14:16]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+
+	var addrLen int
+	switch fixed[13] >> 4 {
+	case addrFamilyInet:
+		addrLen = 12
+	case addrFamilyInet6:
+		addrLen = 36
+	case addrFamilyUnix:
+		addrLen = 2 * unixAddrLen
+	}
+	if len(payload) < addrLen {
+		return nil, fmt.Errorf("%w: address block truncated", ErrInvalidHeader)
+	}
+
+	if header.Command == CommandProxy && addrLen > 0 {
+		header.Source, header.Destination = v2Addrs(fixed[13], payload[:addrLen])
+	}
+
+	tlvs := payload[addrLen:]
+	for len(tlvs) > 0 {
+		if len(tlvs) < 3 {
+			return nil, fmt.Errorf("%w: truncated TLV", ErrInvalidHeader)
+		}
+		length := int(binary.BigEndian.Uint16(tlvs[1:3]))
+		if len(tlvs) < 3+length {
+			return nil, fmt.Errorf("%w: truncated TLV", ErrInvalidHeader)
+		}
+		header.TLVs = append(header.TLVs, TLV{Type: tlvs[0], Value: tlvs[3 : 3+length]})
+		tlvs = tlvs[3+length:]
+	}
+
+	return header, nil
+}
+
+// v2Addrs decodes the address block of a header with the given family
+// byte, whose address family has already been checked.
+func v2Addrs(family byte, addrs []byte) (net.Addr, net.Addr) {
+	dgram := family&0x0F == transportDgram
+
+	if family>>4 == addrFamilyUnix {
+		network := "unix"
+		if dgram {
+			network = "unixgram"
+		}
+		return &net.UnixAddr{Net: network, Name: string(bytes.TrimRight(addrs[:unixAddrLen], "\x00"))},
+			&net.UnixAddr{Net: network, Name: string(bytes.TrimRight(addrs[unixAddrLen:], "\x00"))}
+	}
+
+	ipLen := (len(addrs) - 4) / 2
+	srcIP, dstIP := net.IP(addrs[:ipLen]), net.IP(addrs[ipLen:2*ipLen])
+	srcPort := int(binary.BigEndian.Uint16(addrs[2*ipLen:]))
+	dstPort := int(binary.BigEndian.Uint16(addrs[2*ipLen+2:]))
+	if dgram {
+		return &net.UDPAddr{IP: srcIP, Port: srcPort}, &net.UDPAddr{IP: dstIP, Port: dstPort}
+	}
+	return &net.TCPAddr{IP: srcIP, Port: srcPort}, &net.TCPAddr{IP: dstIP, Port: dstPort}
+}
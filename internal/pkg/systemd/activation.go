@@ -0,0 +1,51 @@
+package systemd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// listenFDsStart is the first file descriptor passed by systemd (SD_LISTEN_FDS_START).
+const listenFDsStart = 3
+
+// ListenFiles returns the sockets passed by systemd socket activation, named
+// after LISTEN_FDNAMES (FileDescriptorName= in the .socket unit). Sockets
+// without a name are called "unknown", as in sd_listen_fds_with_names(3).
+// It returns nil when the process was not socket-activated. With unsetEnv the
+// LISTEN_* variables are cleared so child processes don't inherit them.
+func ListenFiles(unsetEnv bool) ([]*os.File, error) {
+	if unsetEnv {
+		defer func() {
+			os.Unsetenv("LISTEN_PID")
+			os.Unsetenv("LISTEN_FDS")
+			os.Unsetenv("LISTEN_FDNAMES")
+		}()
+	}
+
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count < 0 {
+		return nil, fmt.Errorf("invalid LISTEN_FDS %q", os.Getenv("LISTEN_FDS"))
+	}
+
+	var names []string
+	if value := os.Getenv("LISTEN_FDNAMES"); value != "" {
+		names = strings.Split(value, ":")
+	}
+
+	files := make([]*os.File, 0, count)
+	for i := 0; i < count; i++ {
+		name := "unknown"
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		files = append(files, os.NewFile(uintptr(listenFDsStart+i), name))
+	}
+	return files, nil
+}
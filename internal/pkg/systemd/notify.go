@@ -0,0 +1,91 @@
+package systemd
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Notifier sends service state updates to systemd over NOTIFY_SOCKET, as
+// described in sd_notify(3). A nil Notifier ignores every call, so callers
+// don't need to check whether the service runs under systemd.
+type Notifier struct {
+	addr *net.UnixAddr
+}
+
+// NewNotifier returns a Notifier for NOTIFY_SOCKET, or nil when it is unset.
+func NewNotifier() *Notifier {
+	path := os.Getenv("NOTIFY_SOCKET")
+	if path == "" {
+		return nil
+	}
+	// A leading "@" denotes a socket in the abstract namespace.
+	if strings.HasPrefix(path, "@") {
+		path = "\x00" + path[1:]
+	}
+	return &Notifier{addr: &net.UnixAddr{Name: path, Net: "unixgram"}}
+}
+
+func (n *Notifier) Notify(state string) error {
+	if n == nil {
+		return nil
+	}
+	conn, err := net.DialUnix("unixgram", nil, n.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(state))
+	return err
+}
+
+func (n *Notifier) Ready() error {
+	return n.Notify("READY=1")
+}
+
+func (n *Notifier) Stopping() error {
+	return n.Notify("STOPPING=1")
+}
+
+// Status sets the free-form status line shown by systemctl status.
+func (n *Notifier) Status(status string) error {
+	return n.Notify("STATUS=" + status)
+}
+
+// WatchdogInterval returns the interval set by WatchdogSec= in the unit, or
+// zero when the watchdog is disabled or meant for another process.
+func WatchdogInterval() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond
+}
+
+// RunWatchdog sends WATCHDOG=1 at half the watchdog interval until ctx is
+// done. It returns immediately when the watchdog is disabled.
+func (n *Notifier) RunWatchdog(ctx context.Context) {
+	interval := WatchdogInterval()
+	if n == nil || interval == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n.Notify("WATCHDOG=1")
+		}
+	}
+}
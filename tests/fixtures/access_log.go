@@ -0,0 +1,43 @@
+package fixtures
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+)
+
+// AccessLogRecorder is a port.AccessLogger that keeps every record.
+type AccessLogRecorder struct {
+	mu      sync.Mutex
+	records []model.SessionRecord
+}
+
+func (r *AccessLogRecorder) LogSession(record *model.SessionRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, *record)
+}
+
+// Wait returns the first n records, failing the test if they don't arrive
+// within two seconds.
+func (r *AccessLogRecorder) Wait(t testing.TB, n int) []model.SessionRecord {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		r.mu.Lock()
+		if len(r.records) >= n {
+			records := append([]model.SessionRecord(nil), r.records[:n]...)
+			r.mu.Unlock()
+			return records
+		}
+		r.mu.Unlock()
+
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %d access log records", n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,43 @@
+package fixtures
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+)
+
+// EventRecorder is a port.EventPublisher that keeps every published event.
+type EventRecorder struct {
+	mu     sync.Mutex
+	events []model.Event
+}
+
+func (r *EventRecorder) Publish(event model.Event) {
+	r.mu.Lock()
+	r.events = append(r.events, event)
+	r.mu.Unlock()
+}
+
+// Wait returns the first n published events, failing the test if they don't
+// arrive within two seconds.
+func (r *EventRecorder) Wait(t testing.TB, n int) []model.Event {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		r.mu.Lock()
+		if len(r.events) >= n {
+			events := append([]model.Event(nil), r.events[:n]...)
+			r.mu.Unlock()
+			return events
+		}
+		r.mu.Unlock()
+
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %d events", n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,103 @@
+package fixtures
+
+import "sync"
+
+// MetricsRecorder is a port.MetricsCollector that counts calls by key so
+// tests can assert on what was reported.
+type MetricsRecorder struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func NewMetricsRecorder() *MetricsRecorder {
+	return &MetricsRecorder{counts: make(map[string]int)}
+}
+
+func (m *MetricsRecorder) Count(key string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counts[key]
+}
+
+func (m *MetricsRecorder) inc(key string) {
+	m.add(key, 1)
+}
+
+func (m *MetricsRecorder) add(key string, n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts[key] += n
+}
+
+func (m *MetricsRecorder) IncConnectionsTotal(backend string) {
+	m.inc("connections_total:" + backend)
+}
+
+func (m *MetricsRecorder) IncConnectionsActive(backend string) {
+	m.inc("connections_active:" + backend)
+}
+
+func (m *MetricsRecorder) DecConnectionsActive(backend string) {
+	m.inc("connections_active_dec:" + backend)
+}
+
+func (m *MetricsRecorder) IncConnectionErrors(backend string, errorType string) {
+	m.inc("connection_errors:" + backend + ":" + errorType)
+}
+
+func (m *MetricsRecorder) ObserveConnectionDuration(backend string, duration float64) {
+	m.inc("connection_duration:" + backend)
+}
+
+func (m *MetricsRecorder) SetBackendHealthStatus(backend string, healthy bool) {
+	if healthy {
+		m.inc("backend_healthy:" + backend)
+	} else {
+		m.inc("backend_unhealthy:" + backend)
+	}
+}
+
+func (m *MetricsRecorder) IncHealthChecksTotal(backend string, status string) {
+	m.inc("health_checks:" + backend + ":" + status)
+}
+
+func (m *MetricsRecorder) IncTLSHandshakeErrors(reason string) {
+	m.inc("tls_handshake_errors:" + reason)
+}
+
+func (m *MetricsRecorder) ObserveDatagram(backend string, direction string, size int) {
+	m.inc("datagrams:" + backend + ":" + direction)
+}
+
+func (m *MetricsRecorder) IncQueueDepth() {
+	m.inc("queue_depth_inc")
+}
+
+func (m *MetricsRecorder) DecQueueDepth() {
+	m.inc("queue_depth_dec")
+}
+
+func (m *MetricsRecorder) ObserveQueueWait(seconds float64) {
+	m.inc("queue_wait")
+}
+
+func (m *MetricsRecorder) IncRejectedConnections(reason string) {
+	m.inc("rejected:" + reason)
+}
+
+// AddBytesSent and AddBytesReceived sum bytes rather than counting calls.
+func (m *MetricsRecorder) AddBytesSent(backend string, n int) {
+	m.add("bytes_sent:"+backend, n)
+}
+
+func (m *MetricsRecorder) AddBytesReceived(backend string, n int) {
+	m.add("bytes_received:"+backend, n)
+}
+
+func (m *MetricsRecorder) ObserveDialLatency(backend string, seconds float64) {
+	m.inc("dial_latency:" + backend)
+}
+
+func (m *MetricsRecorder) ObserveTimeToFirstByte(backend string, seconds float64) {
+	m.inc("ttfb:" + backend)
+}
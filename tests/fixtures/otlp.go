@@ -0,0 +1,124 @@
+package fixtures
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	metricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	otlpmetric "go.opentelemetry.io/proto/otlp/metrics/v1"
+	otlptrace "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// OTLPCollector is a stand-in OTLP/HTTP receiver keeping every exported
+// metric and span.
+type OTLPCollector struct {
+	server *httptest.Server
+
+	mu      sync.Mutex
+	metrics []*otlpmetric.Metric
+	spans   []*otlptrace.Span
+}
+
+func NewOTLPCollector(t testing.TB) *OTLPCollector {
+	t.Helper()
+
+	c := &OTLPCollector{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/metrics", func(w http.ResponseWriter, r *http.Request) {
+		req := &metricspb.ExportMetricsServiceRequest{}
+		if !decodeOTLP(w, r, req) {
+			return
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for _, rm := range req.GetResourceMetrics() {
+			for _, sm := range rm.GetScopeMetrics() {
+				c.metrics = append(c.metrics, sm.GetMetrics()...)
+			}
+		}
+		writeOTLP(w, &metricspb.ExportMetricsServiceResponse{})
+	})
+	mux.HandleFunc("/v1/traces", func(w http.ResponseWriter, r *http.Request) {
+		req := &tracepb.ExportTraceServiceRequest{}
+		if !decodeOTLP(w, r, req) {
+			return
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for _, rs := range req.GetResourceSpans() {
+			for _, ss := range rs.GetScopeSpans() {
+				c.spans = append(c.spans, ss.GetSpans()...)
+			}
+		}
+		writeOTLP(w, &tracepb.ExportTraceServiceResponse{})
+	})
+
+	c.server = httptest.NewServer(mux)
+	t.Cleanup(c.server.Close)
+	return c
+}
+
+// Endpoint returns the collector's host:port.
+func (c *OTLPCollector) Endpoint() string {
+	return strings.TrimPrefix(c.server.URL, "http://")
+}
+
+// Metric returns the last export of the named metric, or nil.
+func (c *OTLPCollector) Metric(name string) *otlpmetric.Metric {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := len(c.metrics) - 1; i >= 0; i-- {
+		if c.metrics[i].GetName() == name {
+			return c.metrics[i]
+		}
+	}
+	return nil
+}
+
+func (c *OTLPCollector) Spans() []*otlptrace.Span {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*otlptrace.Span(nil), c.spans...)
+}
+
+// Attr returns the string form of the attribute key, or "".
+func Attr(attrs []*commonpb.KeyValue, key string) string {
+	for _, kv := range attrs {
+		if kv.GetKey() != key {
+			continue
+		}
+		switch v := kv.GetValue().GetValue().(type) {
+		case *commonpb.AnyValue_StringValue:
+			return v.StringValue
+		case *commonpb.AnyValue_IntValue:
+			return strconv.FormatInt(v.IntValue, 10)
+		}
+	}
+	return ""
+}
+
+func decodeOTLP(w http.ResponseWriter, r *http.Request, msg proto.Message) bool {
+	body, err := io.ReadAll(r.Body)
+	if err == nil {
+		err = proto.Unmarshal(body, msg)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func writeOTLP(w http.ResponseWriter, msg proto.Message) {
+	body, _ := proto.Marshal(msg)
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Write(body)
+}
@@ -0,0 +1,89 @@
+package fixtures
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+)
+
+// StartEchoServer starts a TCP server on 127.0.0.1 that echoes everything it
+// receives and returns its port.
+func StartEchoServer(t testing.TB) int {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start echo server: %v", err)
+	}
+	serveEcho(t, ln)
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+// StartUnixEchoServer starts an echo server on a Unix socket in a temporary
+// directory and returns the socket path.
+func StartUnixEchoServer(t testing.TB) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "backend.sock")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("Failed to start unix echo server: %v", err)
+	}
+	serveEcho(t, ln)
+	return path
+}
+
+func StartTLSEchoServer(t testing.TB, config *tls.Config) int {
+	t.Helper()
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", config)
+	if err != nil {
+		t.Fatalf("Failed to start TLS echo server: %v", err)
+	}
+	serveEcho(t, ln)
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func serveEcho(t testing.TB, ln net.Listener) {
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+}
+
+// StartUDPEchoServer starts a UDP server on 127.0.0.1 that answers each
+// datagram with prefix followed by the payload.
+func StartUDPEchoServer(t testing.TB, prefix string) int {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start UDP echo server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 64*1024)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			conn.WriteTo(append([]byte(prefix), buf[:n]...), addr)
+		}
+	}()
+
+	return conn.LocalAddr().(*net.UDPAddr).Port
+}
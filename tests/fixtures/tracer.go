@@ -0,0 +1,82 @@
+package fixtures
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/port"
+)
+
+// TracedSession is what a TracerRecorder saw of one session.
+type TracedSession struct {
+	Client   string
+	Accepted time.Time
+	Phases   []string
+	Errors   map[string]error
+	Record   model.SessionRecord
+}
+
+// TracerRecorder is a port.SessionTracer that keeps every ended session.
+type TracerRecorder struct {
+	mu    sync.Mutex
+	ended []TracedSession
+}
+
+func (r *TracerRecorder) StartSession(ctx context.Context, client string, accepted time.Time) port.SessionSpan {
+	return &recordedSpan{recorder: r, session: TracedSession{Client: client, Accepted: accepted, Errors: make(map[string]error)}}
+}
+
+// Wait returns the first n ended sessions, failing the test if they don't
+// arrive within two seconds.
+func (r *TracerRecorder) Wait(t testing.TB, n int) []TracedSession {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		r.mu.Lock()
+		if len(r.ended) >= n {
+			sessions := append([]TracedSession(nil), r.ended[:n]...)
+			r.mu.Unlock()
+			return sessions
+		}
+		r.mu.Unlock()
+
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %d traced sessions", n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+type recordedSpan struct {
+	recorder *TracerRecorder
+	mu       sync.Mutex
+	session  TracedSession
+}
+
+func (s *recordedSpan) Phase(name string) func(error) {
+	s.mu.Lock()
+	s.session.Phases = append(s.session.Phases, name)
+	s.mu.Unlock()
+	return func(err error) {
+		if err != nil {
+			s.mu.Lock()
+			s.session.Errors[name] = err
+			s.mu.Unlock()
+		}
+	}
+}
+
+func (s *recordedSpan) End(record *model.SessionRecord) {
+	s.mu.Lock()
+	s.session.Record = *record
+	session := s.session
+	s.mu.Unlock()
+
+	s.recorder.mu.Lock()
+	s.recorder.ended = append(s.recorder.ended, session)
+	s.recorder.mu.Unlock()
+}
@@ -0,0 +1,228 @@
+//go:build linux
+
+package integration
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/tests/fixtures"
+)
+
+func buildBalancer(t *testing.T) string {
+	t.Helper()
+
+	binary := filepath.Join(t.TempDir(), "tcp-lb")
+	cmd := exec.Command("go", "build", "-o", binary, "../../cmd")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build balancer: %v\n%s", err, output)
+	}
+	return binary
+}
+
+func freePort(t *testing.T) int {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to find a free port: %v", err)
+	}
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+// logWatcher collects process output and reports when a line contains a
+// given text.
+type logWatcher struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (w *logWatcher) consume(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		w.mu.Lock()
+		w.lines = append(w.lines, scanner.Text())
+		w.mu.Unlock()
+	}
+}
+
+func (w *logWatcher) waitFor(t *testing.T, text string, timeout time.Duration) {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		w.mu.Lock()
+		for _, line := range w.lines {
+			if strings.Contains(line, text) {
+				w.mu.Unlock()
+				return
+			}
+		}
+		w.mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for %q in output:\n%s", text, w.String())
+}
+
+func (w *logWatcher) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return strings.Join(w.lines, "\n")
+}
+
+func echoOnce(conn net.Conn, msg string) error {
+	conn.SetDeadline(time.Now().Add(2 * time.Second))
+	if _, err := conn.Write([]byte(msg + "\n")); err != nil {
+		return err
+	}
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if line != msg+"\n" {
+		return fmt.Errorf("expected echo %q, got %q", msg, line)
+	}
+	return nil
+}
+
+func TestHotRestartKeepsAccepting(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds and runs the balancer binary")
+	}
+
+	binary := buildBalancer(t)
+	backendPort := fixtures.StartEchoServer(t)
+	port := freePort(t)
+	addr := fmt.Sprintf("127.0.0.1:%d", port)
+
+	dir := t.TempDir()
+	config := fmt.Sprintf(`
+frontends:
+  - name: web
+    host: 127.0.0.1
+    port: %d
+    pool: web
+pools:
+  - name: web
+    backends:
+      - address: 127.0.0.1
+        port: %d
+        weight: 1
+`, port, backendPort)
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(config), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	// An *os.File pipe, so Wait doesn't wait for output from the new process,
+	// which inherits it.
+	output, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	logs := &logWatcher{}
+	go logs.consume(output)
+
+	parent := exec.Command(binary)
+	parent.Dir = dir
+	parent.Stdout = writer
+	parent.Stderr = writer
+	parent.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := parent.Start(); err != nil {
+		t.Fatalf("Failed to start balancer: %v", err)
+	}
+	writer.Close()
+	t.Cleanup(func() {
+		// The new process inherits the process group of the old one.
+		syscall.Kill(-parent.Process.Pid, syscall.SIGKILL)
+		output.Close()
+	})
+
+	logs.waitFor(t, "ready to accept connections", 10*time.Second)
+
+	longLived, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer longLived.Close()
+	if err := echoOnce(longLived, "before"); err != nil {
+		t.Fatalf("Echo before restart failed: %v", err)
+	}
+
+	var succeeded, failed atomic.Int64
+	var lastErr atomic.Value
+	stop := make(chan struct{})
+	clientDone := make(chan struct{})
+	go func() {
+		defer close(clientDone)
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			conn, err := net.Dial("tcp", addr)
+			if err == nil {
+				err = echoOnce(conn, fmt.Sprintf("ping-%d", i))
+				conn.Close()
+			}
+			if err != nil {
+				failed.Add(1)
+				lastErr.Store(err.Error())
+			} else {
+				succeeded.Add(1)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+
+	if err := parent.Process.Signal(syscall.SIGUSR2); err != nil {
+		t.Fatalf("Failed to signal balancer: %v", err)
+	}
+	logs.waitFor(t, "is serving, draining connections", 20*time.Second)
+
+	if err := echoOnce(longLived, "during drain"); err != nil {
+		t.Errorf("Connection accepted before the restart was cut off: %v", err)
+	}
+	longLived.Close()
+
+	exited := make(chan error, 1)
+	go func() { exited <- parent.Wait() }()
+	select {
+	case <-exited:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Old process did not exit after draining:\n%s", logs.String())
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	close(stop)
+	<-clientDone
+
+	if failed.Load() != 0 {
+		t.Errorf("%d of %d connections failed during the restart, last error: %v\n%s",
+			failed.Load(), failed.Load()+succeeded.Load(), lastErr.Load(), logs.String())
+	}
+	if succeeded.Load() < 10 {
+		t.Errorf("Expected the client to keep connecting, only %d connections succeeded", succeeded.Load())
+	}
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("New process is not serving: %v", err)
+	}
+	defer conn.Close()
+	if err := echoOnce(conn, "after"); err != nil {
+		t.Errorf("Echo through new process failed: %v", err)
+	}
+}
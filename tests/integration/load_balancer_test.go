@@ -2,6 +2,7 @@ package integration
 
 import (
 	"context"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -23,7 +24,6 @@ func TestLoadBalancerDistribution(t *testing.T) {
 	}
 
 	for _, b := range backends {
-		b.SetHealthy(true)
 		repo.Add(ctx, b)
 	}
 
@@ -62,12 +62,10 @@ func TestLoadBalancerWithUnhealthyBackends(t *testing.T) {
 	}
 
 	for i, b := range backends {
-		if i%2 == 0 {
-			b.SetHealthy(true)
-		} else {
-			b.SetHealthy(false)
-		}
 		repo.Add(ctx, b)
+		if i%2 == 1 {
+			repo.SetHealthy(ctx, b.ID, false)
+		}
 	}
 
 	healthyBackends := repo.GetHealthy(ctx)
@@ -76,8 +74,8 @@ func TestLoadBalancerWithUnhealthyBackends(t *testing.T) {
 	}
 
 	for _, b := range healthyBackends {
-		if !b.GetHealthy() {
-			t.Errorf("Backend %s should be healthy", b.GetID())
+		if !strings.HasPrefix(b.GetID(), "healthy") {
+			t.Errorf("Backend %s should not be healthy", b.GetID())
 		}
 	}
 }
@@ -94,7 +92,6 @@ func TestLoadBalancerConcurrentSelections(t *testing.T) {
 	}
 
 	for _, b := range backends {
-		b.SetHealthy(true)
 		repo.Add(ctx, b)
 	}
 
@@ -166,27 +163,30 @@ func TestBackendConnectionTracking(t *testing.T) {
 }
 
 func TestBackendHealthTransitions(t *testing.T) {
-	backend := model.NewBackend("test", "localhost", 3001, 1)
+	repo := repository.New()
+	ctx := context.Background()
+	repo.Add(ctx, model.NewBackend("test", "localhost", 3001, 1))
+	healthy := func() bool { return len(repo.GetHealthy(ctx)) == 1 }
 
-	if !backend.GetHealthy() {
+	if !healthy() {
 		t.Error("Backend should be healthy by default")
 	}
 
-	backend.SetHealthy(false)
-	if backend.GetHealthy() {
+	repo.SetHealthy(ctx, "test", false)
+	if healthy() {
 		t.Error("Backend should be unhealthy after SetHealthy(false)")
 	}
 
-	backend.SetHealthy(true)
-	if !backend.GetHealthy() {
+	repo.SetHealthy(ctx, "test", true)
+	if !healthy() {
 		t.Error("Backend should be healthy after SetHealthy(true)")
 	}
 
 	for i := 0; i < 10; i++ {
 		isHealthy := i%2 == 0
-		backend.SetHealthy(isHealthy)
-		if backend.GetHealthy() != isHealthy {
-			t.Errorf("Iteration %d: expected healthy=%v, got %v", i, isHealthy, backend.GetHealthy())
+		repo.SetHealthy(ctx, "test", isHealthy)
+		if healthy() != isHealthy {
+			t.Errorf("Iteration %d: expected healthy=%v, got %v", i, isHealthy, healthy())
 		}
 	}
 }
@@ -202,7 +202,6 @@ func TestRepositoryOperations(t *testing.T) {
 	}
 
 	for _, b := range backends {
-		b.SetHealthy(true)
 		err := repo.Add(ctx, b)
 		if err != nil {
 			t.Fatalf("Failed to add backend: %v", err)
@@ -219,7 +218,9 @@ func TestRepositoryOperations(t *testing.T) {
 		t.Errorf("Expected 3 healthy backends, got %d", len(healthy))
 	}
 
-	all[0].SetHealthy(false)
+	if err := repo.SetHealthy(ctx, all[0].ID, false); err != nil {
+		t.Fatalf("Failed to mark backend unhealthy: %v", err)
+	}
 	healthyAfter := repo.GetHealthy(ctx)
 	if len(healthyAfter) != 2 {
 		t.Errorf("Expected 2 healthy backends after marking one unhealthy, got %d", len(healthyAfter))
@@ -237,7 +238,6 @@ func TestMultipleBackendSelections(t *testing.T) {
 	}
 
 	for _, b := range backends {
-		b.SetHealthy(true)
 		repo.Add(ctx, b)
 	}
 
@@ -256,9 +256,6 @@ func TestMultipleBackendSelections(t *testing.T) {
 }
 
 func TestBackendTimeoutScenario(t *testing.T) {
-	backend := model.NewBackend("slow", "localhost", 9999, 1)
-	backend.SetHealthy(true)
-
 	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
 	defer cancel()
 
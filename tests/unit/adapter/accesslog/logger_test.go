@@ -0,0 +1,134 @@
+package accesslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/accesslog"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+)
+
+func sampleRecord() *model.SessionRecord {
+	return &model.SessionRecord{
+		Client:        "198.51.100.7:52000",
+		Backend:       "10.0.0.10:3000",
+		Start:         time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Duration:      1500 * time.Millisecond,
+		BytesSent:     120,
+		BytesReceived: 4096,
+		CloseReason:   model.CloseClientClosed,
+		Retries:       2,
+		TLSVersion:    "TLS 1.3",
+		ServerName:    "api.example.com",
+		ALPN:          "h2",
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	var out bytes.Buffer
+	l, err := accesslog.New(&out, accesslog.FormatJSON, "")
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	l.ForFrontend("web").LogSession(sampleRecord())
+
+	var line map[string]any
+	if err := json.Unmarshal(out.Bytes(), &line); err != nil {
+		t.Fatalf("Expected a JSON line, got %q: %v", out.String(), err)
+	}
+	expected := map[string]any{
+		"frontend":       "web",
+		"client":         "198.51.100.7:52000",
+		"backend":        "10.0.0.10:3000",
+		"start":          "2026-01-02T03:04:05Z",
+		"duration_ms":    1500.0,
+		"bytes_sent":     120.0,
+		"bytes_received": 4096.0,
+		"close_reason":   "client_closed",
+		"retries":        2.0,
+		"tls_version":    "TLS 1.3",
+		"sni":            "api.example.com",
+		"alpn":           "h2",
+	}
+	for key, value := range expected {
+		if line[key] != value {
+			t.Errorf("Expected %s=%v, got %v", key, value, line[key])
+		}
+	}
+}
+
+func TestJSONOmitsMissingFields(t *testing.T) {
+	var out bytes.Buffer
+	l, _ := accesslog.New(&out, accesslog.FormatJSON, "")
+
+	l.LogSession(&model.SessionRecord{Client: "198.51.100.7:52000", CloseReason: model.CloseNoHealthyBackends})
+
+	for _, key := range []string{`"backend"`, `"sni"`, `"tls_version"`, `"alpn"`} {
+		if strings.Contains(out.String(), key) {
+			t.Errorf("Expected %s to be omitted from %s", key, out.String())
+		}
+	}
+}
+
+func TestTextFormat(t *testing.T) {
+	var out bytes.Buffer
+	l, err := accesslog.New(&out, accesslog.FormatText, "")
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	l.ForFrontend("web").LogSession(sampleRecord())
+
+	expected := "2026-01-02T03:04:05.000Z web 198.51.100.7:52000 -> 10.0.0.10:3000 duration=1.5s sent=120 received=4096 reason=client_closed retries=2 tls=TLS 1.3 sni=api.example.com alpn=h2\n"
+	if out.String() != expected {
+		t.Errorf("Unexpected line:\n got %q\nwant %q", out.String(), expected)
+	}
+
+	out.Reset()
+	l, err = accesslog.New(&out, accesslog.FormatText, `{{.Client}} {{.CloseReason}}`)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	l.LogSession(sampleRecord())
+	if out.String() != "198.51.100.7:52000 client_closed\n" {
+		t.Errorf("Unexpected custom line %q", out.String())
+	}
+}
+
+func TestInvalidFormat(t *testing.T) {
+	if _, err := accesslog.New(&bytes.Buffer{}, "xml", ""); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+	if _, err := accesslog.New(&bytes.Buffer{}, accesslog.FormatText, "{{.Nope"); err == nil {
+		t.Error("Expected error for broken template")
+	}
+}
+
+func TestConcurrentLinesDoNotInterleave(t *testing.T) {
+	var out bytes.Buffer
+	l, _ := accesslog.New(&out, accesslog.FormatJSON, "")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.ForFrontend("web").LogSession(sampleRecord())
+		}()
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 50 {
+		t.Fatalf("Expected 50 lines, got %d", len(lines))
+	}
+	for _, line := range lines {
+		if !json.Valid([]byte(line)) {
+			t.Fatalf("Invalid line %q", line)
+		}
+	}
+}
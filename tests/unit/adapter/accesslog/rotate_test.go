@@ -0,0 +1,92 @@
+package accesslog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/accesslog"
+)
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestRotateBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	f, err := accesslog.OpenFile(path, 20, 2)
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer f.Close()
+
+	for _, line := range []string{"first line\n", "second line\n", "third line\n", "fourth line\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+	}
+
+	if got := readFile(t, path); got != "fourth line\n" {
+		t.Errorf("Unexpected current file %q", got)
+	}
+	if got := readFile(t, path+".1"); got != "third line\n" {
+		t.Errorf("Unexpected first backup %q", got)
+	}
+	if got := readFile(t, path+".2"); got != "second line\n" {
+		t.Errorf("Unexpected second backup %q", got)
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("Expected only two backups to be kept")
+	}
+}
+
+func TestAppendsToExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	if err := os.WriteFile(path, []byte("0123456789\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+
+	f, err := accesslog.OpenFile(path, 15, 1)
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer f.Close()
+
+	f.Write([]byte("next\n"))
+	if got := readFile(t, path); got != "next\n" {
+		t.Errorf("Expected rotation to count existing size, got %q", got)
+	}
+}
+
+func TestReopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "access.log")
+	f, err := accesslog.OpenFile(path, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer f.Close()
+
+	f.Write([]byte("before\n"))
+	if err := os.Rename(path, filepath.Join(dir, "access.log.old")); err != nil {
+		t.Fatalf("Failed to move log: %v", err)
+	}
+	if err := f.Reopen(); err != nil {
+		t.Fatalf("Failed to reopen: %v", err)
+	}
+	f.Write([]byte("after\n"))
+
+	if got := readFile(t, path); got != "after\n" {
+		t.Errorf("Expected new file after reopen, got %q", got)
+	}
+	if got := readFile(t, filepath.Join(dir, "access.log.old")); !strings.HasPrefix(got, "before") {
+		t.Errorf("Expected moved file to keep old lines, got %q", got)
+	}
+}
@@ -0,0 +1,203 @@
+package acl
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/acl"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+)
+
+func client(ip string) net.Addr {
+	return &net.TCPAddr{IP: net.ParseIP(ip), Port: 40000}
+}
+
+func newACL(t *testing.T, opts acl.Options) *acl.ACL {
+	t.Helper()
+
+	a, err := acl.New(opts, logger.New("test"))
+	if err != nil {
+		t.Fatalf("Failed to create ACL: %v", err)
+	}
+	return a
+}
+
+func TestLongestPrefixWins(t *testing.T) {
+	a := newACL(t, acl.Options{
+		Allow: []string{"10.0.0.0/8", "10.1.2.3", "2001:db8::/32"},
+		Deny:  []string{"10.1.0.0/16", "2001:db8:bad::/48"},
+	})
+
+	tests := []struct {
+		ip      string
+		allowed bool
+		rule    string
+	}{
+		{"10.2.3.4", true, "allow 10.0.0.0/8 (config)"},
+		{"10.1.9.9", false, "deny 10.1.0.0/16 (config)"},
+		{"10.1.2.3", true, "allow 10.1.2.3/32 (config)"},
+		{"::ffff:10.1.9.9", false, "deny 10.1.0.0/16 (config)"},
+		{"2001:db8:1::1", true, "allow 2001:db8::/32 (config)"},
+		{"2001:db8:bad::1", false, "deny 2001:db8:bad::/48 (config)"},
+		{"192.0.2.1", false, "default deny"},
+	}
+
+	for _, test := range tests {
+		allowed, rule := a.Check(client(test.ip))
+		if allowed != test.allowed || rule != test.rule {
+			t.Errorf("%s: expected (%v, %q), got (%v, %q)", test.ip, test.allowed, test.rule, allowed, rule)
+		}
+	}
+}
+
+func TestDenyOnlyAllowsEveryoneElse(t *testing.T) {
+	a := newACL(t, acl.Options{Deny: []string{"203.0.113.0/24"}})
+
+	if allowed, _ := a.Check(client("203.0.113.7")); allowed {
+		t.Error("Expected denied network to be refused")
+	}
+	if allowed, rule := a.Check(client("198.51.100.1")); !allowed || rule != "default allow" {
+		t.Errorf("Expected default allow, got (%v, %q)", allowed, rule)
+	}
+	if allowed, _ := a.Check(&net.UnixAddr{Name: "@", Net: "unix"}); !allowed {
+		t.Error("Expected unix clients to be allowed")
+	}
+}
+
+func TestDenyWinsForSamePrefix(t *testing.T) {
+	a := newACL(t, acl.Options{Allow: []string{"10.0.0.0/8"}, Deny: []string{"10.0.0.0/8"}})
+
+	if allowed, _ := a.Check(client("10.0.0.1")); allowed {
+		t.Error("Expected deny to win over allow for the same prefix")
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := acl.ParseRules(strings.NewReader("# office\nallow 192.0.2.0/24\n\ndeny 192.0.2.66 # printer\n"), "acl.txt")
+	if err != nil {
+		t.Fatalf("Failed to parse rules: %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("Expected 2 rules, got %d", len(rules))
+	}
+	if got := rules[1].String(); got != "deny 192.0.2.66/32 (acl.txt:4)" {
+		t.Errorf("Unexpected rule %q", got)
+	}
+
+	for _, input := range []string{"permit 10.0.0.0/8", "allow", "allow 10.0.0.0/33", "deny not-an-ip"} {
+		if _, err := acl.ParseRules(strings.NewReader(input), "acl.txt"); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
+
+func TestFileReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "acl.txt")
+	if err := os.WriteFile(path, []byte("deny 192.0.2.0/24\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write ACL file: %v", err)
+	}
+
+	a := newACL(t, acl.Options{File: path})
+	if allowed, rule := a.Check(client("192.0.2.1")); allowed || rule != "deny 192.0.2.0/24 ("+path+":1)" {
+		t.Fatalf("Expected file rule to deny, got (%v, %q)", allowed, rule)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go a.Watch(ctx, 10*time.Millisecond)
+
+	// A broken file keeps the previous rules.
+	if err := os.WriteFile(path, []byte("deny nonsense\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write ACL file: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if allowed, _ := a.Check(client("192.0.2.1")); allowed {
+		t.Fatal("Expected previous rules to stay after a bad reload")
+	}
+
+	if err := os.WriteFile(path, []byte("deny 198.51.100.0/24\n# and nothing else\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write ACL file: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if allowed, _ := a.Check(client("192.0.2.1")); allowed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected ACL to reload after the file changed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if allowed, _ := a.Check(client("198.51.100.9")); allowed {
+		t.Error("Expected new file rule to deny")
+	}
+}
+
+func TestMissingFile(t *testing.T) {
+	if _, err := acl.New(acl.Options{File: filepath.Join(t.TempDir(), "missing")}, logger.New("test")); err == nil {
+		t.Error("Expected error for missing ACL file")
+	}
+}
+
+func BenchmarkCheck(b *testing.B) {
+	deny := make([]string, 0, 10000)
+	for i := 0; i < cap(deny); i++ {
+		deny = append(deny, fmt.Sprintf("10.%d.%d.0/24", i/256, i%256))
+	}
+	a, err := acl.New(acl.Options{Deny: deny}, logger.New("test"))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	addr := client("10.20.30.40")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		a.Check(addr)
+	}
+}
+
+func TestFileReloadCallback(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "acl.txt")
+	if err := os.WriteFile(path, []byte("deny 192.0.2.0/24\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write ACL file: %v", err)
+	}
+
+	reloads := make(chan error, 4)
+	a := newACL(t, acl.Options{File: path, OnReload: func(err error) { reloads <- err }})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go a.Watch(ctx, 10*time.Millisecond)
+
+	wait := func() error {
+		t.Helper()
+		select {
+		case err := <-reloads:
+			return err
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for reload callback")
+		}
+		return nil
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if err := os.WriteFile(path, []byte("deny nonsense\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write ACL file: %v", err)
+	}
+	if err := wait(); err == nil {
+		t.Error("Expected callback with the reload error")
+	}
+
+	if err := os.WriteFile(path, []byte("deny 198.51.100.0/24\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write ACL file: %v", err)
+	}
+	if err := wait(); err != nil {
+		t.Errorf("Expected successful reload, got %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/admin"
+)
+
+func TestRequireToken(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	handler := admin.RequireToken("secret", ok)
+
+	for header, want := range map[string]int{
+		"":              http.StatusUnauthorized,
+		"Bearer wrong":  http.StatusUnauthorized,
+		"secret":        http.StatusUnauthorized,
+		"Bearer secret": http.StatusNoContent,
+	} {
+		req := httptest.NewRequest(http.MethodDelete, "/sessions/1", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("Authorization %q: expected status %d, got %d", header, want, rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	admin.RequireToken("", ok).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/sessions", nil))
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("Expected no token to let requests through, got status %d", rec.Code)
+	}
+}
@@ -0,0 +1,42 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/admin"
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/repository"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/port"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+)
+
+func TestSetAdminState(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.New()
+	repo.Add(ctx, model.NewBackend("web-1", "10.0.0.1", 80, 1))
+
+	mux := http.NewServeMux()
+	admin.NewBackendsHandler(map[string]port.BackendAdmin{"web": repo}, logger.New("test")).Register(mux)
+
+	if rec := do(mux, http.MethodPut, "/pools/web/backends/web-1/admin_state?state=drain"); rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if state := repo.AdminState(ctx, "web-1"); state != model.AdminDrain {
+		t.Errorf("Expected web-1 to be draining, got %s", state)
+	}
+
+	for target, want := range map[string]int{
+		"/pools/web/backends/web-1/admin_state?state=paused":   http.StatusBadRequest,
+		"/pools/web/backends/web-2/admin_state?state=disabled": http.StatusNotFound,
+		"/pools/api/backends/web-1/admin_state?state=disabled": http.StatusNotFound,
+	} {
+		if rec := do(mux, http.MethodPut, target); rec.Code != want {
+			t.Errorf("%s: expected %d, got %d", target, want, rec.Code)
+		}
+	}
+	if state := repo.AdminState(ctx, "web-1"); state != model.AdminDrain {
+		t.Errorf("Expected rejected requests to leave web-1 draining, got %s", state)
+	}
+}
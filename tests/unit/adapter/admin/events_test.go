@@ -0,0 +1,168 @@
+package admin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/admin"
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/events"
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/repository"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+)
+
+type sseEvent struct {
+	id, event, data string
+}
+
+func startEventsServer(t *testing.T, bus *events.Bus) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	admin.NewEventsHandler(bus, logger.New("test")).Register(mux)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func openStream(t *testing.T, url, lastEventID string) (*bufio.Reader, func()) {
+	t.Helper()
+
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open event stream: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected text/event-stream, got %q", ct)
+	}
+	return bufio.NewReader(resp.Body), func() { resp.Body.Close() }
+}
+
+func readEvent(t *testing.T, r *bufio.Reader) sseEvent {
+	t.Helper()
+
+	var e sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read event stream: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			if e.event != "" {
+				return e
+			}
+		case strings.HasPrefix(line, "id: "):
+			e.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			e.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			e.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	bus := events.NewBus(events.DefaultHistory)
+	server := startEventsServer(t, bus)
+
+	stream, closeStream := openStream(t, server.URL+"/events?type=backend_down&frontend=web", "")
+	defer closeStream()
+
+	bus.Publish(model.Event{Type: model.EventBackendUp, Frontend: "web", Backend: "10.0.0.1:80"})
+	bus.Publish(model.Event{Type: model.EventBackendDown, Frontend: "db", Backend: "10.0.0.2:5432"})
+	bus.Publish(model.Event{Type: model.EventBackendDown, Frontend: "web", Pool: "main", Backend: "10.0.0.1:80", Message: "health check failed"})
+
+	e := readEvent(t, stream)
+	if e.id != "3" || e.event != model.EventBackendDown {
+		t.Fatalf("Unexpected event %+v", e)
+	}
+	var body map[string]any
+	if err := json.Unmarshal([]byte(e.data), &body); err != nil {
+		t.Fatalf("Invalid JSON %q: %v", e.data, err)
+	}
+	if body["frontend"] != "web" || body["pool"] != "main" || body["backend"] != "10.0.0.1:80" || body["message"] != "health check failed" {
+		t.Errorf("Unexpected event data %v", body)
+	}
+	if _, ok := body["client"]; ok {
+		t.Errorf("Expected empty fields to be omitted, got %v", body)
+	}
+}
+
+func TestEventStreamReportsBackendChanges(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	repo := repository.New()
+	repo.Add(ctx, model.NewBackend("web-1", "10.0.0.1", 80, 1))
+	bus := events.NewBus(events.DefaultHistory)
+	server := startEventsServer(t, bus)
+	go events.PublishBackendChanges(repo.Watch(ctx), bus, "web", logger.New("test"))
+
+	stream, closeStream := openStream(t, server.URL+"/events?pool=web", "")
+	defer closeStream()
+
+	repo.SetAdminState(ctx, "web-1", model.AdminDrain)
+	repo.Remove(ctx, "web-1")
+
+	e := readEvent(t, stream)
+	if e.event != model.EventBackendAdminChanged {
+		t.Fatalf("Expected an admin change, got %+v", e)
+	}
+	var body map[string]any
+	if err := json.Unmarshal([]byte(e.data), &body); err != nil {
+		t.Fatalf("Invalid JSON %q: %v", e.data, err)
+	}
+	if body["type"] != model.EventBackendAdminChanged || body["pool"] != "web" || body["backend"] != "10.0.0.1:80" || body["reason"] != model.AdminDrain {
+		t.Errorf("Unexpected event data %v", body)
+	}
+
+	if e := readEvent(t, stream); e.event != model.EventBackendEjected || !strings.Contains(e.data, `"reason":"removed"`) {
+		t.Errorf("Expected the removal as an ejection, got %+v", e)
+	}
+}
+
+func TestEventStreamResumesFromLastEventID(t *testing.T) {
+	bus := events.NewBus(events.DefaultHistory)
+	server := startEventsServer(t, bus)
+
+	for _, backend := range []string{"a", "b", "c"} {
+		bus.Publish(model.Event{Type: model.EventBackendUp, Backend: backend})
+	}
+
+	stream, closeStream := openStream(t, server.URL+"/events", "1")
+	defer closeStream()
+
+	for _, expected := range []string{"2", "3"} {
+		if e := readEvent(t, stream); e.id != expected {
+			t.Errorf("Expected event %s, got %+v", expected, e)
+		}
+	}
+}
+
+func TestEventStreamRejectsBadLastEventID(t *testing.T) {
+	mux := http.NewServeMux()
+	admin.NewEventsHandler(events.NewBus(events.DefaultHistory), logger.New("test")).Register(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	req.Header.Set("Last-Event-ID", "latest")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400, got %d", rec.Code)
+	}
+}
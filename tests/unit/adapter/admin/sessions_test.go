@@ -0,0 +1,152 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/admin"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+	"github.com/reybrally/TCP-Load-Balancer/tests/fixtures"
+)
+
+type fakeSessions struct {
+	sessions []model.SessionInfo
+	filter   model.SessionFilter
+	killed   []uint64
+	backends []string
+}
+
+func (f *fakeSessions) Sessions(filter model.SessionFilter) []model.SessionInfo {
+	f.filter = filter
+	return f.sessions
+}
+
+func (f *fakeSessions) Kill(id uint64) bool {
+	for _, s := range f.sessions {
+		if s.ID == id {
+			f.killed = append(f.killed, id)
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeSessions) KillBackend(backend string) int {
+	f.backends = append(f.backends, backend)
+	return 3
+}
+
+func newServer(sessions *fakeSessions) *http.ServeMux {
+	return newServerWithEvents(sessions, nil)
+}
+
+func newServerWithEvents(sessions *fakeSessions, events *fixtures.EventRecorder) *http.ServeMux {
+	mux := http.NewServeMux()
+	if events == nil {
+		admin.NewSessionsHandler(sessions, nil, logger.New("test")).Register(mux)
+	} else {
+		admin.NewSessionsHandler(sessions, events, logger.New("test")).Register(mux)
+	}
+	return mux
+}
+
+func do(mux *http.ServeMux, method, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+	return rec
+}
+
+func TestListSessions(t *testing.T) {
+	sessions := &fakeSessions{sessions: []model.SessionInfo{
+		{ID: 1, Frontend: "web", Client: "198.51.100.7:5000", Backend: "10.0.0.1:80", Start: time.Now(), BytesSent: 10, BytesReceived: 20, Idle: 2 * time.Second},
+		{ID: 2, Frontend: "web", Client: "198.51.100.8:5000", Backend: "10.0.0.1:80", Start: time.Now()},
+	}}
+	mux := newServer(sessions)
+
+	rec := do(mux, http.MethodGet, "/sessions?frontend=web&backend=10.0.0.1:80&client=198.51.100.7&min_idle=1s&limit=1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+
+	expected := model.SessionFilter{Frontend: "web", Backend: "10.0.0.1:80", Client: "198.51.100.7", MinIdle: time.Second}
+	if sessions.filter != expected {
+		t.Errorf("Expected filter %+v, got %+v", expected, sessions.filter)
+	}
+
+	var body struct {
+		Total    int `json:"total"`
+		Sessions []struct {
+			ID            uint64  `json:"id"`
+			Client        string  `json:"client"`
+			BytesSent     int64   `json:"bytes_sent"`
+			BytesReceived int64   `json:"bytes_received"`
+			IdleSeconds   float64 `json:"idle_seconds"`
+		} `json:"sessions"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if body.Total != 2 || len(body.Sessions) != 1 {
+		t.Fatalf("Expected 1 of 2 sessions, got %d of %d", len(body.Sessions), body.Total)
+	}
+	s := body.Sessions[0]
+	if s.ID != 1 || s.Client != "198.51.100.7:5000" || s.BytesSent != 10 || s.BytesReceived != 20 || s.IdleSeconds != 2 {
+		t.Errorf("Unexpected session %+v", s)
+	}
+}
+
+func TestListSessionsRejectsBadParameters(t *testing.T) {
+	mux := newServer(&fakeSessions{})
+
+	for _, target := range []string{"/sessions?min_idle=soon", "/sessions?limit=-1"} {
+		if rec := do(mux, http.MethodGet, target); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", target, rec.Code)
+		}
+	}
+}
+
+func TestKillSession(t *testing.T) {
+	sessions := &fakeSessions{sessions: []model.SessionInfo{{ID: 7}}}
+	events := &fixtures.EventRecorder{}
+	mux := newServerWithEvents(sessions, events)
+
+	if rec := do(mux, http.MethodDelete, "/sessions/7"); rec.Code != http.StatusOK {
+		t.Errorf("Expected 200, got %d", rec.Code)
+	}
+	if rec := do(mux, http.MethodDelete, "/sessions/8"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown session, got %d", rec.Code)
+	}
+	if rec := do(mux, http.MethodDelete, "/sessions/abc"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for bad id, got %d", rec.Code)
+	}
+	if len(sessions.killed) != 1 || sessions.killed[0] != 7 {
+		t.Errorf("Expected session 7 killed, got %v", sessions.killed)
+	}
+	event := events.Wait(t, 1)[0]
+	if event.Type != model.EventSessionKilled || event.Reason != "admin" {
+		t.Errorf("Unexpected event %+v", event)
+	}
+}
+
+func TestKillBackendSessions(t *testing.T) {
+	sessions := &fakeSessions{}
+	mux := newServer(sessions)
+
+	if rec := do(mux, http.MethodDelete, "/sessions"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without backend, got %d", rec.Code)
+	}
+
+	rec := do(mux, http.MethodDelete, "/sessions?backend=10.0.0.1:80")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	var body map[string]int
+	json.NewDecoder(rec.Body).Decode(&body)
+	if body["killed"] != 3 || len(sessions.backends) != 1 || sessions.backends[0] != "10.0.0.1:80" {
+		t.Errorf("Unexpected result %v for %v", body, sessions.backends)
+	}
+}
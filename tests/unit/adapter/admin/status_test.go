@@ -0,0 +1,129 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/admin"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+)
+
+type fakeStatus struct {
+	status model.Status
+}
+
+func (f *fakeStatus) Status(ctx context.Context) model.Status {
+	return f.status
+}
+
+func newStatusServer() *http.ServeMux {
+	changed := time.Now().Add(-time.Minute)
+	status := &fakeStatus{status: model.Status{
+		Version: "v1.0.0",
+		Started: time.Now().Add(-time.Hour),
+		Frontends: []model.FrontendStatus{{
+			Name:           "web",
+			Protocol:       "tcp",
+			Listen:         "0.0.0.0:8080",
+			Algorithm:      "round_robin",
+			ActiveSessions: 3,
+			TotalSessions:  40,
+			Rejected:       map[string]int64{"acl_denied": 2},
+			Pools: []model.PoolStatus{{
+				Name: "web",
+				Backends: []model.BackendStatus{
+					{ID: "web-backend-0", Address: "10.0.0.1:80", Healthy: true, AdminState: model.AdminDrain, Weight: 1, ActiveSessions: 3, TotalSessions: 30, BytesSent: 2048},
+					{ID: "web-backend-1", Address: "10.0.0.2:80", AdminState: model.AdminEnabled, Weight: 1, TotalSessions: 10, LastChange: changed,
+						Errors:       map[string]int64{"connection_failed": 4},
+						HealthChecks: []model.HealthCheck{{Time: changed, Healthy: true}, {Time: changed, Healthy: false}}},
+				},
+			}},
+		}},
+	}}
+
+	mux := http.NewServeMux()
+	admin.NewStatusHandler(status, logger.New("test")).Register(mux)
+	return mux
+}
+
+func TestStatusJSON(t *testing.T) {
+	rec := do(newStatusServer(), http.MethodGet, "/status?format=json")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+
+	var body struct {
+		Version   string `json:"version"`
+		Frontends []struct {
+			Name     string           `json:"name"`
+			Rejected map[string]int64 `json:"rejected"`
+			Pools    []struct {
+				Backends []struct {
+					ID           string           `json:"id"`
+					State        string           `json:"state"`
+					AdminState   string           `json:"admin_state"`
+					BytesSent    int64            `json:"bytes_sent"`
+					Errors       map[string]int64 `json:"errors"`
+					LastChange   *time.Time       `json:"last_change"`
+					HealthChecks []struct {
+						Healthy bool `json:"healthy"`
+					} `json:"health_checks"`
+				} `json:"backends"`
+			} `json:"pools"`
+		} `json:"frontends"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.Version != "v1.0.0" || len(body.Frontends) != 1 || body.Frontends[0].Rejected["acl_denied"] != 2 {
+		t.Fatalf("Unexpected status %+v", body)
+	}
+	backends := body.Frontends[0].Pools[0].Backends
+	if len(backends) != 2 {
+		t.Fatalf("Expected 2 backends, got %d", len(backends))
+	}
+	if backends[0].State != "up" || backends[0].AdminState != model.AdminDrain || backends[0].BytesSent != 2048 || backends[0].LastChange != nil {
+		t.Errorf("Unexpected first backend %+v", backends[0])
+	}
+	if backends[1].State != "down" || backends[1].AdminState != model.AdminEnabled || backends[1].Errors["connection_failed"] != 4 || backends[1].LastChange == nil {
+		t.Errorf("Unexpected second backend %+v", backends[1])
+	}
+	if len(backends[1].HealthChecks) != 2 || backends[1].HealthChecks[1].Healthy {
+		t.Errorf("Expected health history to be kept in order, got %+v", backends[1].HealthChecks)
+	}
+}
+
+func TestStatusHTML(t *testing.T) {
+	mux := newStatusServer()
+
+	rec := do(mux, http.MethodGet, "/status")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("Expected HTML, got %s", ct)
+	}
+	page := rec.Body.String()
+	for _, want := range []string{
+		`<meta http-equiv="refresh" content="10">`,
+		"web-backend-0", "10.0.0.2:80", "DOWN", "admin-drain", "2.0 KiB", "acl_denied=2", "1m0s ago",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Expected page to contain %q", want)
+		}
+	}
+
+	rec = do(mux, http.MethodGet, "/status?refresh=0")
+	if strings.Contains(rec.Body.String(), "http-equiv") {
+		t.Error("Expected refresh=0 to disable auto-refresh")
+	}
+
+	if rec := do(mux, http.MethodGet, "/status?refresh=soon"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid refresh, got %d", rec.Code)
+	}
+}
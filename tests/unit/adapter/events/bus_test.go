@@ -0,0 +1,125 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/events"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+)
+
+func receive(t *testing.T, ch <-chan model.Event) model.Event {
+	t.Helper()
+
+	select {
+	case event, ok := <-ch:
+		if !ok {
+			t.Fatal("Expected an event, channel closed")
+		}
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for an event")
+	}
+	return model.Event{}
+}
+
+func TestBusDeliversMatchingEvents(t *testing.T) {
+	bus := events.NewBus(events.DefaultHistory)
+	ch, cancel := bus.Subscribe(model.EventFilter{Types: []string{model.EventBackendDown}, Backend: "10.0.0.1:80"}, 0)
+	defer cancel()
+
+	bus.Publish(model.Event{Type: model.EventBackendUp, Backend: "10.0.0.1:80"})
+	bus.Publish(model.Event{Type: model.EventBackendDown, Backend: "10.0.0.2:80"})
+	bus.Publish(model.Event{Type: model.EventBackendDown, Backend: "10.0.0.1:80"})
+
+	event := receive(t, ch)
+	if event.ID != 3 || event.Type != model.EventBackendDown || event.Backend != "10.0.0.1:80" || event.Time.IsZero() {
+		t.Errorf("Unexpected event %+v", event)
+	}
+	select {
+	case event := <-ch:
+		t.Errorf("Expected no further events, got %+v", event)
+	default:
+	}
+}
+
+func TestBusFiltersByPool(t *testing.T) {
+	bus := events.NewBus(events.DefaultHistory)
+	ch, cancel := bus.Subscribe(model.EventFilter{Pool: "web"}, 0)
+	defer cancel()
+
+	bus.Publish(model.Event{Type: model.EventBackendDown, Pool: "db", Backend: "10.0.0.2:5432"})
+	bus.Publish(model.Event{Type: model.EventBackendDown, Pool: "web", Backend: "10.0.0.1:80"})
+
+	if event := receive(t, ch); event.Pool != "web" || event.Backend != "10.0.0.1:80" {
+		t.Errorf("Expected only the web pool event, got %+v", event)
+	}
+}
+
+func TestBusReplaysHistory(t *testing.T) {
+	bus := events.NewBus(2)
+	for _, backend := range []string{"a", "b", "c"} {
+		bus.Publish(model.Event{Type: model.EventBackendUp, Backend: backend})
+	}
+
+	ch, cancel := bus.Subscribe(model.EventFilter{}, 1)
+	defer cancel()
+
+	// Event 1 is past the history and event 2 is the last one seen.
+	if event := receive(t, ch); event.ID != 2 || event.Backend != "b" {
+		t.Errorf("Expected event 2 first, got %+v", event)
+	}
+	if event := receive(t, ch); event.ID != 3 || event.Backend != "c" {
+		t.Errorf("Expected event 3 next, got %+v", event)
+	}
+}
+
+func TestBusDisconnectsSlowSubscribers(t *testing.T) {
+	bus := events.NewBus(events.DefaultHistory)
+	ch, cancel := bus.Subscribe(model.EventFilter{}, 0)
+	defer cancel()
+
+	for i := 0; i < 1000; i++ {
+		bus.Publish(model.Event{Type: model.EventConnectionRejected})
+	}
+
+	received := 0
+	for range ch {
+		received++
+	}
+	if received == 0 || received >= 1000 {
+		t.Errorf("Expected a partial stream before disconnect, got %d events", received)
+	}
+}
+
+func TestBusClose(t *testing.T) {
+	bus := events.NewBus(events.DefaultHistory)
+	ch, _ := bus.Subscribe(model.EventFilter{}, 0)
+
+	bus.Close()
+	if _, ok := <-ch; ok {
+		t.Error("Expected subscriber channel to be closed")
+	}
+
+	bus.Publish(model.Event{Type: model.EventBackendUp})
+	late, _ := bus.Subscribe(model.EventFilter{}, 0)
+	if _, ok := <-late; ok {
+		t.Error("Expected subscriptions after Close to be closed")
+	}
+}
+
+func TestBusForFrontend(t *testing.T) {
+	bus := events.NewBus(events.DefaultHistory)
+	ch, cancel := bus.Subscribe(model.EventFilter{Frontend: "web"}, 0)
+	defer cancel()
+
+	bus.ForFrontend("web").Publish(model.Event{Type: model.EventConnectionRejected, Client: "198.51.100.7"})
+	bus.ForFrontend("db").Publish(model.Event{Type: model.EventConnectionRejected, Frontend: "web", Client: "198.51.100.8"})
+
+	if event := receive(t, ch); event.Frontend != "web" || event.Client != "198.51.100.7" {
+		t.Errorf("Expected scoped frontend, got %+v", event)
+	}
+	if event := receive(t, ch); event.Client != "198.51.100.8" {
+		t.Errorf("Expected explicit frontend to be kept, got %+v", event)
+	}
+}
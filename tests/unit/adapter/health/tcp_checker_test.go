@@ -0,0 +1,99 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/health"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+	"github.com/reybrally/TCP-Load-Balancer/tests/fixtures"
+)
+
+func startRecordingServer(t *testing.T, size int) (int, chan []byte) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		buf := make([]byte, size)
+		n, _ := io.ReadFull(conn, buf)
+		received <- buf[:n]
+	}()
+
+	return ln.Addr().(*net.TCPAddr).Port, received
+}
+
+func TestCheckHealthyBackend(t *testing.T) {
+	port := fixtures.StartEchoServer(t)
+	backend := model.NewBackend("b1", "127.0.0.1", port, 1)
+	metrics := fixtures.NewMetricsRecorder()
+
+	checker := health.New(time.Second, metrics, logger.New("test"))
+	if !checker.Check(context.Background(), backend) {
+		t.Fatal("Expected backend to be healthy")
+	}
+	if metrics.Count("health_checks:"+backend.GetAddress()+":success") != 1 {
+		t.Error("Expected successful check to be counted")
+	}
+}
+
+func TestCheckUnreachableBackend(t *testing.T) {
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	backend := model.NewBackend("b1", "127.0.0.1", port, 1)
+	checker := health.New(time.Second, fixtures.NewMetricsRecorder(), logger.New("test"))
+	if checker.Check(context.Background(), backend) {
+		t.Error("Expected closed port to be unhealthy")
+	}
+}
+
+func TestCheckSendsProxyProtocolLocal(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  int
+		expected []byte
+	}{
+		{"v1", 1, []byte("PROXY UNKNOWN\r\n")},
+		{"v2", 2, []byte("\r\n\r\n\x00\r\nQUIT\n\x20\x00\x00\x00")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			port, received := startRecordingServer(t, len(test.expected))
+			backend := model.NewBackend("b1", "127.0.0.1", port, 1)
+
+			checker := health.New(time.Second, fixtures.NewMetricsRecorder(), logger.New("test"),
+				health.WithProxyProtocol(test.version))
+			if !checker.Check(context.Background(), backend) {
+				t.Fatal("Expected backend to be healthy")
+			}
+
+			select {
+			case data := <-received:
+				if !bytes.Equal(data, test.expected) {
+					t.Errorf("Expected %q, got %q", test.expected, data)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("Backend did not receive a header")
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package listener
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/acl"
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/listener"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+	"github.com/reybrally/TCP-Load-Balancer/tests/fixtures"
+)
+
+func startACLListener(t *testing.T, opts acl.Options, metrics *fixtures.MetricsRecorder, extra ...listener.Option) *listener.TCPListener {
+	t.Helper()
+
+	log := logger.New("test")
+	access, err := acl.New(opts, log)
+	if err != nil {
+		t.Fatalf("Failed to create ACL: %v", err)
+	}
+	tl, err := listener.New("127.0.0.1", 0, log, append([]listener.Option{listener.WithACL(access), listener.WithMetrics(metrics)}, extra...)...)
+	if err != nil {
+		t.Fatalf("Failed to create listener: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go tl.Listen(ctx, echoHandler{})
+	t.Cleanup(func() {
+		cancel()
+		tl.Close()
+	})
+	return tl
+}
+
+func TestACLDeniesClient(t *testing.T) {
+	metrics := fixtures.NewMetricsRecorder()
+	tl := startACLListener(t, acl.Options{Allow: []string{"10.0.0.0/8"}, Deny: []string{"127.0.0.0/8"}}, metrics)
+
+	conn, err := net.Dial("tcp", tl.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	expectClosed(t, conn)
+
+	if got := metrics.Count("rejected:acl_denied"); got != 1 {
+		t.Errorf("Expected 1 denied connection, got %d", got)
+	}
+}
+
+func TestACLRejectionPublishesEvent(t *testing.T) {
+	events := &fixtures.EventRecorder{}
+	tl := startACLListener(t, acl.Options{Deny: []string{"127.0.0.0/8"}}, fixtures.NewMetricsRecorder(), listener.WithEvents(events))
+
+	conn, err := net.Dial("tcp", tl.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	expectClosed(t, conn)
+
+	event := events.Wait(t, 1)[0]
+	if event.Type != model.EventConnectionRejected || event.Reason != "acl_denied" || event.Client != conn.LocalAddr().String() {
+		t.Errorf("Unexpected event %+v", event)
+	}
+}
+
+func TestACLAllowsClient(t *testing.T) {
+	metrics := fixtures.NewMetricsRecorder()
+	tl := startACLListener(t, acl.Options{Allow: []string{"127.0.0.1"}}, metrics)
+
+	conn, err := net.Dial("tcp", tl.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	echo(t, conn, "hello")
+
+	if got := metrics.Count("rejected:acl_denied"); got != 0 {
+		t.Errorf("Expected no denied connections, got %d", got)
+	}
+}
@@ -0,0 +1,50 @@
+package listener
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/listener"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+)
+
+func TestDrainWaitsForAcceptedConnections(t *testing.T) {
+	tl, err := listener.New("127.0.0.1", 0, logger.New("test"))
+	if err != nil {
+		t.Fatalf("Failed to create listener: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		tl.Listen(ctx, echoHandler{})
+		close(stopped)
+	}()
+
+	conn, err := net.Dial("tcp", tl.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	echo(t, conn, "before close")
+
+	cancel()
+	tl.Close()
+	<-stopped
+
+	short, shortCancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer shortCancel()
+	if err := tl.Drain(short); err == nil {
+		t.Fatal("Expected Drain to wait for the open connection")
+	}
+
+	echo(t, conn, "still served after close")
+	conn.Close()
+
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer drainCancel()
+	if err := tl.Drain(drainCtx); err != nil {
+		t.Errorf("Expected Drain to finish after the client closed, got %v", err)
+	}
+}
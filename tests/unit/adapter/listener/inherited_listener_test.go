@@ -0,0 +1,76 @@
+package listener
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/listener"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+)
+
+func TestListenerFromInheritedFile(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	file, err := ln.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatalf("Failed to get socket file: %v", err)
+	}
+	ln.Close()
+
+	tl, err := listener.NewFromFiles([]*os.File{file}, logger.New("test"))
+	if err != nil {
+		t.Fatalf("NewFromFile failed: %v", err)
+	}
+	if tl.Addr().String() != ln.Addr().String() {
+		t.Errorf("Expected address %s, got %s", ln.Addr(), tl.Addr())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go tl.Listen(ctx, echoHandler{})
+	defer tl.Close()
+
+	conn, err := net.Dial("tcp", tl.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	echo(t, conn, "inherited")
+}
+
+func TestUDPListenerFromInheritedFile(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	file, err := conn.File()
+	if err != nil {
+		t.Fatalf("Failed to get socket file: %v", err)
+	}
+	conn.Close()
+
+	ul, err := listener.NewUDPFromFile(file, logger.New("test"))
+	if err != nil {
+		t.Fatalf("NewUDPFromFile failed: %v", err)
+	}
+	defer ul.Close()
+
+	if ul.Addr().String() != conn.LocalAddr().String() {
+		t.Errorf("Expected address %s, got %s", conn.LocalAddr(), ul.Addr())
+	}
+}
+
+func TestInheritedFileOfWrongType(t *testing.T) {
+	conn, _ := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	file, _ := conn.File()
+	conn.Close()
+
+	if _, err := listener.NewFromFiles([]*os.File{file}, logger.New("test")); err == nil {
+		t.Error("Expected error using a UDP socket as a stream listener")
+	}
+}
@@ -0,0 +1,52 @@
+package listener
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/listener"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+)
+
+func TestMaxConnectionsHoldsClientsInBacklog(t *testing.T) {
+	tl, err := listener.New("127.0.0.1", 0, logger.New("test"), listener.WithMaxConnections(1))
+	if err != nil {
+		t.Fatalf("Failed to create listener: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- tl.Listen(ctx, echoHandler{}) }()
+	defer cancel()
+
+	first, err := net.Dial("tcp", tl.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	echo(t, first, "first")
+
+	second, err := net.Dial("tcp", tl.Addr().String())
+	if err != nil {
+		t.Fatalf("Expected kernel to queue the second client: %v", err)
+	}
+	defer second.Close()
+
+	second.SetDeadline(time.Now().Add(200 * time.Millisecond))
+	second.Write([]byte("second\n"))
+	if _, err := bufio.NewReader(second).ReadString('\n'); err == nil {
+		t.Fatal("Expected second client to wait while the first is served")
+	}
+
+	first.Close()
+	echo(t, second, "second")
+
+	tl.Close()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Listen did not return after Close")
+	}
+}
@@ -0,0 +1,163 @@
+package listener
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/listener"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/proxyproto"
+	"github.com/reybrally/TCP-Load-Balancer/tests/fixtures"
+)
+
+type addrHandler struct {
+	remote chan net.Addr
+}
+
+func (h *addrHandler) Handle(ctx context.Context, conn net.Conn) error {
+	defer conn.Close()
+	h.remote <- conn.RemoteAddr()
+	buf := make([]byte, 4)
+	conn.Read(buf)
+	conn.Write(buf)
+	return nil
+}
+
+func startProxyListener(t *testing.T, trusted []*net.IPNet, metrics *fixtures.MetricsRecorder) (*listener.TCPListener, *addrHandler) {
+	t.Helper()
+
+	tl, err := listener.New("127.0.0.1", 0, logger.New("test"),
+		listener.WithMetrics(metrics),
+		listener.WithProxyProtocol(trusted, 200*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create listener: %v", err)
+	}
+
+	handler := &addrHandler{remote: make(chan net.Addr, 1)}
+	ctx, cancel := context.WithCancel(context.Background())
+	go tl.Listen(ctx, handler)
+	t.Cleanup(func() {
+		cancel()
+		tl.Close()
+	})
+	return tl, handler
+}
+
+// loopback trusts the tests' own connections to send a PROXY header.
+func loopback() []*net.IPNet {
+	_, network, _ := net.ParseCIDR("127.0.0.0/8")
+	return []*net.IPNet{network}
+}
+
+func TestListenerAcceptsProxyHeader(t *testing.T) {
+	for _, version := range []int{1, 2} {
+		tl, handler := startProxyListener(t, loopback(), fixtures.NewMetricsRecorder())
+
+		conn, err := net.Dial("tcp", tl.Addr().String())
+		if err != nil {
+			t.Fatalf("Dial failed: %v", err)
+		}
+
+		header := &proxyproto.Header{
+			Version:     version,
+			Command:     proxyproto.CommandProxy,
+			Source:      &net.TCPAddr{IP: net.ParseIP("198.51.100.23"), Port: 51000},
+			Destination: &net.TCPAddr{IP: net.ParseIP("10.0.0.5"), Port: 443},
+		}
+		header.WriteTo(conn)
+		conn.Write([]byte("ping"))
+
+		select {
+		case addr := <-handler.remote:
+			if addr.String() != "198.51.100.23:51000" {
+				t.Errorf("v%d: expected client address from header, got %s", version, addr)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("v%d: handler was not called", version)
+		}
+
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		buf := make([]byte, 4)
+		if n, _ := conn.Read(buf); string(buf[:n]) != "ping" {
+			t.Errorf("v%d: expected data after header to be delivered, got %q", version, buf[:n])
+		}
+		conn.Close()
+	}
+}
+
+func TestListenerIgnoresUntrustedSources(t *testing.T) {
+	_, trusted, _ := net.ParseCIDR("10.0.0.0/8")
+	tl, handler := startProxyListener(t, []*net.IPNet{trusted}, fixtures.NewMetricsRecorder())
+
+	conn, err := net.Dial("tcp", tl.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	select {
+	case addr := <-handler.remote:
+		if addr.String() != conn.LocalAddr().String() {
+			t.Errorf("Expected real peer address %s, got %s", conn.LocalAddr(), addr)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Handler was not called for untrusted source")
+	}
+}
+
+func TestListenerTrustsNoSourceByDefault(t *testing.T) {
+	tl, handler := startProxyListener(t, nil, fixtures.NewMetricsRecorder())
+
+	conn, err := net.Dial("tcp", tl.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+	header := &proxyproto.Header{
+		Version:     2,
+		Command:     proxyproto.CommandProxy,
+		Source:      &net.TCPAddr{IP: net.ParseIP("198.51.100.23"), Port: 51000},
+		Destination: tl.Addr(),
+	}
+	header.WriteTo(conn)
+
+	select {
+	case addr := <-handler.remote:
+		if addr.String() != conn.LocalAddr().String() {
+			t.Errorf("Expected real peer address %s, got spoofed %s", conn.LocalAddr(), addr)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Handler was not called")
+	}
+}
+
+func TestListenerRejectsMissingProxyHeader(t *testing.T) {
+	metrics := fixtures.NewMetricsRecorder()
+	tl, handler := startProxyListener(t, loopback(), metrics)
+
+	conn, err := net.Dial("tcp", tl.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+	conn.Write([]byte("GET / HTTP/1.1\r\n\r\n"))
+
+	waitForCount(t, metrics, "connection_errors:all:proxy_protocol_invalid", 1)
+
+	silent, err := net.Dial("tcp", tl.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer silent.Close()
+
+	waitForCount(t, metrics, "connection_errors:all:proxy_protocol_invalid", 2)
+
+	select {
+	case addr := <-handler.remote:
+		t.Errorf("Handler should not be called, got connection from %s", addr)
+	default:
+	}
+}
@@ -0,0 +1,123 @@
+package listener
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/listener"
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/ratelimit"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/proxyproto"
+	"github.com/reybrally/TCP-Load-Balancer/tests/fixtures"
+)
+
+func startRateLimitedListener(t *testing.T, limiter *ratelimit.Limiter, metrics *fixtures.MetricsRecorder, opts ...listener.Option) *listener.TCPListener {
+	t.Helper()
+
+	opts = append(opts, listener.WithRateLimit(limiter), listener.WithMetrics(metrics))
+	tl, err := listener.New("127.0.0.1", 0, logger.New("test"), opts...)
+	if err != nil {
+		t.Fatalf("Failed to create listener: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go tl.Listen(ctx, echoHandler{})
+	t.Cleanup(func() {
+		cancel()
+		tl.Close()
+	})
+	return tl
+}
+
+func expectClosed(t *testing.T, conn net.Conn) {
+	t.Helper()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("Expected connection to be closed, got %v", err)
+	}
+}
+
+func TestRateLimitCapsConnectionsPerIP(t *testing.T) {
+	metrics := fixtures.NewMetricsRecorder()
+	limiter := ratelimit.New(ratelimit.Config{MaxConnectionsPerIP: 1})
+	tl := startRateLimitedListener(t, limiter, metrics)
+
+	first, err := net.Dial("tcp", tl.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	echo(t, first, "first")
+
+	second, err := net.Dial("tcp", tl.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer second.Close()
+	expectClosed(t, second)
+
+	if got := metrics.Count("rejected:" + ratelimit.ReasonIPConcurrency); got != 1 {
+		t.Errorf("Expected 1 rejection, got %d", got)
+	}
+
+	first.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		third, err := net.Dial("tcp", tl.Addr().String())
+		if err != nil {
+			t.Fatalf("Failed to connect: %v", err)
+		}
+		third.SetDeadline(time.Now().Add(200 * time.Millisecond))
+		third.Write([]byte("third\n"))
+		_, err = third.Read(make([]byte, 1))
+		third.Close()
+		if err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected a new connection to be served once the first closed")
+		}
+	}
+}
+
+func TestRateLimitUsesProxyProtocolSource(t *testing.T) {
+	metrics := fixtures.NewMetricsRecorder()
+	limiter := ratelimit.New(ratelimit.Config{Rate: 1, Burst: 1})
+	tl := startRateLimitedListener(t, limiter, metrics, listener.WithProxyProtocol(loopback(), time.Second))
+
+	dial := func(client string) net.Conn {
+		conn, err := net.Dial("tcp", tl.Addr().String())
+		if err != nil {
+			t.Fatalf("Failed to connect: %v", err)
+		}
+		header := &proxyproto.Header{
+			Version:     2,
+			Command:     proxyproto.CommandProxy,
+			Source:      &net.TCPAddr{IP: net.ParseIP(client), Port: 50000},
+			Destination: tl.Addr(),
+		}
+		if _, err := header.WriteTo(conn); err != nil {
+			t.Fatalf("Failed to write PROXY header: %v", err)
+		}
+		return conn
+	}
+
+	first := dial("198.51.100.1")
+	defer first.Close()
+	echo(t, first, "first")
+
+	other := dial("198.51.100.2")
+	defer other.Close()
+	echo(t, other, "other")
+
+	again := dial("198.51.100.1")
+	defer again.Close()
+	expectClosed(t, again)
+
+	if got := metrics.Count("rejected:" + ratelimit.ReasonIPRate); got != 1 {
+		t.Errorf("Expected 1 rejection, got %d", got)
+	}
+}
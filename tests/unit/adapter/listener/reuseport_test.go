@@ -0,0 +1,121 @@
+//go:build linux
+
+package listener
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/listener"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+)
+
+func startReusePortListener(tb testing.TB, handler interface {
+	Handle(context.Context, net.Conn) error
+}, opts ...listener.Option) *listener.TCPListener {
+	tb.Helper()
+
+	tl, err := listener.New("127.0.0.1", 0, logger.New("test"), opts...)
+	if err != nil {
+		tb.Fatalf("Failed to create listener: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		tl.Listen(ctx, handler)
+		close(done)
+	}()
+	tb.Cleanup(func() {
+		cancel()
+		tl.Close()
+		<-done
+	})
+
+	return tl
+}
+
+func TestReusePortAcceptors(t *testing.T) {
+	tl := startReusePortListener(t, echoHandler{}, listener.WithReusePort(4), listener.WithBacklog(128))
+
+	if tl.Addr().(*net.TCPAddr).Port == 0 {
+		t.Fatal("Expected a bound port")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn, err := net.Dial("tcp", tl.Addr().String())
+			if err != nil {
+				t.Errorf("Failed to connect: %v", err)
+				return
+			}
+			defer conn.Close()
+			echo(t, conn, fmt.Sprintf("client-%d", i))
+		}()
+	}
+	wg.Wait()
+}
+
+func TestReusePortListenStopsAllAcceptors(t *testing.T) {
+	tl, err := listener.New("127.0.0.1", 0, logger.New("test"), listener.WithReusePort(3))
+	if err != nil {
+		t.Fatalf("Failed to create listener: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- tl.Listen(ctx, echoHandler{}) }()
+
+	cancel()
+	tl.Close()
+	if err := <-done; err != nil && err != context.Canceled {
+		t.Errorf("Unexpected Listen error: %v", err)
+	}
+
+	if _, err := net.Dial("tcp", tl.Addr().String()); err == nil {
+		t.Error("Expected all sockets to be closed")
+	}
+}
+
+type closeHandler struct{}
+
+func (closeHandler) Handle(ctx context.Context, conn net.Conn) error {
+	return conn.Close()
+}
+
+// BenchmarkAcceptStorm measures how fast parallel clients get a connection
+// accepted and served with one accept loop versus several SO_REUSEPORT
+// sockets. Run with -cpu to vary the number of dialing goroutines.
+func BenchmarkAcceptStorm(b *testing.B) {
+	for _, acceptors := range []int{1, 4, 8} {
+		b.Run(fmt.Sprintf("acceptors=%d", acceptors), func(b *testing.B) {
+			opts := []listener.Option{listener.WithBacklog(4096)}
+			if acceptors > 1 {
+				opts = append(opts, listener.WithReusePort(acceptors))
+			}
+			tl := startReusePortListener(b, closeHandler{}, opts...)
+			addr := tl.Addr().String()
+
+			b.SetParallelism(8)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				buf := make([]byte, 1)
+				for pb.Next() {
+					conn, err := net.Dial("tcp", addr)
+					if err != nil {
+						b.Error(err)
+						return
+					}
+					conn.Read(buf)
+					conn.Close()
+				}
+			})
+		})
+	}
+}
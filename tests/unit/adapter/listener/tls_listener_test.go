@@ -0,0 +1,227 @@
+package listener
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/listener"
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/tlsutil"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+	"github.com/reybrally/TCP-Load-Balancer/tests/fixtures"
+)
+
+type echoHandler struct{}
+
+func (echoHandler) Handle(ctx context.Context, conn net.Conn) error {
+	defer conn.Close()
+	_, err := io.Copy(conn, conn)
+	return err
+}
+
+func startTLSListener(t *testing.T, opts tlsutil.ServerOptions, metrics *fixtures.MetricsRecorder) (*listener.TCPListener, *tlsutil.ServerReloader) {
+	t.Helper()
+
+	log := logger.New("test")
+	reloader, err := tlsutil.NewServerReloader(opts, log)
+	if err != nil {
+		t.Fatalf("Failed to create reloader: %v", err)
+	}
+
+	tl, err := listener.New("127.0.0.1", 0, log,
+		listener.WithMetrics(metrics),
+		listener.WithTLS(reloader.Config(), time.Second),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create listener: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go tl.Listen(ctx, echoHandler{})
+	t.Cleanup(func() {
+		cancel()
+		tl.Close()
+	})
+
+	return tl, reloader
+}
+
+func echo(t *testing.T, conn net.Conn, msg string) {
+	t.Helper()
+
+	conn.SetDeadline(time.Now().Add(2 * time.Second))
+	if _, err := conn.Write([]byte(msg + "\n")); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if line != msg+"\n" {
+		t.Errorf("Expected echo %q, got %q", msg, line)
+	}
+}
+
+func TestTLSTermination(t *testing.T) {
+	ca := fixtures.NewTestCA(t)
+	certFile, keyFile := ca.IssueFiles(t, t.TempDir(), "lb.test", "lb.test")
+
+	tl, _ := startTLSListener(t, tlsutil.ServerOptions{
+		CertFile:   certFile,
+		KeyFile:    keyFile,
+		MinVersion: "1.2",
+	}, fixtures.NewMetricsRecorder())
+
+	conn, err := tls.Dial("tcp", tl.Addr().String(), &tls.Config{
+		RootCAs:    ca.Pool(),
+		ServerName: "lb.test",
+	})
+	if err != nil {
+		t.Fatalf("TLS dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	echo(t, conn, "hello")
+}
+
+func TestTLSCertificateHotReload(t *testing.T) {
+	ca := fixtures.NewTestCA(t)
+	dir := t.TempDir()
+	certFile, keyFile := ca.IssueFiles(t, dir, "old.test", "old.test")
+
+	tl, reloader := startTLSListener(t, tlsutil.ServerOptions{
+		CertFile: certFile,
+		KeyFile:  keyFile,
+	}, fixtures.NewMetricsRecorder())
+
+	clientCfg := &tls.Config{RootCAs: ca.Pool(), InsecureSkipVerify: true}
+
+	oldConn, err := tls.Dial("tcp", tl.Addr().String(), clientCfg)
+	if err != nil {
+		t.Fatalf("TLS dial failed: %v", err)
+	}
+	defer oldConn.Close()
+	if cn := oldConn.ConnectionState().PeerCertificates[0].Subject.CommonName; cn != "old.test" {
+		t.Fatalf("Expected old.test certificate, got %s", cn)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reloader.Watch(ctx, 10*time.Millisecond)
+
+	ca.IssueFiles(t, dir, "new.test", "new.test")
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		conn, err := tls.Dial("tcp", tl.Addr().String(), clientCfg)
+		if err != nil {
+			t.Fatalf("TLS dial failed: %v", err)
+		}
+		cn := conn.ConnectionState().PeerCertificates[0].Subject.CommonName
+		conn.Close()
+		if cn == "new.test" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Certificate was not reloaded, still serving %s", cn)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	echo(t, oldConn, "still alive")
+}
+
+func TestTLSMutualAuthentication(t *testing.T) {
+	ca := fixtures.NewTestCA(t)
+	dir := t.TempDir()
+	certFile, keyFile := ca.IssueFiles(t, dir, "lb.test", "lb.test")
+
+	metrics := fixtures.NewMetricsRecorder()
+	tl, _ := startTLSListener(t, tlsutil.ServerOptions{
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+		ClientAuth:   "require_and_verify",
+		ClientCAFile: ca.WriteCA(t, dir),
+	}, metrics)
+
+	conn, err := tls.Dial("tcp", tl.Addr().String(), &tls.Config{
+		RootCAs:      ca.Pool(),
+		ServerName:   "lb.test",
+		Certificates: []tls.Certificate{ca.IssueTLS(t, "client")},
+	})
+	if err != nil {
+		t.Fatalf("mTLS dial failed: %v", err)
+	}
+	echo(t, conn, "authenticated")
+	conn.Close()
+
+	anon, err := tls.Dial("tcp", tl.Addr().String(), &tls.Config{
+		RootCAs:    ca.Pool(),
+		ServerName: "lb.test",
+	})
+	if err == nil {
+		// With TLS 1.3 the client learns about the rejection on first read.
+		anon.SetDeadline(time.Now().Add(time.Second))
+		anon.Write([]byte("x"))
+		_, err = anon.Read(make([]byte, 1))
+		anon.Close()
+	}
+	if err == nil {
+		t.Fatal("Expected connection without client certificate to be rejected")
+	}
+
+	waitForCount(t, metrics, "tls_handshake_errors:no_client_certificate", 1)
+}
+
+func TestTLSHandshakeFailureReasons(t *testing.T) {
+	ca := fixtures.NewTestCA(t)
+	certFile, keyFile := ca.IssueFiles(t, t.TempDir(), "lb.test", "lb.test")
+
+	metrics := fixtures.NewMetricsRecorder()
+	tl, _ := startTLSListener(t, tlsutil.ServerOptions{
+		CertFile:   certFile,
+		KeyFile:    keyFile,
+		MinVersion: "1.3",
+	}, metrics)
+
+	plain, err := net.Dial("tcp", tl.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	plain.Write([]byte("GET / HTTP/1.1\r\n\r\n"))
+	plain.Close()
+	waitForCount(t, metrics, "tls_handshake_errors:not_tls", 1)
+
+	old, err := tls.Dial("tcp", tl.Addr().String(), &tls.Config{
+		InsecureSkipVerify: true,
+		MaxVersion:         tls.VersionTLS12,
+	})
+	if err == nil {
+		old.Close()
+		t.Fatal("Expected TLS 1.2 client to be rejected")
+	}
+	waitForCount(t, metrics, "tls_handshake_errors:protocol_version", 1)
+
+	silent, err := net.Dial("tcp", tl.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer silent.Close()
+	waitForCount(t, metrics, "tls_handshake_errors:timeout", 1)
+}
+
+func waitForCount(t *testing.T, metrics *fixtures.MetricsRecorder, key string, expected int) {
+	t.Helper()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for metrics.Count(key) < expected {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %s to reach %d, got %d", key, expected, metrics.Count(key))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
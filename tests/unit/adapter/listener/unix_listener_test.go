@@ -0,0 +1,128 @@
+package listener
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/listener"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+)
+
+func startUnixListener(t *testing.T, socket listener.UnixSocket) *listener.TCPListener {
+	t.Helper()
+
+	ul, err := listener.NewUnix(socket, logger.New("test"))
+	if err != nil {
+		t.Fatalf("Failed to create unix listener: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go ul.Listen(ctx, echoHandler{})
+	t.Cleanup(func() {
+		cancel()
+		ul.Close()
+	})
+
+	return ul
+}
+
+func TestUnixListener(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lb.sock")
+	startUnixListener(t, listener.UnixSocket{Path: path, Mode: 0o600})
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Socket file missing: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected mode 0600, got %v", info.Mode().Perm())
+	}
+
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	echo(t, conn, "hello over unix")
+}
+
+func TestUnixListenerRemovesStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lb.sock")
+
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("Failed to create stale socket: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	startUnixListener(t, listener.UnixSocket{Path: path})
+
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("Failed to connect after stale socket cleanup: %v", err)
+	}
+	defer conn.Close()
+
+	echo(t, conn, "fresh")
+}
+
+func TestUnixListenerRefusesLiveOrForeignPaths(t *testing.T) {
+	dir := t.TempDir()
+
+	live := filepath.Join(dir, "live.sock")
+	startUnixListener(t, listener.UnixSocket{Path: live})
+	if _, err := listener.NewUnix(listener.UnixSocket{Path: live}, logger.New("test")); err == nil {
+		t.Error("Expected error for a socket still in use")
+	}
+
+	regular := filepath.Join(dir, "file")
+	os.WriteFile(regular, []byte("data"), 0o644)
+	if _, err := listener.NewUnix(listener.UnixSocket{Path: regular}, logger.New("test")); err == nil {
+		t.Error("Expected error for a path that is not a socket")
+	}
+	if _, err := os.Stat(regular); err != nil {
+		t.Errorf("Regular file should not be removed: %v", err)
+	}
+}
+
+func TestUnixListenerUnknownOwner(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lb.sock")
+	_, err := listener.NewUnix(listener.UnixSocket{Path: path, User: "no-such-user-lb"}, logger.New("test"))
+	if err == nil {
+		t.Fatal("Expected error for unknown socket owner")
+	}
+	if _, statErr := os.Stat(path); statErr == nil {
+		t.Error("Expected socket to be removed after failed setup")
+	}
+}
+
+func TestUnixListenerKeepsSocketOnlyAfterHandOver(t *testing.T) {
+	for _, handedOver := range []bool{false, true} {
+		path := filepath.Join(t.TempDir(), "lb.sock")
+		ul, err := listener.NewUnix(listener.UnixSocket{Path: path}, logger.New("test"))
+		if err != nil {
+			t.Fatalf("Failed to create unix listener: %v", err)
+		}
+
+		files, err := ul.Files()
+		if err != nil {
+			t.Fatalf("Files failed: %v", err)
+		}
+		for _, file := range files {
+			file.Close()
+		}
+		if handedOver {
+			ul.HandedOver()
+		}
+		ul.Close()
+
+		if _, err := os.Stat(path); (err == nil) != handedOver {
+			t.Errorf("Handed over %v: expected socket file kept %v, got stat error %v", handedOver, handedOver, err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	prommetrics "github.com/reybrally/TCP-Load-Balancer/internal/adapter/metrics"
+)
+
+// series counts the gathered series of name that carry backend.
+func series(t *testing.T, reg *prometheus.Registry, name, backend string) int {
+	t.Helper()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather failed: %v", err)
+	}
+	count := 0
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "backend" && label.GetValue() == backend {
+					count++
+				}
+			}
+		}
+	}
+	return count
+}
+
+func TestSeparateRegistries(t *testing.T) {
+	first := prometheus.NewRegistry()
+	second := prometheus.NewRegistry()
+	m1 := prommetrics.NewPrometheusMetrics(first)
+	prommetrics.NewPrometheusMetrics(second)
+
+	m1.IncConnectionsTotal("10.0.0.1:80")
+
+	if got := series(t, first, "tcp_lb_connections_total", "10.0.0.1:80"); got != 1 {
+		t.Errorf("Expected 1 series in the first registry, got %d", got)
+	}
+	if got := series(t, second, "tcp_lb_connections_total", "10.0.0.1:80"); got != 0 {
+		t.Errorf("Expected no series in the second registry, got %d", got)
+	}
+}
+
+func TestRemoveBackend(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := prommetrics.NewPrometheusMetrics(reg)
+	web := m.ForFrontend("web")
+	api := m.ForFrontend("api")
+
+	for _, backend := range []string{"10.0.0.1:80", "10.0.0.2:80"} {
+		web.IncConnectionsTotal(backend)
+		api.IncConnectionsTotal(backend)
+		web.SetBackendHealthStatus(backend, true)
+		web.AddBytesSent(backend, 10)
+		web.ObserveDialLatency(backend, 0.01)
+	}
+	web.IncRejectedConnections("acl_denied")
+
+	m.RemoveBackend("10.0.0.1:80")
+
+	for _, name := range []string{
+		"tcp_lb_connections_total",
+		"tcp_lb_backend_healthy",
+		"tcp_lb_bytes_sent_total",
+		"tcp_lb_backend_dial_duration_seconds",
+	} {
+		if got := series(t, reg, name, "10.0.0.1:80"); got != 0 {
+			t.Errorf("Expected %s series of the removed backend to be gone, got %d", name, got)
+		}
+		if got := series(t, reg, name, "10.0.0.2:80"); got == 0 {
+			t.Errorf("Expected %s series of the remaining backend to stay", name)
+		}
+	}
+	if got := series(t, reg, "tcp_lb_connections_total", "10.0.0.2:80"); got != 2 {
+		t.Errorf("Expected both frontends' series of the remaining backend, got %d", got)
+	}
+}
@@ -0,0 +1,22 @@
+package metrics
+
+import (
+	"testing"
+
+	prommetrics "github.com/reybrally/TCP-Load-Balancer/internal/adapter/metrics"
+	"github.com/reybrally/TCP-Load-Balancer/tests/fixtures"
+)
+
+func TestTeeReportsToAll(t *testing.T) {
+	first, second := fixtures.NewMetricsRecorder(), fixtures.NewMetricsRecorder()
+	tee := prommetrics.Tee(first, second)
+
+	tee.IncConnectionsTotal("10.0.0.1:80")
+	tee.IncRejectedConnections("acl_denied")
+
+	for _, recorder := range []*fixtures.MetricsRecorder{first, second} {
+		if recorder.Count("connections_total:10.0.0.1:80") != 1 || recorder.Count("rejected:acl_denied") != 1 {
+			t.Error("Expected every collector to see each call")
+		}
+	}
+}
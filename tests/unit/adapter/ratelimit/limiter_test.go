@@ -0,0 +1,163 @@
+package ratelimit
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/ratelimit"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func addr(ip string) net.Addr {
+	return &net.TCPAddr{IP: net.ParseIP(ip), Port: 40000}
+}
+
+func allow(l *ratelimit.Limiter, ip string) string {
+	_, reason := l.Allow(addr(ip))
+	return reason
+}
+
+func TestPerIPRate(t *testing.T) {
+	c := &clock{now: time.Unix(0, 0)}
+	l := ratelimit.New(ratelimit.Config{Rate: 2, Burst: 2}, ratelimit.WithClock(c.Now))
+
+	for i := 0; i < 2; i++ {
+		if reason := allow(l, "10.0.0.1"); reason != "" {
+			t.Fatalf("Expected connection %d within burst, got %s", i, reason)
+		}
+	}
+	if reason := allow(l, "10.0.0.1"); reason != ratelimit.ReasonIPRate {
+		t.Fatalf("Expected %s after burst, got %q", ratelimit.ReasonIPRate, reason)
+	}
+	if reason := allow(l, "10.0.0.2"); reason != "" {
+		t.Fatalf("Expected another IP to be unaffected, got %s", reason)
+	}
+
+	c.advance(500 * time.Millisecond)
+	if reason := allow(l, "10.0.0.1"); reason != "" {
+		t.Fatalf("Expected a token after refill, got %s", reason)
+	}
+	if reason := allow(l, "10.0.0.1"); reason != ratelimit.ReasonIPRate {
+		t.Fatalf("Expected a single refilled token, got %q", reason)
+	}
+}
+
+func TestPerPrefixRate(t *testing.T) {
+	c := &clock{now: time.Unix(0, 0)}
+	l := ratelimit.New(ratelimit.Config{PrefixRate: 1, PrefixBurst: 2}, ratelimit.WithClock(c.Now))
+
+	if reason := allow(l, "192.0.2.1"); reason != "" {
+		t.Fatalf("Expected first connection allowed, got %s", reason)
+	}
+	if reason := allow(l, "192.0.2.200"); reason != "" {
+		t.Fatalf("Expected second connection allowed, got %s", reason)
+	}
+	if reason := allow(l, "192.0.2.77"); reason != ratelimit.ReasonPrefixRate {
+		t.Fatalf("Expected the /24 to be limited, got %q", reason)
+	}
+	if reason := allow(l, "192.0.3.1"); reason != "" {
+		t.Fatalf("Expected another /24 to be unaffected, got %s", reason)
+	}
+
+	if reason := allow(l, "2001:db8:0:1::1"); reason != "" {
+		t.Fatalf("Expected IPv6 connection allowed, got %s", reason)
+	}
+	if reason := allow(l, "2001:db8:0:1:ffff::2"); reason != "" {
+		t.Fatalf("Expected IPv6 connection allowed, got %s", reason)
+	}
+	if reason := allow(l, "2001:db8:0:1::3"); reason != ratelimit.ReasonPrefixRate {
+		t.Fatalf("Expected the /64 to be limited, got %q", reason)
+	}
+}
+
+func TestRejectedConnectionTakesNoToken(t *testing.T) {
+	c := &clock{now: time.Unix(0, 0)}
+	l := ratelimit.New(ratelimit.Config{Rate: 1, Burst: 2, PrefixRate: 1, PrefixBurst: 1}, ratelimit.WithClock(c.Now))
+
+	if reason := allow(l, "10.0.0.1"); reason != "" {
+		t.Fatalf("Expected first connection allowed, got %s", reason)
+	}
+	if reason := allow(l, "10.0.0.1"); reason != ratelimit.ReasonPrefixRate {
+		t.Fatalf("Expected prefix limit, got %q", reason)
+	}
+
+	// The IP bucket still holds the token the refused connection didn't use.
+	c.advance(time.Second)
+	if reason := allow(l, "10.0.0.1"); reason != "" {
+		t.Fatalf("Expected connection allowed, got %s", reason)
+	}
+	if reason := allow(l, "10.0.0.1"); reason != ratelimit.ReasonPrefixRate {
+		t.Fatalf("Expected prefix limit, got %q", reason)
+	}
+}
+
+func TestMaxConnectionsPerIP(t *testing.T) {
+	l := ratelimit.New(ratelimit.Config{MaxConnectionsPerIP: 2})
+
+	first, reason := l.Allow(addr("10.0.0.1"))
+	if reason != "" {
+		t.Fatalf("Expected first connection allowed, got %s", reason)
+	}
+	second, _ := l.Allow(addr("::ffff:10.0.0.1"))
+	if reason := allow(l, "10.0.0.1"); reason != ratelimit.ReasonIPConcurrency {
+		t.Fatalf("Expected %s, got %q", ratelimit.ReasonIPConcurrency, reason)
+	}
+
+	first()
+	first()
+	if _, reason := l.Allow(addr("10.0.0.1")); reason != "" {
+		t.Fatalf("Expected a slot after release, got %s", reason)
+	}
+	if reason := allow(l, "10.0.0.1"); reason != ratelimit.ReasonIPConcurrency {
+		t.Fatalf("Expected a double release to free one slot only, got %q", reason)
+	}
+	second()
+}
+
+func TestTrackedSourcesAreBounded(t *testing.T) {
+	c := &clock{now: time.Unix(0, 0)}
+	l := ratelimit.New(ratelimit.Config{Rate: 1, Burst: 1, PrefixRate: 100, MaxTracked: 3}, ratelimit.WithClock(c.Now))
+
+	allow(l, "10.0.0.1")
+	for _, ip := range []string{"10.0.1.1", "10.0.2.1", "10.0.3.1"} {
+		if reason := allow(l, ip); reason != "" {
+			t.Fatalf("Expected %s allowed, got %s", ip, reason)
+		}
+	}
+
+	ips, prefixes := l.Tracked()
+	if ips != 3 || prefixes != 3 {
+		t.Fatalf("Expected 3 tracked IPs and prefixes, got %d and %d", ips, prefixes)
+	}
+
+	// 10.0.0.1 was the least recently seen, so its empty bucket was evicted.
+	if reason := allow(l, "10.0.0.1"); reason != "" {
+		t.Fatalf("Expected evicted IP to start with a full bucket, got %s", reason)
+	}
+	if reason := allow(l, "10.0.3.1"); reason != ratelimit.ReasonIPRate {
+		t.Fatalf("Expected recent IP to stay limited, got %q", reason)
+	}
+}
+
+func TestNonIPAddressesAreNotLimited(t *testing.T) {
+	l := ratelimit.New(ratelimit.Config{Rate: 1, Burst: 1, MaxConnectionsPerIP: 1})
+
+	unix := &net.UnixAddr{Name: "@", Net: "unix"}
+	for i := 0; i < 3; i++ {
+		if _, reason := l.Allow(unix); reason != "" {
+			t.Fatalf("Expected unix client allowed, got %s", reason)
+		}
+	}
+}
@@ -1,8 +1,10 @@
 package model
 
 import (
+	"context"
 	"testing"
 
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/repository"
 	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
 )
 
@@ -23,23 +25,52 @@ func TestBackendCreation(t *testing.T) {
 }
 
 func TestBackendHealthStatus(t *testing.T) {
-	backend := model.NewBackend("test", "localhost", 3001, 1)
+	ctx := context.Background()
+	repo := repository.New()
+	repo.Add(ctx, model.NewBackend("test", "localhost", 3001, 1))
 
-	if !backend.GetHealthy() {
+	if len(repo.GetHealthy(ctx)) != 1 {
 		t.Error("Expected backend to be healthy by default")
 	}
 
-	backend.SetHealthy(false)
-	if backend.GetHealthy() {
+	repo.SetHealthy(ctx, "test", false)
+	if len(repo.GetHealthy(ctx)) != 0 {
 		t.Error("Expected backend to be unhealthy after SetHealthy(false)")
 	}
 
-	backend.SetHealthy(true)
-	if !backend.GetHealthy() {
+	repo.SetHealthy(ctx, "test", true)
+	if len(repo.GetHealthy(ctx)) != 1 {
 		t.Error("Expected backend to be healthy after SetHealthy(true)")
 	}
 }
 
+func TestBackendAdminState(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.New()
+	repo.Add(ctx, model.NewBackend("test", "localhost", 3001, 1))
+
+	if state := repo.AdminState(ctx, "test"); state != model.AdminEnabled {
+		t.Errorf("Expected backend to be enabled by default, got %s", state)
+	}
+
+	repo.SetAdminState(ctx, "test", model.AdminDrain)
+	if len(repo.GetHealthy(ctx)) != 0 {
+		t.Error("Expected a draining backend to get no new connections")
+	}
+
+	repo.SetAdminState(ctx, "test", model.AdminEnabled)
+	if len(repo.GetHealthy(ctx)) != 1 {
+		t.Error("Expected backend to get connections again once enabled")
+	}
+
+	if err := repo.SetAdminState(ctx, "test", "paused"); err == nil {
+		t.Error("Expected an unknown admin state to fail")
+	}
+	if err := repo.SetAdminState(ctx, "missing", model.AdminDisabled); err == nil {
+		t.Error("Expected an unknown backend to fail")
+	}
+}
+
 func TestConnectionCount(t *testing.T) {
 	backend := model.NewBackend("test", "localhost", 3001, 1)
 
@@ -0,0 +1,180 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/repository"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+)
+
+func TestFileRepoSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "web.json")
+
+	repo, err := repository.OpenFile(path)
+	if err != nil {
+		t.Fatalf("Failed to open state file: %v", err)
+	}
+	if repo.Loaded() {
+		t.Error("Expected a missing state file not to count as loaded")
+	}
+
+	web := model.NewBackend("web-1", "10.0.0.1", 80, 3)
+	web.MaxConnections = 100
+	repo.Add(ctx, web)
+	repo.Add(ctx, model.NewUnixBackend("web-2", "/run/app.sock", 1))
+	repo.Add(ctx, model.NewBackend("web-3", "10.0.0.3", 80, 1))
+	repo.Remove(ctx, "web-3")
+	repo.Update(ctx, model.NewBackend("web-1", "10.0.0.1", 8080, 5))
+	repo.SetHealthy(ctx, "web-2", false)
+
+	restarted, err := repository.OpenFile(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen state file: %v", err)
+	}
+	if !restarted.Loaded() {
+		t.Error("Expected existing state file to count as loaded")
+	}
+
+	backends := restarted.GetAll(ctx)
+	if len(backends) != 2 {
+		t.Fatalf("Expected 2 backends, got %d", len(backends))
+	}
+	if b := backends[0]; b.ID != "web-1" || b.GetAddress() != "10.0.0.1:8080" || b.Weight != 5 || b.MaxConnections != 0 {
+		t.Errorf("Unexpected first backend %+v", b)
+	}
+	if b := backends[1]; b.ID != "web-2" || b.GetNetwork() != model.NetworkUnix || b.GetAddress() != "/run/app.sock" {
+		t.Errorf("Unexpected second backend %+v", b)
+	}
+	if len(restarted.GetHealthy(ctx)) != 2 {
+		t.Error("Expected health not to be persisted")
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("Expected only the state file to be left, got %d entries", len(entries))
+	}
+}
+
+func TestFileRepoKeepsAdminState(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "web.json")
+
+	repo, err := repository.OpenFile(path)
+	if err != nil {
+		t.Fatalf("Failed to open state file: %v", err)
+	}
+	repo.Add(ctx, model.NewBackend("web-1", "10.0.0.1", 80, 1))
+	repo.Add(ctx, model.NewBackend("web-2", "10.0.0.2", 80, 1))
+	repo.Add(ctx, model.NewBackend("web-3", "10.0.0.3", 80, 1))
+	repo.SetAdminState(ctx, "web-1", model.AdminDrain)
+	repo.SetAdminState(ctx, "web-2", model.AdminDisabled)
+	repo.Update(ctx, model.NewBackend("web-1", "10.0.0.1", 8080, 1))
+
+	restarted, err := repository.OpenFile(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen state file: %v", err)
+	}
+
+	for id, want := range map[string]string{
+		"web-1": model.AdminDrain,
+		"web-2": model.AdminDisabled,
+		"web-3": model.AdminEnabled,
+	} {
+		if got := restarted.AdminState(ctx, id); got != want {
+			t.Errorf("Expected %s to be %s after a restart, got %s", id, want, got)
+		}
+	}
+	healthy := restarted.GetHealthy(ctx)
+	if len(healthy) != 1 || healthy[0].ID != "web-3" {
+		t.Errorf("Expected only web-3 to get connections, got %d backends", len(healthy))
+	}
+}
+
+func TestFileRepoDoesNotSaveRejectedChanges(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "web.json")
+
+	repo, err := repository.OpenFile(path)
+	if err != nil {
+		t.Fatalf("Failed to open state file: %v", err)
+	}
+	if err := repo.Add(ctx, model.NewBackend("web-1", "10.0.0.1", 80, 1)); err != nil {
+		t.Fatalf("Failed to add backend: %v", err)
+	}
+	saved, _ := os.ReadFile(path)
+
+	if err := repo.Add(ctx, model.NewBackend("web-1", "10.0.0.9", 80, 1)); err == nil {
+		t.Error("Expected a duplicate ID to fail")
+	}
+	if err := repo.Update(ctx, model.NewBackend("web-2", "10.0.0.2", 80, 1)); !errors.Is(err, model.ErrBackendNotFound) {
+		t.Errorf("Expected updating an unknown backend to fail with ErrBackendNotFound, got %v", err)
+	}
+	if err := repo.Remove(ctx, "web-2"); !errors.Is(err, model.ErrBackendNotFound) {
+		t.Errorf("Expected removing an unknown backend to fail with ErrBackendNotFound, got %v", err)
+	}
+	if err := repo.SetAdminState(ctx, "web-1", "paused"); err == nil {
+		t.Error("Expected an unknown admin state to fail")
+	}
+
+	if data, _ := os.ReadFile(path); string(data) != string(saved) {
+		t.Errorf("Expected rejected changes to leave the state file alone, got:\n%s", data)
+	}
+}
+
+func TestFileRepoRejectsCorruptState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "web.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatalf("Failed to write state file: %v", err)
+	}
+	if _, err := repository.OpenFile(path); err == nil {
+		t.Error("Expected a corrupt state file to fail")
+	}
+}
+
+func TestFileRepoReportsSaveFailures(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "state")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatalf("Failed to create state directory: %v", err)
+	}
+	repo, err := repository.OpenFile(filepath.Join(dir, "web.json"))
+	if err != nil {
+		t.Fatalf("Failed to open state file: %v", err)
+	}
+	if err := repo.Add(ctx, model.NewBackend("web-1", "10.0.0.1", 80, 1)); err != nil {
+		t.Fatalf("Failed to add backend: %v", err)
+	}
+
+	// Without its directory the state file can no longer be written.
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatalf("Failed to remove state directory: %v", err)
+	}
+	if err := repo.Add(ctx, model.NewBackend("web-2", "10.0.0.2", 80, 1)); err == nil {
+		t.Error("Expected an unwritable state file to fail the add")
+	}
+	if err := repo.Update(ctx, model.NewBackend("web-1", "10.0.0.1", 8080, 1)); err == nil {
+		t.Error("Expected an unwritable state file to fail the update")
+	}
+	if err := repo.SetAdminState(ctx, "web-1", model.AdminDisabled); err == nil {
+		t.Error("Expected an unwritable state file to fail the admin change")
+	}
+	if err := repo.Remove(ctx, "web-1"); err == nil {
+		t.Error("Expected an unwritable state file to fail the remove")
+	}
+
+	backends := repo.GetAll(ctx)
+	if len(backends) != 1 || backends[0].GetAddress() != "10.0.0.1:80" {
+		t.Errorf("Expected failed saves to leave the backends unchanged, got %d backends", len(backends))
+	}
+	if state := repo.AdminState(ctx, "web-1"); state != model.AdminEnabled {
+		t.Errorf("Expected failed saves to leave the admin state unchanged, got %s", state)
+	}
+	if repo.Version() != 1 {
+		t.Errorf("Expected failed saves not to publish changes, got version %d", repo.Version())
+	}
+}
@@ -0,0 +1,172 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/repository"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+)
+
+func ids(backends []*model.Backend) string {
+	var s string
+	for _, b := range backends {
+		s += b.ID + " "
+	}
+	return s
+}
+
+func TestSnapshotsKeepInsertionOrder(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.New()
+	for _, id := range []string{"c", "a", "d", "b"} {
+		repo.Add(ctx, model.NewBackend(id, "10.0.0.1", 80, 1))
+	}
+
+	before := repo.GetAll(ctx)
+	repo.Remove(ctx, "a")
+	repo.SetHealthy(ctx, "d", false)
+	repo.Update(ctx, model.NewBackend("c", "10.0.0.3", 80, 1))
+
+	if got := ids(repo.GetAll(ctx)); got != "c d b " {
+		t.Errorf("Expected c d b, got %s", got)
+	}
+	if got := ids(repo.GetHealthy(ctx)); got != "c b " {
+		t.Errorf("Expected healthy c b, got %s", got)
+	}
+	if got := ids(before); got != "c a d b " || before[0].Address != "10.0.0.1" {
+		t.Errorf("Expected earlier snapshot to be unchanged, got %s", got)
+	}
+	if v := repo.Version(); v != 7 {
+		t.Errorf("Expected version 7, got %d", v)
+	}
+}
+
+func TestGetHealthyDoesNotAllocate(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.New()
+	for i := 0; i < 8; i++ {
+		repo.Add(ctx, model.NewBackend(fmt.Sprint(i), "10.0.0.1", 8000+i, 1))
+	}
+	repo.SetHealthy(ctx, "3", false)
+
+	if allocs := testing.AllocsPerRun(100, func() { repo.GetHealthy(ctx) }); allocs != 0 {
+		t.Errorf("Expected no allocations, got %v", allocs)
+	}
+}
+
+// mutexRepo is the map-and-RWMutex design the snapshots replaced, kept as a
+// baseline for the benchmarks.
+// Health sat behind a lock on each backend then.
+type mutexRepo struct {
+	mu       sync.RWMutex
+	backends map[string]*mutexBackend
+}
+
+type mutexBackend struct {
+	*model.Backend
+	mu      sync.RWMutex
+	healthy bool
+}
+
+func (b *mutexBackend) getHealthy() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.healthy
+}
+
+func (r *mutexRepo) GetHealthy(ctx context.Context) []*model.Backend {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	backends := make([]*model.Backend, 0)
+	for _, backend := range r.backends {
+		if backend.getHealthy() {
+			backends = append(backends, backend.Backend)
+		}
+	}
+	return backends
+}
+
+func (r *mutexRepo) SetHealthy(ctx context.Context, id string, healthy bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b := r.backends[id]
+	b.mu.Lock()
+	b.healthy = healthy
+	b.mu.Unlock()
+	return nil
+}
+
+type healthRepo interface {
+	GetHealthy(ctx context.Context) []*model.Backend
+	SetHealthy(ctx context.Context, id string, healthy bool) error
+}
+
+type namedRepo struct {
+	name string
+	repo healthRepo
+}
+
+func benchmarkRepos(n int) []namedRepo {
+	ctx := context.Background()
+	snapshots := repository.New()
+	mutex := &mutexRepo{backends: make(map[string]*mutexBackend)}
+	for i := 0; i < n; i++ {
+		id := fmt.Sprint(i)
+		snapshots.Add(ctx, model.NewBackend(id, "10.0.0.1", 8000+i, 1))
+		mutex.backends[id] = &mutexBackend{Backend: model.NewBackend(id, "10.0.0.1", 8000+i, 1), healthy: true}
+	}
+	return []namedRepo{{"snapshot", snapshots}, {"mutex", mutex}}
+}
+
+// BenchmarkGetHealthy reads the healthy set from every P at once, as
+// concurrent accepts do.
+func BenchmarkGetHealthy(b *testing.B) {
+	for _, n := range []int{4, 64} {
+		for _, r := range benchmarkRepos(n) {
+			repo := r.repo
+			b.Run(fmt.Sprintf("%s/backends=%d", r.name, n), func(b *testing.B) {
+				ctx := context.Background()
+				b.ReportAllocs()
+				b.RunParallel(func(pb *testing.PB) {
+					for pb.Next() {
+						if len(repo.GetHealthy(ctx)) != n {
+							b.Fatal("Expected every backend to be healthy")
+						}
+					}
+				})
+			})
+		}
+	}
+}
+
+// BenchmarkGetHealthyWithFlapping adds a writer flipping one backend's
+// health as fast as it can.
+func BenchmarkGetHealthyWithFlapping(b *testing.B) {
+	for _, r := range benchmarkRepos(16) {
+		repo := r.repo
+		b.Run(r.name, func(b *testing.B) {
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for healthy := false; ctx.Err() == nil; healthy = !healthy {
+					repo.SetHealthy(ctx, "0", healthy)
+				}
+			}()
+
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					repo.GetHealthy(ctx)
+				}
+			})
+			b.StopTimer()
+			cancel()
+			<-done
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/repository"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+)
+
+func next(t *testing.T, changes <-chan model.BackendChange) model.BackendChange {
+	t.Helper()
+
+	select {
+	case change, ok := <-changes:
+		if !ok {
+			t.Fatal("Expected a change, channel closed")
+		}
+		return change
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for a change")
+	}
+	return model.BackendChange{}
+}
+
+func TestWatchReportsChanges(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	repo := repository.New()
+	changes := repo.Watch(ctx)
+
+	b1 := model.NewBackend("b1", "10.0.0.1", 80, 1)
+	repo.Add(ctx, b1)
+	repo.SetHealthy(ctx, "b1", true)
+	repo.SetHealthy(ctx, "b1", false)
+	repo.Update(ctx, model.NewBackend("b1", "10.0.0.1", 8080, 2))
+	repo.Remove(ctx, "b1")
+
+	expected := []struct {
+		kind    string
+		port    int
+		healthy bool
+	}{
+		{model.BackendAdded, 80, true},
+		{model.BackendHealthChanged, 80, false},
+		// Health belongs to the ID, so an update keeps it.
+		{model.BackendUpdated, 8080, false},
+		{model.BackendRemoved, 8080, false},
+	}
+	for i, e := range expected {
+		change := next(t, changes)
+		if change.Type != e.kind || change.Backend.Port != e.port || change.Healthy != e.healthy {
+			t.Errorf("Change %d: expected %s of port %d (healthy %v), got %s of port %d (healthy %v)",
+				i, e.kind, e.port, e.healthy, change.Type, change.Backend.Port, change.Healthy)
+		}
+		if change.Version != uint64(i+1) {
+			t.Errorf("Change %d: expected version %d, got %d", i, i+1, change.Version)
+		}
+	}
+	if v := repo.Version(); v != 4 {
+		t.Errorf("Expected repository version 4, got %d", v)
+	}
+}
+
+func TestWatchStartsWithCurrentBackends(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	repo := repository.New()
+	repo.Add(ctx, model.NewBackend("b1", "10.0.0.1", 80, 1))
+	repo.Add(ctx, model.NewBackend("b2", "10.0.0.2", 80, 1))
+
+	changes := repo.Watch(ctx)
+	seen := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		change := next(t, changes)
+		if change.Type != model.BackendAdded || change.Version != 2 {
+			t.Errorf("Expected add at version 2, got %s at %d", change.Type, change.Version)
+		}
+		seen[change.Backend.ID] = true
+	}
+	if !seen["b1"] || !seen["b2"] {
+		t.Errorf("Expected both backends, got %v", seen)
+	}
+
+	repo.Remove(ctx, "b2")
+	if change := next(t, changes); change.Type != model.BackendRemoved || change.Backend.ID != "b2" || change.Version != 3 {
+		t.Errorf("Unexpected change %+v", change)
+	}
+}
+
+func TestWatchDoesNotBlockWriters(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	repo := repository.New()
+	changes := repo.Watch(ctx)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		repo.Add(ctx, model.NewBackend("b1", "10.0.0.1", 80, 1))
+		for i := 0; i < 1000; i++ {
+			repo.SetHealthy(ctx, "b1", i%2 == 1)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Writers blocked on an idle watcher")
+	}
+
+	for i := 0; i < 1001; i++ {
+		if change := next(t, changes); change.Version != uint64(i+1) {
+			t.Fatalf("Expected version %d, got %d", i+1, change.Version)
+		}
+	}
+
+	cancel()
+	select {
+	case _, ok := <-changes:
+		if ok {
+			t.Error("Expected no changes after cancel")
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("Expected channel to close after cancel")
+	}
+}
+
+func TestUnknownBackend(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.New()
+	if err := repo.SetHealthy(ctx, "missing", false); err == nil {
+		t.Error("Expected SetHealthy of an unknown backend to fail")
+	}
+	if err := repo.Remove(ctx, "missing"); err == nil {
+		t.Error("Expected removing an unknown backend to fail")
+	}
+	if repo.Version() != 0 {
+		t.Errorf("Expected failed changes not to bump the version, got %d", repo.Version())
+	}
+}
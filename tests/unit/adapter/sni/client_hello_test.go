@@ -0,0 +1,126 @@
+package sni
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/sni"
+	"github.com/reybrally/TCP-Load-Balancer/tests/fixtures"
+)
+
+func dialTLS(conn net.Conn, serverName string, alpn []string, ca *fixtures.TestCA) chan error {
+	done := make(chan error, 1)
+	go func() {
+		cfg := &tls.Config{ServerName: serverName, NextProtos: alpn, InsecureSkipVerify: ca == nil}
+		if ca != nil {
+			cfg.RootCAs = ca.Pool()
+		}
+		client := tls.Client(conn, cfg)
+		client.SetDeadline(time.Now().Add(2 * time.Second))
+		done <- client.Handshake()
+		client.Close()
+	}()
+	return done
+}
+
+func TestPeekExtractsServerNameAndALPN(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	dialTLS(client, "API.Example.com", []string{"h2", "http/1.1"}, nil)
+
+	hello, _, err := sni.Peek(server, time.Second)
+	if err != nil {
+		t.Fatalf("Peek failed: %v", err)
+	}
+
+	if hello.ServerName != "api.example.com" {
+		t.Errorf("Expected server name api.example.com, got %q", hello.ServerName)
+	}
+	if len(hello.ALPN) != 2 || hello.ALPN[0] != "h2" || hello.ALPN[1] != "http/1.1" {
+		t.Errorf("Expected ALPN [h2 http/1.1], got %v", hello.ALPN)
+	}
+}
+
+func TestPeekReplaysBytesUnchanged(t *testing.T) {
+	ca := fixtures.NewTestCA(t)
+	client, server := net.Pipe()
+	defer server.Close()
+
+	done := dialTLS(client, "db.internal", nil, ca)
+
+	hello, peeked, err := sni.Peek(server, time.Second)
+	if err != nil {
+		t.Fatalf("Peek failed: %v", err)
+	}
+	if hello.ServerName != "db.internal" {
+		t.Fatalf("Expected server name db.internal, got %q", hello.ServerName)
+	}
+
+	tlsServer := tls.Server(peeked, &tls.Config{
+		Certificates: []tls.Certificate{ca.IssueTLS(t, "db.internal", "db.internal")},
+	})
+	tlsServer.SetDeadline(time.Now().Add(2 * time.Second))
+	if err := tlsServer.Handshake(); err != nil {
+		t.Fatalf("Handshake over replayed bytes failed: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Client handshake failed: %v", err)
+	}
+}
+
+func TestPeekWithoutServerName(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	dialTLS(client, "", nil, nil)
+
+	hello, _, err := sni.Peek(server, time.Second)
+	if err != nil {
+		t.Fatalf("Peek failed: %v", err)
+	}
+	if hello.ServerName != "" {
+		t.Errorf("Expected empty server name, got %q", hello.ServerName)
+	}
+}
+
+func TestPeekRejectsPlainText(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	request := []byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n")
+	go func() {
+		client.Write(request)
+		client.Close()
+	}()
+
+	_, peeked, err := sni.Peek(server, time.Second)
+	if !errors.Is(err, sni.ErrNotTLS) {
+		t.Fatalf("Expected ErrNotTLS, got %v", err)
+	}
+
+	replayed, _ := io.ReadAll(peeked)
+	if !bytes.Equal(replayed, request) {
+		t.Errorf("Expected peeked bytes to be replayed, got %q", replayed)
+	}
+}
+
+func TestPeekTimeout(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	start := time.Now()
+	_, _, err := sni.Peek(server, 50*time.Millisecond)
+	if err == nil {
+		t.Fatal("Expected timeout error")
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("Peek did not honour timeout, took %v", time.Since(start))
+	}
+}
@@ -0,0 +1,120 @@
+package sni
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/sni"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+	"github.com/reybrally/TCP-Load-Balancer/tests/fixtures"
+)
+
+type namedHandler struct {
+	name string
+}
+
+func (h *namedHandler) Handle(ctx context.Context, conn net.Conn) error {
+	return conn.Close()
+}
+
+func TestRouterMatching(t *testing.T) {
+	api := &namedHandler{"api"}
+	wildcard := &namedHandler{"wildcard"}
+	grpc := &namedHandler{"grpc"}
+	fallback := &namedHandler{"default"}
+
+	router := sni.NewRouter([]sni.Route{
+		{Hosts: []string{"*.example.com"}, Handler: wildcard},
+		{Hosts: []string{"api.example.com"}, Handler: api},
+		{Hosts: []string{"rpc.example.org"}, ALPN: []string{"h2"}, Handler: grpc},
+	}, fallback, time.Second, fixtures.NewMetricsRecorder(), logger.New("test"))
+
+	tests := []struct {
+		name       string
+		serverName string
+		alpn       []string
+		expected   string
+	}{
+		{"Exact wins over wildcard", "api.example.com", nil, "api"},
+		{"Wildcard single label", "www.example.com", nil, "wildcard"},
+		{"Wildcard does not match nested labels", "a.b.example.com", nil, "default"},
+		{"Wildcard does not match apex", "example.com", nil, "default"},
+		{"ALPN required", "rpc.example.org", []string{"http/1.1"}, "default"},
+		{"ALPN matched", "rpc.example.org", []string{"http/1.1", "h2"}, "grpc"},
+		{"No SNI", "", nil, "default"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := router.Match(&sni.ClientHello{ServerName: test.serverName, ALPN: test.alpn})
+			if got := handler.(*namedHandler).name; got != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, got)
+			}
+		})
+	}
+}
+
+type tlsBackendHandler struct {
+	cert   tls.Certificate
+	served chan string
+}
+
+func (h *tlsBackendHandler) Handle(ctx context.Context, conn net.Conn) error {
+	defer conn.Close()
+	server := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{h.cert}})
+	if err := server.Handshake(); err != nil {
+		return err
+	}
+	h.served <- server.ConnectionState().ServerName
+	return nil
+}
+
+func TestRouterPassthrough(t *testing.T) {
+	ca := fixtures.NewTestCA(t)
+	backend := &tlsBackendHandler{
+		cert:   ca.IssueTLS(t, "pg.example.com", "pg.example.com"),
+		served: make(chan string, 1),
+	}
+
+	router := sni.NewRouter([]sni.Route{
+		{Hosts: []string{"pg.example.com"}, Handler: backend},
+	}, nil, time.Second, fixtures.NewMetricsRecorder(), logger.New("test"))
+
+	client, server := net.Pipe()
+	go router.Handle(context.Background(), server)
+
+	tlsClient := tls.Client(client, &tls.Config{RootCAs: ca.Pool(), ServerName: "pg.example.com"})
+	tlsClient.SetDeadline(time.Now().Add(2 * time.Second))
+	if err := tlsClient.Handshake(); err != nil {
+		t.Fatalf("End-to-end handshake failed: %v", err)
+	}
+	tlsClient.Close()
+
+	select {
+	case name := <-backend.served:
+		if name != "pg.example.com" {
+			t.Errorf("Backend saw server name %q", name)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Backend was not reached")
+	}
+}
+
+func TestRouterRejectsUnmatchedWithoutDefault(t *testing.T) {
+	metrics := fixtures.NewMetricsRecorder()
+	router := sni.NewRouter([]sni.Route{
+		{Hosts: []string{"known.example.com"}, Handler: &namedHandler{"known"}},
+	}, nil, time.Second, metrics, logger.New("test"))
+
+	client, server := net.Pipe()
+	dialTLS(client, "unknown.example.com", nil, nil)
+
+	router.Handle(context.Background(), server)
+
+	if metrics.Count("connection_errors:all:no_route") != 1 {
+		t.Error("Expected unmatched connection to be counted as no_route")
+	}
+}
@@ -0,0 +1,193 @@
+package statsd
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/statsd"
+)
+
+// agent is a local UDP socket standing in for a StatsD agent.
+type agent struct {
+	conn net.PacketConn
+}
+
+func newAgent(t *testing.T) *agent {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &agent{conn: conn}
+}
+
+func (a *agent) addr() string {
+	return a.conn.LocalAddr().String()
+}
+
+// packets reads until no packet arrives for 200ms.
+func (a *agent) packets(t *testing.T) []string {
+	t.Helper()
+	var packets []string
+	buf := make([]byte, 64*1024)
+	for {
+		a.conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		n, _, err := a.conn.ReadFrom(buf)
+		if err != nil {
+			return packets
+		}
+		packets = append(packets, string(buf[:n]))
+	}
+}
+
+func lines(packets []string) map[string]bool {
+	set := make(map[string]bool)
+	for _, packet := range packets {
+		for _, line := range strings.Split(packet, "\n") {
+			set[line] = true
+		}
+	}
+	return set
+}
+
+func newClient(t *testing.T, cfg statsd.Config) *statsd.Client {
+	t.Helper()
+	if cfg.FlushInterval == 0 {
+		cfg.FlushInterval = time.Hour
+	}
+	client, err := statsd.New(cfg)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestAggregatesBetweenFlushes(t *testing.T) {
+	agent := newAgent(t)
+	client := newClient(t, statsd.Config{Address: agent.addr(), Prefix: "tcp_lb", Tags: []string{"env:test"}})
+	metrics := statsd.NewMetrics(client).ForFrontend("web")
+
+	metrics.IncConnectionsTotal("10.0.0.1:80")
+	metrics.IncConnectionsTotal("10.0.0.1:80")
+	metrics.AddBytesSent("10.0.0.1:80", 100)
+	metrics.AddBytesSent("10.0.0.1:80", 28)
+	metrics.IncConnectionsActive("10.0.0.1:80")
+	metrics.IncConnectionsActive("10.0.0.1:80")
+	metrics.DecConnectionsActive("10.0.0.1:80")
+	metrics.ObserveDialLatency("10.0.0.1:80", 0.0025)
+	metrics.IncRejectedConnections("acl_denied")
+	client.Flush()
+
+	got := lines(agent.packets(t))
+	for _, want := range []string{
+		"tcp_lb.connections:2|c|#frontend:web,backend:10.0.0.1:80,env:test",
+		"tcp_lb.bytes.sent:128|c|#frontend:web,backend:10.0.0.1:80,env:test",
+		"tcp_lb.connections.active:1|g|#frontend:web,backend:10.0.0.1:80,env:test",
+		"tcp_lb.backend.dial.duration:2.5|ms|#frontend:web,backend:10.0.0.1:80,env:test",
+		"tcp_lb.connections.rejected:1|c|#frontend:web,reason:acl_denied,env:test",
+	} {
+		if !got[want] {
+			t.Errorf("Expected line %q, got %v", want, got)
+		}
+	}
+
+	// Counters start over; gauges are resent.
+	client.Flush()
+	got = lines(agent.packets(t))
+	if len(got) != 1 || !got["tcp_lb.connections.active:1|g|#frontend:web,backend:10.0.0.1:80,env:test"] {
+		t.Errorf("Expected only the gauge after an idle flush, got %v", got)
+	}
+}
+
+func TestSplitsPackets(t *testing.T) {
+	agent := newAgent(t)
+	client := newClient(t, statsd.Config{Address: agent.addr(), MaxPacketSize: 200})
+	metrics := statsd.NewMetrics(client)
+
+	for i := 0; i < 50; i++ {
+		metrics.ObserveQueueWait(float64(i))
+	}
+	client.Flush()
+
+	packets := agent.packets(t)
+	if len(packets) < 2 {
+		t.Fatalf("Expected several packets, got %d", len(packets))
+	}
+	count := 0
+	for _, packet := range packets {
+		if len(packet) > 200 {
+			t.Errorf("Packet of %d bytes exceeds the limit", len(packet))
+		}
+		count += len(strings.Split(packet, "\n"))
+	}
+	if count != 50 {
+		t.Errorf("Expected 50 timing lines, got %d", count)
+	}
+}
+
+func TestSamplesTimings(t *testing.T) {
+	agent := newAgent(t)
+	client := newClient(t, statsd.Config{Address: agent.addr()})
+	metrics := statsd.NewMetrics(client)
+
+	for i := 0; i < 4*statsd.MaxTimingSamples; i++ {
+		metrics.ObserveQueueWait(0.001)
+	}
+	client.Flush()
+
+	packets := agent.packets(t)
+	count := 0
+	for _, packet := range packets {
+		count += len(strings.Split(packet, "\n"))
+	}
+	if count != statsd.MaxTimingSamples {
+		t.Errorf("Expected %d timing lines, got %d", statsd.MaxTimingSamples, count)
+	}
+	if got := lines(packets); len(got) != 1 || !got["queue.wait:1|ms|@0.25|#frontend:default"] {
+		t.Errorf("Expected sampled timings with rate 0.25, got %v", got)
+	}
+}
+
+func TestCountingBytesDoesNotAllocate(t *testing.T) {
+	client := newClient(t, statsd.Config{Address: newAgent(t).addr()})
+	metrics := statsd.NewMetrics(client).ForFrontend("web")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		metrics.AddBytesSent("10.0.0.1:80", 1024)
+		metrics.AddBytesReceived("10.0.0.1:80", 1024)
+	})
+	if allocs != 0 {
+		t.Errorf("Expected byte counters not to allocate, got %.1f allocations per run", allocs)
+	}
+}
+
+func TestRemoveBackendDropsGauges(t *testing.T) {
+	agent := newAgent(t)
+	client := newClient(t, statsd.Config{Address: agent.addr()})
+	metrics := statsd.NewMetrics(client)
+
+	metrics.SetBackendHealthStatus("10.0.0.1:80", true)
+	metrics.SetBackendHealthStatus("10.0.0.2:80", false)
+	metrics.RemoveBackend("10.0.0.1:80")
+	client.Flush()
+
+	got := lines(agent.packets(t))
+	if len(got) != 1 || !got["backend.healthy:0|g|#frontend:default,backend:10.0.0.2:80"] {
+		t.Errorf("Expected only the remaining backend's gauge, got %v", got)
+	}
+}
+
+func TestFlushesOnInterval(t *testing.T) {
+	agent := newAgent(t)
+	client := newClient(t, statsd.Config{Address: agent.addr(), FlushInterval: 50 * time.Millisecond})
+	statsd.NewMetrics(client).IncTLSHandshakeErrors("bad_certificate")
+
+	got := lines(agent.packets(t))
+	if !got["tls.handshake_errors:1|c|#frontend:default,reason:bad_certificate"] {
+		t.Errorf("Expected the counter to be flushed, got %v", got)
+	}
+}
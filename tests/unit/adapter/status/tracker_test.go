@@ -0,0 +1,124 @@
+package status
+
+import (
+	"context"
+	"testing"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/repository"
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/status"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+)
+
+func newTracker(t *testing.T) (*status.Tracker, *model.Backend) {
+	t.Helper()
+	repo := repository.New()
+	backend := model.NewBackend("web-backend-0", "10.0.0.1", 80, 1)
+	repo.Add(context.Background(), backend)
+
+	tracker := status.New("v1.0.0")
+	tracker.AddFrontend(status.Frontend{
+		Name:  "web",
+		Pools: []status.Pool{{Name: "web", Repository: repo}},
+	})
+	return tracker, backend
+}
+
+func TestTrackerCountsSessions(t *testing.T) {
+	tracker, backend := newTracker(t)
+	web := tracker.ForFrontend("web")
+	addr := backend.GetAddress()
+
+	web.IncConnectionsTotal(addr)
+	web.IncConnectionsActive(addr)
+	web.IncConnectionsTotal(addr)
+	web.IncConnectionsActive(addr)
+	web.DecConnectionsActive(addr)
+	web.AddBytesSent(addr, 100)
+	web.AddBytesReceived(addr, 300)
+	web.IncConnectionErrors(addr, "connection_failed")
+	web.IncConnectionErrors("all", "no_healthy_backends")
+	web.IncRejectedConnections("rate_limited_ip")
+	tracker.ForFrontend("api").IncConnectionsTotal(addr)
+
+	st := tracker.Status(context.Background())
+	if len(st.Frontends) != 1 {
+		t.Fatalf("Expected only registered frontends, got %d", len(st.Frontends))
+	}
+	fe := st.Frontends[0]
+	if fe.TotalSessions != 2 || fe.ActiveSessions != 1 {
+		t.Errorf("Expected 2 total and 1 active session, got %d and %d", fe.TotalSessions, fe.ActiveSessions)
+	}
+	if fe.Errors["no_healthy_backends"] != 1 || fe.Rejected["rate_limited_ip"] != 1 {
+		t.Errorf("Expected frontend errors and rejections, got %v and %v", fe.Errors, fe.Rejected)
+	}
+
+	b := fe.Pools[0].Backends[0]
+	if b.TotalSessions != 2 || b.ActiveSessions != 1 || b.BytesSent != 100 || b.BytesReceived != 300 {
+		t.Errorf("Unexpected backend counters %+v", b)
+	}
+	if b.Errors["connection_failed"] != 1 || !b.Healthy {
+		t.Errorf("Unexpected backend state %+v", b)
+	}
+	if tracker.TotalSessions() != 3 {
+		t.Errorf("Expected 3 sessions across frontends, got %d", tracker.TotalSessions())
+	}
+}
+
+func TestTrackerHealthHistory(t *testing.T) {
+	tracker, backend := newTracker(t)
+	web := tracker.ForFrontend("web")
+	addr := backend.GetAddress()
+
+	web.SetBackendHealthStatus(addr, true)
+	if b := tracker.Status(context.Background()).Frontends[0].Pools[0].Backends[0]; !b.LastChange.IsZero() {
+		t.Error("Expected no state change while healthy")
+	}
+
+	for i := 0; i < status.HistorySize+5; i++ {
+		web.IncHealthChecksTotal(addr, "success")
+	}
+	web.IncHealthChecksTotal(addr, "failed")
+	web.SetBackendHealthStatus(addr, false)
+
+	b := tracker.Status(context.Background()).Frontends[0].Pools[0].Backends[0]
+	if len(b.HealthChecks) != status.HistorySize {
+		t.Fatalf("Expected %d health checks, got %d", status.HistorySize, len(b.HealthChecks))
+	}
+	if last := b.HealthChecks[len(b.HealthChecks)-1]; last.Healthy {
+		t.Error("Expected the latest check last")
+	}
+	if b.LastChange.IsZero() {
+		t.Error("Expected the state change to be recorded")
+	}
+
+	tracker.RemoveBackend(addr)
+	b = tracker.Status(context.Background()).Frontends[0].Pools[0].Backends[0]
+	if len(b.HealthChecks) != 0 || !b.LastChange.IsZero() {
+		t.Errorf("Expected removed backend's stats to be dropped, got %+v", b)
+	}
+}
+
+func TestTrackerReportsAdminStateApartFromHealth(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.New()
+	backend := model.NewBackend("web-backend-0", "10.0.0.1", 80, 1)
+	repo.Add(ctx, backend)
+
+	tracker := status.New("v1.0.0")
+	tracker.AddFrontend(status.Frontend{
+		Name:  "web",
+		Pools: []status.Pool{{Name: "web", Repository: repo, Admin: repo}},
+	})
+
+	repo.SetAdminState(ctx, backend.ID, model.AdminDrain)
+	b := tracker.Status(ctx).Frontends[0].Pools[0].Backends[0]
+	if b.AdminState != model.AdminDrain || !b.Healthy {
+		t.Errorf("Expected a healthy draining backend, got %+v", b)
+	}
+
+	tracker.ForFrontend("web").SetBackendHealthStatus(backend.GetAddress(), false)
+	b = tracker.Status(ctx).Frontends[0].Pools[0].Backends[0]
+	if b.AdminState != model.AdminDrain || b.Healthy {
+		t.Errorf("Expected an unhealthy draining backend, got %+v", b)
+	}
+}
@@ -0,0 +1,139 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/telemetry"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/port"
+	"github.com/reybrally/TCP-Load-Balancer/tests/fixtures"
+	otlptrace "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+func newProvider(t *testing.T, collector *fixtures.OTLPCollector) *telemetry.Provider {
+	t.Helper()
+	provider, err := telemetry.NewProvider(context.Background(), telemetry.Options{
+		Endpoint:    collector.Endpoint(),
+		Insecure:    true,
+		Interval:    time.Hour,
+		ServiceName: "test",
+		Traces:      true,
+	})
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	return provider
+}
+
+func shutdown(t *testing.T, provider *telemetry.Provider) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := provider.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+}
+
+func TestExportMetrics(t *testing.T) {
+	collector := fixtures.NewOTLPCollector(t)
+	provider := newProvider(t, collector)
+
+	metrics, err := provider.Metrics()
+	if err != nil {
+		t.Fatalf("Metrics failed: %v", err)
+	}
+	web := metrics.ForFrontend("web")
+	web.IncConnectionsTotal("10.0.0.1:80")
+	web.IncConnectionsTotal("10.0.0.1:80")
+	web.AddBytesSent("10.0.0.1:80", 512)
+	web.ObserveDialLatency("10.0.0.1:80", 0.003)
+	web.SetBackendHealthStatus("10.0.0.1:80", true)
+	web.SetBackendHealthStatus("10.0.0.2:80", true)
+	metrics.RemoveBackend("10.0.0.2:80")
+
+	shutdown(t, provider)
+
+	connections := collector.Metric("tcp_lb.connections")
+	if connections == nil {
+		t.Fatal("Expected tcp_lb.connections to be exported")
+	}
+	points := connections.GetSum().GetDataPoints()
+	if len(points) != 1 || points[0].GetAsInt() != 2 {
+		t.Fatalf("Expected one point with value 2, got %v", points)
+	}
+	if got := fixtures.Attr(points[0].GetAttributes(), "frontend"); got != "web" {
+		t.Errorf("Expected frontend web, got %q", got)
+	}
+	if got := fixtures.Attr(points[0].GetAttributes(), "backend"); got != "10.0.0.1:80" {
+		t.Errorf("Expected backend 10.0.0.1:80, got %q", got)
+	}
+
+	if bytes := collector.Metric("tcp_lb.bytes.sent"); bytes == nil || bytes.GetSum().GetDataPoints()[0].GetAsInt() != 512 {
+		t.Errorf("Expected 512 bytes sent, got %v", bytes)
+	}
+	if dial := collector.Metric("tcp_lb.backend.dial.duration"); dial == nil || dial.GetHistogram().GetDataPoints()[0].GetCount() != 1 {
+		t.Errorf("Expected one dial latency observation, got %v", dial)
+	}
+
+	healthy := collector.Metric("tcp_lb.backend.healthy")
+	if healthy == nil {
+		t.Fatal("Expected tcp_lb.backend.healthy to be exported")
+	}
+	gauge := healthy.GetGauge().GetDataPoints()
+	if len(gauge) != 1 || fixtures.Attr(gauge[0].GetAttributes(), "backend") != "10.0.0.1:80" {
+		t.Errorf("Expected only the remaining backend's health, got %v", gauge)
+	}
+}
+
+func TestExportSessionSpans(t *testing.T) {
+	collector := fixtures.NewOTLPCollector(t)
+	provider := newProvider(t, collector)
+
+	accepted := time.Now().Add(-50 * time.Millisecond)
+	span := provider.Tracer().ForFrontend("web").StartSession(context.Background(), "198.51.100.7:52000", accepted)
+	span.Phase(port.PhaseSelect)(nil)
+	span.Phase(port.PhaseDial)(errors.New("connection refused"))
+	span.End(&model.SessionRecord{
+		Backend:     "10.0.0.1:80",
+		CloseReason: model.CloseDialFailed,
+	})
+
+	shutdown(t, provider)
+
+	spans := make(map[string]*otlptrace.Span)
+	for _, s := range collector.Spans() {
+		spans[s.GetName()] = s
+	}
+	session, handshake, dial := spans["session"], spans[port.PhaseHandshake], spans[port.PhaseDial]
+	if session == nil || handshake == nil || dial == nil || spans[port.PhaseSelect] == nil {
+		t.Fatalf("Expected session, handshake, select and dial spans, got %v", collector.Spans())
+	}
+	if session.GetStartTimeUnixNano() != uint64(accepted.UnixNano()) {
+		t.Errorf("Expected session span to start at accept, got %d want %d", session.GetStartTimeUnixNano(), accepted.UnixNano())
+	}
+	if handshake.GetStartTimeUnixNano() != session.GetStartTimeUnixNano() ||
+		handshake.GetEndTimeUnixNano() > spans[port.PhaseSelect].GetStartTimeUnixNano() {
+		t.Error("Expected handshake span to run from accept until select started")
+	}
+	if string(handshake.GetParentSpanId()) != string(session.GetSpanId()) {
+		t.Error("Expected handshake span to be a child of the session span")
+	}
+	if string(dial.GetParentSpanId()) != string(session.GetSpanId()) {
+		t.Error("Expected dial span to be a child of the session span")
+	}
+	if dial.GetStatus().GetCode() != otlptrace.Status_STATUS_CODE_ERROR {
+		t.Errorf("Expected failed dial span, got status %v", dial.GetStatus())
+	}
+	if got := fixtures.Attr(session.GetAttributes(), "backend"); got != "10.0.0.1:80" {
+		t.Errorf("Expected backend attribute, got %q", got)
+	}
+	if got := fixtures.Attr(session.GetAttributes(), "close_reason"); got != model.CloseDialFailed {
+		t.Errorf("Expected close_reason %s, got %q", model.CloseDialFailed, got)
+	}
+	if got := fixtures.Attr(session.GetAttributes(), "frontend"); got != "web" {
+		t.Errorf("Expected frontend web, got %q", got)
+	}
+}
@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/balancer"
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/listener"
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/repository"
+	"github.com/reybrally/TCP-Load-Balancer/internal/application/usecase"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+	"github.com/reybrally/TCP-Load-Balancer/tests/fixtures"
+)
+
+func TestAccessLogRecordsSession(t *testing.T) {
+	repo, backends := limitedPool(t, 0)
+	accessLog := &fixtures.AccessLogRecorder{}
+	uc := usecase.New(balancer.New(), repo, fixtures.NewMetricsRecorder(), logger.New("test"), usecase.WithAccessLog(accessLog))
+
+	s := startSession(t, uc)
+	s.mustEcho(t, "hello")
+	s.conn.Close()
+
+	record := accessLog.Wait(t, 1)[0]
+	if record.Backend != backends[0].GetAddress() {
+		t.Errorf("Expected backend %s, got %s", backends[0].GetAddress(), record.Backend)
+	}
+	if record.BytesSent != 6 || record.BytesReceived != 6 {
+		t.Errorf("Expected 6 bytes each way, got %d sent and %d received", record.BytesSent, record.BytesReceived)
+	}
+	if record.CloseReason != model.CloseClientClosed {
+		t.Errorf("Expected close reason %s, got %s", model.CloseClientClosed, record.CloseReason)
+	}
+	if record.Client == "" || record.Start.IsZero() || record.Duration <= 0 {
+		t.Errorf("Expected client, start and duration to be set, got %+v", record)
+	}
+}
+
+func TestAccessLogRecordsRejection(t *testing.T) {
+	accessLog := &fixtures.AccessLogRecorder{}
+	uc := usecase.New(balancer.New(), repository.New(), fixtures.NewMetricsRecorder(), logger.New("test"), usecase.WithAccessLog(accessLog))
+
+	s := startSession(t, uc)
+	s.readLine(2 * time.Second)
+
+	record := accessLog.Wait(t, 1)[0]
+	if record.CloseReason != model.CloseNoHealthyBackends || record.Backend != "" {
+		t.Errorf("Expected unrouted %s record, got %+v", model.CloseNoHealthyBackends, record)
+	}
+}
+
+func TestAccessLogRecordsQueueRetries(t *testing.T) {
+	repo, _ := limitedPool(t, 1)
+	accessLog := &fixtures.AccessLogRecorder{}
+	uc := usecase.New(balancer.New(), repo, fixtures.NewMetricsRecorder(), logger.New("test"),
+		usecase.WithAccessLog(accessLog), usecase.WithQueue(1, 350*time.Millisecond))
+
+	first := startSession(t, uc)
+	first.mustEcho(t, "first")
+
+	second := startSession(t, uc)
+	if line := second.readLine(2 * time.Second); line != "Backends busy\n" {
+		t.Fatalf("Expected queued client to be rejected, got %q", line)
+	}
+
+	record := accessLog.Wait(t, 1)[0]
+	if record.CloseReason != model.CloseQueueTimeout {
+		t.Errorf("Expected close reason %s, got %s", model.CloseQueueTimeout, record.CloseReason)
+	}
+	// The head of the queue retries on every poll until it times out.
+	if record.Retries < 2 {
+		t.Errorf("Expected queued connection to retry, got %d retries", record.Retries)
+	}
+}
+
+func TestAccessLogRecordsUDPSession(t *testing.T) {
+	repo := repository.New()
+	repo.Add(context.Background(), model.NewBackend("b1", "127.0.0.1", fixtures.StartUDPEchoServer(t, "b1:"), 1))
+
+	accessLog := &fixtures.AccessLogRecorder{}
+	log := logger.New("test")
+	uc := usecase.NewDatagram(balancer.New(), repo, fixtures.NewMetricsRecorder(), log, 100*time.Millisecond, usecase.WithDatagramAccessLog(accessLog))
+
+	ul, err := listener.NewUDP("127.0.0.1", 0, log)
+	if err != nil {
+		t.Fatalf("Failed to create UDP listener: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go ul.Listen(ctx, uc)
+	defer func() {
+		cancel()
+		ul.Close()
+	}()
+
+	conn, err := net.Dial("udp", ul.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer conn.Close()
+	exchange(t, conn, "ping")
+
+	record := accessLog.Wait(t, 1)[0]
+	if record.CloseReason != model.CloseIdleTimeout {
+		t.Errorf("Expected close reason %s, got %s", model.CloseIdleTimeout, record.CloseReason)
+	}
+	if record.BytesSent != 4 || record.BytesReceived != 7 {
+		t.Errorf("Expected 4 bytes sent and 7 received, got %d and %d", record.BytesSent, record.BytesReceived)
+	}
+}
@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/balancer"
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/repository"
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/tlsutil"
+	"github.com/reybrally/TCP-Load-Balancer/internal/application/usecase"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+	"github.com/reybrally/TCP-Load-Balancer/tests/fixtures"
+)
+
+func proxyOnce(t *testing.T, uc *usecase.HandleConnectionUseCase, msg string) (string, error) {
+	t.Helper()
+
+	client, server := net.Pipe()
+	defer client.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- uc.Handle(context.Background(), server)
+	}()
+
+	client.SetDeadline(time.Now().Add(2 * time.Second))
+	go client.Write([]byte(msg + "\n"))
+	line, _ := bufio.NewReader(client).ReadString('\n')
+	client.Close()
+
+	select {
+	case err := <-done:
+		return line, err
+	case <-time.After(2 * time.Second):
+		t.Fatal("Handle did not return")
+		return "", nil
+	}
+}
+
+func newTLSBackendRepo(t *testing.T, serverConfig *tls.Config) *repository.BackendRepo {
+	t.Helper()
+
+	port := fixtures.StartTLSEchoServer(t, serverConfig)
+	repo := repository.New()
+	repo.Add(context.Background(), model.NewBackend("tls", "127.0.0.1", port, 1))
+	return repo
+}
+
+func TestHandleOriginatesTLS(t *testing.T) {
+	ca := fixtures.NewTestCA(t)
+	repo := newTLSBackendRepo(t, &tls.Config{
+		Certificates: []tls.Certificate{ca.IssueTLS(t, "backend")},
+	})
+
+	tlsConfig, err := tlsutil.NewClientConfig(tlsutil.ClientOptions{CAFile: ca.WriteCA(t, t.TempDir())})
+	if err != nil {
+		t.Fatalf("Failed to build client config: %v", err)
+	}
+
+	uc := usecase.New(balancer.New(), repo, fixtures.NewMetricsRecorder(), logger.New("test"),
+		usecase.WithBackendTLS(tlsConfig))
+
+	line, _ := proxyOnce(t, uc, "encrypted hop")
+	if line != "encrypted hop\n" {
+		t.Errorf("Expected echo over TLS, got %q", line)
+	}
+}
+
+func TestHandleOriginatesMutualTLS(t *testing.T) {
+	ca := fixtures.NewTestCA(t)
+	dir := t.TempDir()
+	repo := newTLSBackendRepo(t, &tls.Config{
+		Certificates: []tls.Certificate{ca.IssueTLS(t, "backend")},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    ca.Pool(),
+	})
+
+	certFile, keyFile := ca.IssueFiles(t, dir, "balancer")
+	tlsConfig, err := tlsutil.NewClientConfig(tlsutil.ClientOptions{
+		CAFile:   ca.WriteCA(t, dir),
+		CertFile: certFile,
+		KeyFile:  keyFile,
+	})
+	if err != nil {
+		t.Fatalf("Failed to build client config: %v", err)
+	}
+
+	uc := usecase.New(balancer.New(), repo, fixtures.NewMetricsRecorder(), logger.New("test"),
+		usecase.WithBackendTLS(tlsConfig))
+
+	line, _ := proxyOnce(t, uc, "mutual")
+	if line != "mutual\n" {
+		t.Errorf("Expected echo over mTLS, got %q", line)
+	}
+}
+
+func TestHandleRejectsUntrustedBackend(t *testing.T) {
+	ca := fixtures.NewTestCA(t)
+	other := fixtures.NewTestCA(t)
+	repo := newTLSBackendRepo(t, &tls.Config{
+		Certificates: []tls.Certificate{other.IssueTLS(t, "backend")},
+	})
+
+	tlsConfig, err := tlsutil.NewClientConfig(tlsutil.ClientOptions{CAFile: ca.WriteCA(t, t.TempDir())})
+	if err != nil {
+		t.Fatalf("Failed to build client config: %v", err)
+	}
+
+	metrics := fixtures.NewMetricsRecorder()
+	uc := usecase.New(balancer.New(), repo, metrics, logger.New("test"), usecase.WithBackendTLS(tlsConfig))
+
+	line, err := proxyOnce(t, uc, "hello")
+	if err == nil {
+		t.Error("Expected handshake error for untrusted backend")
+	}
+	if line != "Backend unavailable\n" {
+		t.Errorf("Expected 'Backend unavailable', got %q", line)
+	}
+
+	backendAddr := repo.GetAll(context.Background())[0].GetAddress()
+	if metrics.Count("connection_errors:"+backendAddr+":tls_handshake_failed") != 1 {
+		t.Error("Expected tls_handshake_failed to be counted")
+	}
+
+	insecure, _ := tlsutil.NewClientConfig(tlsutil.ClientOptions{InsecureSkipVerify: true})
+	uc = usecase.New(balancer.New(), repo, metrics, logger.New("test"), usecase.WithBackendTLS(insecure))
+	if line, _ := proxyOnce(t, uc, "skip verify"); line != "skip verify\n" {
+		t.Errorf("Expected echo with insecure_skip_verify, got %q", line)
+	}
+}
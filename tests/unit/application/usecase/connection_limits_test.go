@@ -0,0 +1,220 @@
+package usecase
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/balancer"
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/repository"
+	"github.com/reybrally/TCP-Load-Balancer/internal/application/usecase"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+	"github.com/reybrally/TCP-Load-Balancer/tests/fixtures"
+)
+
+type clientSession struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	done   chan error
+}
+
+func startSession(t *testing.T, uc *usecase.HandleConnectionUseCase) *clientSession {
+	t.Helper()
+
+	client, server := net.Pipe()
+	s := &clientSession{conn: client, reader: bufio.NewReader(client), done: make(chan error, 1)}
+	go func() {
+		s.done <- uc.Handle(context.Background(), server)
+	}()
+	t.Cleanup(func() { client.Close() })
+	return s
+}
+
+func (s *clientSession) echo(msg string, timeout time.Duration) (string, error) {
+	s.conn.SetDeadline(time.Now().Add(timeout))
+	if _, err := s.conn.Write([]byte(msg + "\n")); err != nil {
+		return "", err
+	}
+	return s.reader.ReadString('\n')
+}
+
+func (s *clientSession) readLine(timeout time.Duration) string {
+	s.conn.SetDeadline(time.Now().Add(timeout))
+	line, _ := s.reader.ReadString('\n')
+	return line
+}
+
+func (s *clientSession) mustEcho(t *testing.T, msg string) {
+	t.Helper()
+	if line, err := s.echo(msg, 2*time.Second); err != nil || line != msg+"\n" {
+		t.Fatalf("Expected echo %q, got %q (%v)", msg, line, err)
+	}
+}
+
+func limitedPool(t *testing.T, maxConnections ...int) (*repository.BackendRepo, []*model.Backend) {
+	t.Helper()
+
+	repo := repository.New()
+	var backends []*model.Backend
+	for i, max := range maxConnections {
+		b := model.NewBackend(string(rune('a'+i)), "127.0.0.1", fixtures.StartEchoServer(t), 1)
+		b.MaxConnections = max
+		repo.Add(context.Background(), b)
+		backends = append(backends, b)
+	}
+	return repo, backends
+}
+
+func waitForCount(t *testing.T, metrics *fixtures.MetricsRecorder, key string, count int) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for metrics.Count(key) < count {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s to reach %d, got %d", key, count, metrics.Count(key))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestFullBackendIsSkipped(t *testing.T) {
+	repo, backends := limitedPool(t, 1, 0)
+	uc := usecase.New(balancer.New(), repo, fixtures.NewMetricsRecorder(), logger.New("test"))
+
+	for i := 0; i < 4; i++ {
+		startSession(t, uc).mustEcho(t, "hello")
+	}
+
+	if backends[0].GetActiveConnections() != 1 {
+		t.Errorf("Expected full backend to keep 1 connection, got %d", backends[0].GetActiveConnections())
+	}
+	if backends[1].GetActiveConnections() != 3 {
+		t.Errorf("Expected other backend to take the rest, got %d", backends[1].GetActiveConnections())
+	}
+}
+
+func TestAllBackendsFullWithoutQueue(t *testing.T) {
+	repo, _ := limitedPool(t, 1)
+	metrics := fixtures.NewMetricsRecorder()
+	uc := usecase.New(balancer.New(), repo, metrics, logger.New("test"))
+
+	startSession(t, uc).mustEcho(t, "holder")
+
+	if line := startSession(t, uc).readLine(2 * time.Second); line != "Backends busy\n" {
+		t.Errorf("Expected busy message, got %q", line)
+	}
+	if metrics.Count("connection_errors:all:backends_full") != 1 {
+		t.Error("Expected backends_full to be counted")
+	}
+}
+
+func TestQueuedConnectionsAreServedInOrder(t *testing.T) {
+	repo, backends := limitedPool(t, 1)
+	metrics := fixtures.NewMetricsRecorder()
+	uc := usecase.New(balancer.New(), repo, metrics, logger.New("test"), usecase.WithQueue(2, 5*time.Second))
+
+	holder := startSession(t, uc)
+	holder.mustEcho(t, "holder")
+
+	first := startSession(t, uc)
+	waitForCount(t, metrics, "queue_depth_inc", 1)
+	second := startSession(t, uc)
+	waitForCount(t, metrics, "queue_depth_inc", 2)
+
+	holder.conn.Close()
+	first.mustEcho(t, "first")
+
+	if _, err := second.echo("second", 300*time.Millisecond); err == nil {
+		t.Fatal("Expected second client to keep waiting while the first holds the slot")
+	}
+
+	first.conn.Close()
+	second.mustEcho(t, "second")
+
+	if metrics.Count("queue_wait") != 2 || metrics.Count("queue_depth_dec") != 2 {
+		t.Errorf("Expected 2 queue waits, got %d", metrics.Count("queue_wait"))
+	}
+	if backends[0].GetActiveConnections() != 1 {
+		t.Errorf("Expected 1 active connection, got %d", backends[0].GetActiveConnections())
+	}
+}
+
+func TestQueueTimeoutAndOverflow(t *testing.T) {
+	repo, _ := limitedPool(t, 1)
+	metrics := fixtures.NewMetricsRecorder()
+	uc := usecase.New(balancer.New(), repo, metrics, logger.New("test"), usecase.WithQueue(1, 200*time.Millisecond))
+
+	startSession(t, uc).mustEcho(t, "holder")
+
+	waiting := startSession(t, uc)
+	waitForCount(t, metrics, "queue_depth_inc", 1)
+
+	if line := startSession(t, uc).readLine(2 * time.Second); line != "Backends busy\n" {
+		t.Errorf("Expected overflow to be rejected, got %q", line)
+	}
+	if metrics.Count("connection_errors:all:queue_full") != 1 {
+		t.Error("Expected queue_full to be counted")
+	}
+
+	if line := waiting.readLine(2 * time.Second); line != "Backends busy\n" {
+		t.Errorf("Expected queued client to time out, got %q", line)
+	}
+	if metrics.Count("connection_errors:all:queue_timeout") != 1 {
+		t.Error("Expected queue_timeout to be counted")
+	}
+}
+
+func TestQueuedConnectionKeepsItsPlace(t *testing.T) {
+	repo, _ := limitedPool(t, 1)
+	metrics := fixtures.NewMetricsRecorder()
+	uc := usecase.New(balancer.New(), repo, metrics, logger.New("test"), usecase.WithQueue(1, 5*time.Second))
+
+	holder := startSession(t, uc)
+	holder.mustEcho(t, "holder")
+
+	waiting := startSession(t, uc)
+	waitForCount(t, metrics, "queue_depth_inc", 1)
+
+	// Newcomers arrive while the queued client retries on every poll and
+	// while the slot is freed; none of them may get ahead of it.
+	stop := make(chan struct{})
+	served := make(chan string, 100)
+	var newcomers sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		newcomers.Add(1)
+		go func() {
+			defer newcomers.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				newcomer := startSession(t, uc)
+				if line, err := newcomer.echo("newcomer", time.Second); err == nil {
+					served <- line
+				}
+				newcomer.conn.Close()
+			}
+		}()
+	}
+
+	time.Sleep(350 * time.Millisecond)
+	holder.conn.Close()
+	waiting.mustEcho(t, "waiting")
+	close(stop)
+	newcomers.Wait()
+
+	select {
+	case line := <-served:
+		t.Errorf("Expected newcomers to be rejected while a client waits, one got %q", line)
+	default:
+	}
+	if metrics.Count("connection_errors:all:queue_timeout") != 0 {
+		t.Error("Expected the queued client not to time out")
+	}
+}
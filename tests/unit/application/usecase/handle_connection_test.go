@@ -3,6 +3,7 @@ package usecase
 import (
 	"context"
 	"net"
+	"strings"
 	"testing"
 	"time"
 
@@ -15,13 +16,23 @@ import (
 
 type mockMetricsCollector struct{}
 
-func (m *mockMetricsCollector) IncConnectionsTotal(backend string)                  {}
-func (m *mockMetricsCollector) IncConnectionsActive(backend string)                 {}
-func (m *mockMetricsCollector) DecConnectionsActive(backend string)                 {}
-func (m *mockMetricsCollector) IncConnectionErrors(backend, reason string)          {}
-func (m *mockMetricsCollector) ObserveConnectionDuration(backend string, d float64) {}
-func (m *mockMetricsCollector) IncHealthChecksTotal(backend, status string)         {}
-func (m *mockMetricsCollector) SetBackendHealthStatus(backend string, healthy bool) {}
+func (m *mockMetricsCollector) IncConnectionsTotal(backend string)                     {}
+func (m *mockMetricsCollector) IncConnectionsActive(backend string)                    {}
+func (m *mockMetricsCollector) DecConnectionsActive(backend string)                    {}
+func (m *mockMetricsCollector) IncConnectionErrors(backend, reason string)             {}
+func (m *mockMetricsCollector) ObserveConnectionDuration(backend string, d float64)    {}
+func (m *mockMetricsCollector) IncHealthChecksTotal(backend, status string)            {}
+func (m *mockMetricsCollector) SetBackendHealthStatus(backend string, healthy bool)    {}
+func (m *mockMetricsCollector) IncTLSHandshakeErrors(reason string)                    {}
+func (m *mockMetricsCollector) ObserveDatagram(backend, direction string, size int)    {}
+func (m *mockMetricsCollector) IncQueueDepth()                                         {}
+func (m *mockMetricsCollector) DecQueueDepth()                                         {}
+func (m *mockMetricsCollector) ObserveQueueWait(seconds float64)                       {}
+func (m *mockMetricsCollector) IncRejectedConnections(reason string)                   {}
+func (m *mockMetricsCollector) AddBytesSent(backend string, n int)                     {}
+func (m *mockMetricsCollector) AddBytesReceived(backend string, n int)                 {}
+func (m *mockMetricsCollector) ObserveDialLatency(backend string, seconds float64)     {}
+func (m *mockMetricsCollector) ObserveTimeToFirstByte(backend string, seconds float64) {}
 
 func TestHandleConnectionWithNoHealthyBackends(t *testing.T) {
 	repo := repository.New()
@@ -39,9 +50,8 @@ func TestHandleConnectionWithNoHealthyBackends(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
-	backend := model.NewBackend("test", "localhost", 9999, 1)
-	backend.SetHealthy(false)
-	repo.Add(ctx, backend)
+	repo.Add(ctx, model.NewBackend("test", "localhost", 9999, 1))
+	repo.SetHealthy(ctx, "test", false)
 
 	done := make(chan error, 1)
 	go func() {
@@ -77,16 +87,15 @@ func TestHandleConnectionWithHealthyBackends(t *testing.T) {
 
 	// Add healthy backend
 	backend := model.NewBackend("test", "localhost", 3001, 1)
-	backend.SetHealthy(true)
 	repo.Add(ctx, backend)
 
 	healthyBackends := repo.GetHealthy(ctx)
 	if len(healthyBackends) != 1 {
-		t.Errorf("Expected 1 healthy backend, got %d", len(healthyBackends))
+		t.Fatalf("Expected 1 healthy backend, got %d", len(healthyBackends))
 	}
 
-	if !healthyBackends[0].GetHealthy() {
-		t.Error("Expected backend to be healthy")
+	if healthyBackends[0] != backend {
+		t.Error("Expected the added backend to be healthy")
 	}
 }
 
@@ -108,9 +117,7 @@ func TestHandleConnectionBackendSelection(t *testing.T) {
 	}
 
 	for _, cfg := range backendConfigs {
-		b := model.NewBackend(cfg.id, "localhost", cfg.port, 1)
-		b.SetHealthy(true)
-		repo.Add(ctx, b)
+		repo.Add(ctx, model.NewBackend(cfg.id, "localhost", cfg.port, 1))
 	}
 
 	healthyBackends := repo.GetHealthy(ctx)
@@ -159,12 +166,10 @@ func TestRepositoryFiltersHealthyBackends(t *testing.T) {
 	}
 
 	for i, b := range backends {
-		if i%2 == 0 {
-			b.SetHealthy(true)
-		} else {
-			b.SetHealthy(false)
-		}
 		repo.Add(ctx, b)
+		if i%2 == 1 {
+			repo.SetHealthy(ctx, b.ID, false)
+		}
 	}
 
 	healthyBackends := repo.GetHealthy(ctx)
@@ -173,7 +178,7 @@ func TestRepositoryFiltersHealthyBackends(t *testing.T) {
 	}
 
 	for _, b := range healthyBackends {
-		if !b.GetHealthy() {
+		if !strings.HasPrefix(b.GetID(), "healthy") {
 			t.Errorf("Expected all backends in GetHealthy() to be healthy, got unhealthy: %s", b.GetID())
 		}
 	}
@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/balancer"
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/listener"
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/repository"
+	"github.com/reybrally/TCP-Load-Balancer/internal/application/usecase"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+	"github.com/reybrally/TCP-Load-Balancer/tests/fixtures"
+)
+
+func startUDPBalancer(t *testing.T, sessionTimeout time.Duration, metrics *fixtures.MetricsRecorder) (*usecase.HandleDatagramUseCase, string, []*model.Backend) {
+	t.Helper()
+
+	repo := repository.New()
+	backends := []*model.Backend{
+		model.NewBackend("b1", "127.0.0.1", fixtures.StartUDPEchoServer(t, "b1:"), 1),
+		model.NewBackend("b2", "127.0.0.1", fixtures.StartUDPEchoServer(t, "b2:"), 1),
+	}
+	for _, b := range backends {
+		repo.Add(context.Background(), b)
+	}
+
+	log := logger.New("test")
+	uc := usecase.NewDatagram(balancer.New(), repo, metrics, log, sessionTimeout)
+
+	ul, err := listener.NewUDP("127.0.0.1", 0, log)
+	if err != nil {
+		t.Fatalf("Failed to create UDP listener: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go ul.Listen(ctx, uc)
+	t.Cleanup(func() {
+		cancel()
+		ul.Close()
+	})
+
+	return uc, ul.Addr().String(), backends
+}
+
+func exchange(t *testing.T, conn net.Conn, msg string) string {
+	t.Helper()
+
+	if _, err := conn.Write([]byte(msg)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	return string(buf[:n])
+}
+
+func TestUDPSessionsStickToBackend(t *testing.T) {
+	metrics := fixtures.NewMetricsRecorder()
+	uc, addr, backends := startUDPBalancer(t, time.Minute, metrics)
+
+	first, _ := net.Dial("udp", addr)
+	defer first.Close()
+	second, _ := net.Dial("udp", addr)
+	defer second.Close()
+
+	firstBackend := strings.SplitN(exchange(t, first, "query-1"), ":", 2)[0]
+	exchange(t, second, "query-1")
+
+	for i := 0; i < 5; i++ {
+		reply := exchange(t, first, "query")
+		if !strings.HasPrefix(reply, firstBackend+":") {
+			t.Errorf("Expected session to stay on %s, got reply %q", firstBackend, reply)
+		}
+	}
+
+	if uc.ActiveSessions() != 2 {
+		t.Errorf("Expected 2 active sessions, got %d", uc.ActiveSessions())
+	}
+
+	total := 0
+	for _, b := range backends {
+		total += b.GetActiveConnections()
+	}
+	if total != 2 {
+		t.Errorf("Expected 2 sessions tracked on backends, got %d", total)
+	}
+
+	var toBackend, toClient int
+	for _, b := range backends {
+		toBackend += metrics.Count("datagrams:" + b.GetAddress() + ":to_backend")
+		toClient += metrics.Count("datagrams:" + b.GetAddress() + ":to_client")
+	}
+	if toBackend != 7 || toClient != 7 {
+		t.Errorf("Expected 7 datagrams each way, got %d to backends and %d to clients", toBackend, toClient)
+	}
+}
+
+func TestUDPSessionsExpireWhenIdle(t *testing.T) {
+	uc, addr, backends := startUDPBalancer(t, 100*time.Millisecond, fixtures.NewMetricsRecorder())
+
+	client, _ := net.Dial("udp", addr)
+	defer client.Close()
+	exchange(t, client, "ping")
+
+	if uc.ActiveSessions() != 1 {
+		t.Fatalf("Expected 1 active session, got %d", uc.ActiveSessions())
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for uc.ActiveSessions() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Session did not expire")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	for _, b := range backends {
+		if b.GetActiveConnections() != 0 {
+			t.Errorf("Expected no active connections on %s after expiry", b.GetID())
+		}
+	}
+
+	if reply := exchange(t, client, "again"); !strings.HasSuffix(reply, ":again") {
+		t.Errorf("Expected a new session after expiry, got %q", reply)
+	}
+}
+
+func TestUDPWithoutHealthyBackends(t *testing.T) {
+	repo := repository.New()
+	repo.Add(context.Background(), model.NewBackend("down", "127.0.0.1", 9, 1))
+	repo.SetHealthy(context.Background(), "down", false)
+
+	metrics := fixtures.NewMetricsRecorder()
+	uc := usecase.NewDatagram(balancer.New(), repo, metrics, logger.New("test"), time.Second)
+
+	conn, _ := net.ListenPacket("udp", "127.0.0.1:0")
+	defer conn.Close()
+
+	client := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 40000}
+	if err := uc.HandleDatagram(context.Background(), conn, client, []byte("x")); err == nil {
+		t.Error("Expected error without healthy backends")
+	}
+	if metrics.Count("connection_errors:all:no_healthy_backends") != 1 {
+		t.Error("Expected no_healthy_backends to be counted")
+	}
+}
+
+func TestUDPSessionKeepsDatagramsSentWhileDialing(t *testing.T) {
+	repo := repository.New()
+	repo.Add(context.Background(), model.NewBackend("b1", "127.0.0.1", fixtures.StartUDPEchoServer(t, "b1:"), 1))
+	uc := usecase.NewDatagram(balancer.New(), repo, fixtures.NewMetricsRecorder(), logger.New("test"), time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The client and the frontend socket are the same, so replies come
+	// back to conn.
+	conn, _ := net.ListenPacket("udp", "127.0.0.1:0")
+	defer conn.Close()
+
+	messages := []string{"one", "two", "three", "four"}
+	for _, msg := range messages {
+		if err := uc.HandleDatagram(ctx, conn, conn.LocalAddr(), []byte(msg)); err != nil {
+			t.Fatalf("HandleDatagram failed: %v", err)
+		}
+	}
+
+	buf := make([]byte, 1024)
+	for _, msg := range messages {
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("Read failed: %v", err)
+		}
+		if reply := string(buf[:n]); reply != "b1:"+msg {
+			t.Errorf("Expected reply to %q in order, got %q", msg, reply)
+		}
+	}
+}
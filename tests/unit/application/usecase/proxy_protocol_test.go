@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/balancer"
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/repository"
+	"github.com/reybrally/TCP-Load-Balancer/internal/application/usecase"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+	"github.com/reybrally/TCP-Load-Balancer/tests/fixtures"
+)
+
+func TestHandleSendsProxyProtocolV1(t *testing.T) {
+	backendLn, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer backendLn.Close()
+
+	lines := make(chan string, 2)
+	go func() {
+		conn, err := backendLn.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for i := 0; i < 2; i++ {
+			line, _ := r.ReadString('\n')
+			lines <- line
+		}
+	}()
+
+	repo := repository.New()
+	repo.Add(context.Background(), model.NewBackend("b1", "127.0.0.1", backendLn.Addr().(*net.TCPAddr).Port, 1))
+	uc := usecase.New(balancer.New(), repo, fixtures.NewMetricsRecorder(), logger.New("test"),
+		usecase.WithProxyProtocol(1))
+
+	frontLn, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer frontLn.Close()
+	go func() {
+		conn, err := frontLn.Accept()
+		if err == nil {
+			uc.Handle(context.Background(), conn)
+		}
+	}()
+
+	client, err := net.Dial("tcp", frontLn.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer client.Close()
+	client.Write([]byte("payload\n"))
+
+	clientAddr := client.LocalAddr().(*net.TCPAddr)
+	expected := fmt.Sprintf("PROXY TCP4 127.0.0.1 127.0.0.1 %d %d\r\n",
+		clientAddr.Port, frontLn.Addr().(*net.TCPAddr).Port)
+
+	for _, want := range []string{expected, "payload\n"} {
+		select {
+		case got := <-lines:
+			if got != want {
+				t.Errorf("Expected %q, got %q", want, got)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timed out waiting for %q", want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/balancer"
+	"github.com/reybrally/TCP-Load-Balancer/internal/application/usecase"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+	"github.com/reybrally/TCP-Load-Balancer/tests/fixtures"
+)
+
+func waitForSessions(t *testing.T, registry *usecase.SessionRegistry, filter model.SessionFilter, count int) []model.SessionInfo {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		sessions := registry.Sessions(filter)
+		if len(sessions) == count {
+			return sessions
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %d sessions, got %d", count, len(sessions))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSessionRegistryListsLiveSessions(t *testing.T) {
+	repo, backends := limitedPool(t, 0)
+	registry := usecase.NewSessionRegistry()
+	uc := usecase.New(balancer.New(), repo, fixtures.NewMetricsRecorder(), logger.New("test"), usecase.WithSessions(registry, "web"))
+
+	s := startSession(t, uc)
+	s.mustEcho(t, "hello")
+
+	info := waitForSessions(t, registry, model.SessionFilter{}, 1)[0]
+	if info.ID == 0 || info.Frontend != "web" || info.Backend != backends[0].GetAddress() {
+		t.Errorf("Unexpected session %+v", info)
+	}
+
+	// The reply can reach the client just before it is counted.
+	deadline := time.Now().Add(2 * time.Second)
+	for info.BytesSent != 6 || info.BytesReceived != 6 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected live byte counts of 6 each way, got %d and %d", info.BytesSent, info.BytesReceived)
+		}
+		time.Sleep(5 * time.Millisecond)
+		info = registry.Sessions(model.SessionFilter{})[0]
+	}
+
+	if got := registry.Sessions(model.SessionFilter{Backend: "10.9.9.9:1"}); len(got) != 0 {
+		t.Errorf("Expected backend filter to exclude the session, got %d", len(got))
+	}
+	if got := registry.Sessions(model.SessionFilter{Frontend: "web", Client: info.Client}); len(got) != 1 {
+		t.Errorf("Expected client filter to match, got %d", len(got))
+	}
+	if got := registry.Sessions(model.SessionFilter{MinIdle: time.Hour}); len(got) != 0 {
+		t.Errorf("Expected min idle filter to exclude the session, got %d", len(got))
+	}
+
+	s.conn.Close()
+	waitForSessions(t, registry, model.SessionFilter{}, 0)
+}
+
+func TestKillSession(t *testing.T) {
+	repo, _ := limitedPool(t, 0)
+	registry := usecase.NewSessionRegistry()
+	accessLog := &fixtures.AccessLogRecorder{}
+	uc := usecase.New(balancer.New(), repo, fixtures.NewMetricsRecorder(), logger.New("test"),
+		usecase.WithSessions(registry, "web"), usecase.WithAccessLog(accessLog))
+
+	victim := startSession(t, uc)
+	victim.mustEcho(t, "victim")
+	bystander := startSession(t, uc)
+	bystander.mustEcho(t, "bystander")
+
+	sessions := waitForSessions(t, registry, model.SessionFilter{}, 2)
+	if !registry.Kill(sessions[0].ID) {
+		t.Fatal("Expected session to be killed")
+	}
+	if registry.Kill(12345) {
+		t.Error("Expected unknown session not to be found")
+	}
+
+	if _, err := victim.echo("again", time.Second); err == nil {
+		t.Error("Expected killed session to be closed")
+	}
+	bystander.mustEcho(t, "still here")
+
+	record := accessLog.Wait(t, 1)[0]
+	if record.CloseReason != model.CloseKilled {
+		t.Errorf("Expected close reason %s, got %s", model.CloseKilled, record.CloseReason)
+	}
+}
+
+func TestKillBackendSessions(t *testing.T) {
+	repo, backends := limitedPool(t, 1, 1)
+	registry := usecase.NewSessionRegistry()
+	uc := usecase.New(balancer.New(), repo, fixtures.NewMetricsRecorder(), logger.New("test"), usecase.WithSessions(registry, "web"))
+
+	// Each backend takes one connection, so the two sessions use both.
+	first := startSession(t, uc)
+	first.mustEcho(t, "first")
+	second := startSession(t, uc)
+	second.mustEcho(t, "second")
+	waitForSessions(t, registry, model.SessionFilter{}, 2)
+
+	target := backends[0].GetAddress()
+	if killed := registry.KillBackend(target); killed != 1 {
+		t.Fatalf("Expected 1 session killed, got %d", killed)
+	}
+	remaining := waitForSessions(t, registry, model.SessionFilter{}, 1)
+	if remaining[0].Backend == target {
+		t.Errorf("Expected remaining session to use the other backend")
+	}
+}
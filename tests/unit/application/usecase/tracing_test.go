@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/balancer"
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/repository"
+	"github.com/reybrally/TCP-Load-Balancer/internal/application/usecase"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/port"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+	"github.com/reybrally/TCP-Load-Balancer/tests/fixtures"
+)
+
+func TestTracerSeesSessionPhases(t *testing.T) {
+	repo, backends := limitedPool(t, 0)
+	tracer := &fixtures.TracerRecorder{}
+	uc := usecase.New(balancer.New(), repo, fixtures.NewMetricsRecorder(), logger.New("test"), usecase.WithTracer(tracer))
+
+	s := startSession(t, uc)
+	s.mustEcho(t, "hello")
+	s.conn.Close()
+
+	session := tracer.Wait(t, 1)[0]
+	want := []string{port.PhaseSelect, port.PhaseDial, port.PhaseProxy}
+	if len(session.Phases) != len(want) {
+		t.Fatalf("Expected phases %v, got %v", want, session.Phases)
+	}
+	for i := range want {
+		if session.Phases[i] != want[i] {
+			t.Fatalf("Expected phases %v, got %v", want, session.Phases)
+		}
+	}
+	if len(session.Errors) != 0 {
+		t.Errorf("Expected no phase errors, got %v", session.Errors)
+	}
+	if session.Record.Backend != backends[0].GetAddress() || session.Record.BytesSent != 6 {
+		t.Errorf("Expected record with backend and bytes, got %+v", session.Record)
+	}
+	if session.Client == "" {
+		t.Error("Expected client address on the session")
+	}
+}
+
+func TestSessionStartsAtAccept(t *testing.T) {
+	tracer := &fixtures.TracerRecorder{}
+	uc := usecase.New(balancer.New(), repository.New(), fixtures.NewMetricsRecorder(), logger.New("test"), usecase.WithTracer(tracer))
+
+	accepted := time.Now().Add(-time.Second)
+	client, server := net.Pipe()
+	defer client.Close()
+	go uc.Handle(port.WithAcceptTime(context.Background(), accepted), server)
+	client.SetDeadline(time.Now().Add(2 * time.Second))
+	bufio.NewReader(client).ReadString('\n')
+
+	session := tracer.Wait(t, 1)[0]
+	if !session.Accepted.Equal(accepted) || !session.Record.Start.Equal(accepted) {
+		t.Errorf("Expected session to start at accept %v, got span %v and record %v", accepted, session.Accepted, session.Record.Start)
+	}
+	if session.Record.Duration < time.Second {
+		t.Errorf("Expected duration to count from accept, got %v", session.Record.Duration)
+	}
+}
+
+func TestTracerSeesFailedSelection(t *testing.T) {
+	tracer := &fixtures.TracerRecorder{}
+	uc := usecase.New(balancer.New(), repository.New(), fixtures.NewMetricsRecorder(), logger.New("test"), usecase.WithTracer(tracer))
+
+	s := startSession(t, uc)
+	s.readLine(2 * time.Second)
+
+	session := tracer.Wait(t, 1)[0]
+	if len(session.Phases) != 1 || session.Errors[port.PhaseSelect] == nil {
+		t.Errorf("Expected only a failed select phase, got %v with errors %v", session.Phases, session.Errors)
+	}
+	if session.Record.CloseReason != model.CloseNoHealthyBackends {
+		t.Errorf("Expected close reason %s, got %s", model.CloseNoHealthyBackends, session.Record.CloseReason)
+	}
+}
@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/balancer"
+	"github.com/reybrally/TCP-Load-Balancer/internal/application/usecase"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+	"github.com/reybrally/TCP-Load-Balancer/tests/fixtures"
+)
+
+// tcpPair returns both ends of a loopback TCP connection, so the relay
+// copies between real sockets as it does in production.
+func tcpPair(t *testing.T) (client, server net.Conn) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer ln.Close()
+
+	client, err = net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	server, err = ln.Accept()
+	if err != nil {
+		t.Fatalf("Failed to accept: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client, server
+}
+
+func TestTrafficMetrics(t *testing.T) {
+	repo, backends := limitedPool(t, 0)
+	backendAddr := backends[0].GetAddress()
+	metrics := fixtures.NewMetricsRecorder()
+	uc := usecase.New(balancer.New(), repo, metrics, logger.New("test"))
+
+	client, server := tcpPair(t)
+	done := make(chan error, 1)
+	go func() {
+		done <- uc.Handle(context.Background(), server)
+	}()
+
+	// Larger than the relay buffer, so counts are reported more than once.
+	payload := bytes.Repeat([]byte("0123456789abcdef"), 20000)
+	client.SetDeadline(time.Now().Add(5 * time.Second))
+	go client.Write(payload)
+
+	echoed := make([]byte, len(payload))
+	if _, err := io.ReadFull(client, echoed); err != nil {
+		t.Fatalf("Failed to read echo: %v", err)
+	}
+	if !bytes.Equal(echoed, payload) {
+		t.Fatal("Echoed payload differs")
+	}
+	client.Close()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Handle did not return")
+	}
+
+	waitForCount(t, metrics, "bytes_sent:"+backendAddr, len(payload))
+	waitForCount(t, metrics, "bytes_received:"+backendAddr, len(payload))
+	if got := metrics.Count("dial_latency:" + backendAddr); got != 1 {
+		t.Errorf("Expected one dial latency observation, got %d", got)
+	}
+	if got := metrics.Count("ttfb:" + backendAddr); got != 1 {
+		t.Errorf("Expected one time to first byte observation, got %d", got)
+	}
+}
+
+func TestTimeToFirstByteWithoutResponse(t *testing.T) {
+	repo, backends := limitedPool(t, 0)
+	backendAddr := backends[0].GetAddress()
+	metrics := fixtures.NewMetricsRecorder()
+	uc := usecase.New(balancer.New(), repo, metrics, logger.New("test"))
+
+	client, server := net.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- uc.Handle(context.Background(), server)
+	}()
+
+	waitForCount(t, metrics, "dial_latency:"+backendAddr, 1)
+	client.Close()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Handle did not return")
+	}
+	if got := metrics.Count("ttfb:" + backendAddr); got != 0 {
+		t.Errorf("Expected no time to first byte for a silent backend, got %d", got)
+	}
+	if got := metrics.Count("bytes_received:" + backendAddr); got != 0 {
+		t.Errorf("Expected no bytes received, got %d", got)
+	}
+}
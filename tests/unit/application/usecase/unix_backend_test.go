@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/balancer"
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/health"
+	"github.com/reybrally/TCP-Load-Balancer/internal/adapter/repository"
+	"github.com/reybrally/TCP-Load-Balancer/internal/application/usecase"
+	"github.com/reybrally/TCP-Load-Balancer/internal/domain/model"
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/logger"
+	"github.com/reybrally/TCP-Load-Balancer/tests/fixtures"
+)
+
+func TestProxyToUnixBackend(t *testing.T) {
+	path := fixtures.StartUnixEchoServer(t)
+	backend := model.NewUnixBackend("sock", path, 1)
+
+	repo := repository.New()
+	repo.Add(context.Background(), backend)
+
+	metrics := fixtures.NewMetricsRecorder()
+	log := logger.New("test")
+
+	if !health.New(time.Second, metrics, log).Check(context.Background(), backend) {
+		t.Fatal("Expected unix backend to pass health check")
+	}
+
+	uc := usecase.New(balancer.New(), repo, metrics, log)
+	line, err := proxyOnce(t, uc, "via socket")
+	if err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+	if line != "via socket\n" {
+		t.Errorf("Expected echo through unix backend, got %q", line)
+	}
+	if metrics.Count("connections_total:"+path) != 1 {
+		t.Error("Expected connection counted against the socket path")
+	}
+}
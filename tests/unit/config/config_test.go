@@ -0,0 +1,515 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/config"
+)
+
+func TestApplyDefaultsLegacyLayout(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{Host: "0.0.0.0", Port: 8080},
+		Backends: []config.BackendConfig{
+			{Address: "localhost", Port: 3001, Weight: 1},
+			{Address: "localhost", Port: 3002, Weight: 1},
+		},
+	}
+
+	cfg.ApplyDefaults()
+
+	if len(cfg.Frontends) != 1 {
+		t.Fatalf("Expected 1 frontend, got %d", len(cfg.Frontends))
+	}
+
+	fe := cfg.Frontends[0]
+	if fe.Name != config.DefaultFrontendName || fe.Port != 8080 || fe.Pool != config.DefaultPoolName {
+		t.Errorf("Unexpected default frontend: %+v", fe)
+	}
+	if fe.Algorithm != config.DefaultAlgorithm {
+		t.Errorf("Expected algorithm %s, got %s", config.DefaultAlgorithm, fe.Algorithm)
+	}
+	if fe.HealthCheck.Interval != config.DefaultHealthCheckInterval || fe.HealthCheck.Timeout != config.DefaultHealthCheckTimeout {
+		t.Errorf("Unexpected health check defaults: %+v", fe.HealthCheck)
+	}
+
+	pool := cfg.Pool(config.DefaultPoolName)
+	if pool == nil || len(pool.Backends) != 2 {
+		t.Fatalf("Expected default pool with 2 backends, got %+v", pool)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid config, got %v", err)
+	}
+}
+
+func TestApplyDefaultsKeepsExplicitFrontends(t *testing.T) {
+	cfg := &config.Config{
+		Frontends: []config.FrontendConfig{
+			{Name: "postgres", Port: 5432, HealthCheck: config.HealthCheckConfig{Interval: 5 * time.Second}},
+			{Name: "redis", Port: 6379, Pool: "cache", Algorithm: "round_robin"},
+		},
+		Pools: []config.PoolConfig{
+			{Name: "postgres", Backends: []config.BackendConfig{{Address: "db1", Port: 5432, Weight: 1}}},
+			{Name: "cache", Backends: []config.BackendConfig{{Address: "redis1", Port: 6379, Weight: 1}}},
+		},
+	}
+
+	cfg.ApplyDefaults()
+
+	if len(cfg.Frontends) != 2 {
+		t.Fatalf("Expected 2 frontends, got %d", len(cfg.Frontends))
+	}
+	if cfg.Frontends[0].Pool != "postgres" {
+		t.Errorf("Expected pool to default to frontend name, got %s", cfg.Frontends[0].Pool)
+	}
+	if cfg.Frontends[0].HealthCheck.Interval != 5*time.Second {
+		t.Errorf("Expected explicit interval to be kept, got %v", cfg.Frontends[0].HealthCheck.Interval)
+	}
+	if cfg.Pool(config.DefaultPoolName) != nil {
+		t.Error("Expected no default pool when frontends are configured")
+	}
+
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid config, got %v", err)
+	}
+}
+
+func TestValidateRejectsBadFrontends(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Config
+	}{
+		{
+			"Unknown pool",
+			config.Config{
+				Frontends: []config.FrontendConfig{{Name: "api", Port: 80, Pool: "missing"}},
+			},
+		},
+		{
+			"Duplicate frontend",
+			config.Config{
+				Frontends: []config.FrontendConfig{
+					{Name: "api", Port: 80, Pool: "api"},
+					{Name: "api", Port: 81, Pool: "api"},
+				},
+				Pools: []config.PoolConfig{{Name: "api"}},
+			},
+		},
+		{
+			"Same listen address",
+			config.Config{
+				Frontends: []config.FrontendConfig{
+					{Name: "a", Port: 80, Pool: "api"},
+					{Name: "b", Port: 80, Pool: "api"},
+				},
+				Pools: []config.PoolConfig{{Name: "api"}},
+			},
+		},
+		{
+			"SNI route to unknown pool",
+			config.Config{
+				Frontends: []config.FrontendConfig{{
+					Name: "https",
+					Port: 443,
+					SNI: config.SNIConfig{Routes: []config.SNIRouteConfig{
+						{Hosts: []string{"api.example.com"}, Pool: "missing"},
+					}},
+				}},
+			},
+		},
+		{
+			"PROXY protocol without trusted CIDRs",
+			config.Config{
+				Frontends: []config.FrontendConfig{{
+					Name:        "api",
+					Port:        80,
+					Pool:        "api",
+					AcceptProxy: config.AcceptProxyConfig{Enabled: true},
+				}},
+				Pools: []config.PoolConfig{{Name: "api"}},
+			},
+		},
+		{
+			"Duplicate pool",
+			config.Config{
+				Pools: []config.PoolConfig{{Name: "api"}, {Name: "api"}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.cfg.Validate(); err == nil {
+				t.Error("Expected validation error, got nil")
+			}
+		})
+	}
+}
+
+func TestSNIFrontendWithoutDefaultPool(t *testing.T) {
+	cfg := &config.Config{
+		Frontends: []config.FrontendConfig{{
+			Name: "https",
+			Port: 443,
+			SNI: config.SNIConfig{Routes: []config.SNIRouteConfig{
+				{Hosts: []string{"api.example.com"}, Pool: "api"},
+				{Hosts: []string{"*.example.com"}, Pool: "web"},
+				{Hosts: []string{"www.example.org"}, Pool: "web"},
+			}},
+		}},
+		Pools: []config.PoolConfig{{Name: "api"}, {Name: "web"}},
+	}
+
+	cfg.ApplyDefaults()
+
+	if cfg.Frontends[0].Pool != "" {
+		t.Errorf("Expected no default pool, got %q", cfg.Frontends[0].Pool)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected valid config, got %v", err)
+	}
+
+	pools := cfg.Frontends[0].PoolNames()
+	if len(pools) != 2 || pools[0] != "api" || pools[1] != "web" {
+		t.Errorf("Expected pools [api web], got %v", pools)
+	}
+}
+
+func TestUDPFrontendConfig(t *testing.T) {
+	cfg := &config.Config{
+		Frontends: []config.FrontendConfig{
+			{Name: "dns-tcp", Port: 53, Pool: "dns"},
+			{Name: "dns-udp", Protocol: config.ProtocolUDP, Port: 53, Pool: "dns"},
+		},
+		Pools: []config.PoolConfig{{Name: "dns"}},
+	}
+
+	cfg.ApplyDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected tcp and udp on the same port to be valid, got %v", err)
+	}
+	if cfg.Frontends[1].UDP.SessionTimeout != config.DefaultUDPSessionTimeout {
+		t.Errorf("Expected default session timeout, got %v", cfg.Frontends[1].UDP.SessionTimeout)
+	}
+	if !cfg.Frontends[1].HealthCheck.Disabled {
+		t.Error("Expected TCP health checks to be off for a udp frontend")
+	}
+	if cfg.Frontends[0].HealthCheck.Disabled {
+		t.Error("Expected TCP health checks to stay on for a tcp frontend")
+	}
+
+	cfg.Frontends[1].TLS = config.TLSConfig{Enabled: true, CertFile: "c", KeyFile: "k"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected TLS on a udp frontend to be rejected")
+	}
+
+	cfg.Frontends[1].TLS = config.TLSConfig{}
+	cfg.Frontends[1].Protocol = "sctp"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected unknown protocol to be rejected")
+	}
+}
+
+func TestUnixSocketConfig(t *testing.T) {
+	cfg := &config.Config{
+		Frontends: []config.FrontendConfig{
+			{Name: "sidecar", Pool: "app", Unix: config.UnixSocketConfig{Path: "/run/lb.sock", Mode: "0660"}},
+			{Name: "public", Port: 8080, Pool: "app"},
+		},
+		Pools: []config.PoolConfig{{
+			Name:     "app",
+			Backends: []config.BackendConfig{{Socket: "/run/app.sock", Weight: 1}},
+		}},
+	}
+	cfg.ApplyDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected valid config, got %v", err)
+	}
+	if addr := cfg.Frontends[0].ListenAddress(); addr != "unix//run/lb.sock" {
+		t.Errorf("Unexpected listen address %q", addr)
+	}
+	if mode, _ := cfg.Frontends[0].Unix.FileMode(); mode != 0o660 {
+		t.Errorf("Expected mode 0660, got %v", mode)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*config.Config)
+	}{
+		{"invalid mode", func(c *config.Config) { c.Frontends[0].Unix.Mode = "rw" }},
+		{"socket and address", func(c *config.Config) { c.Pools[0].Backends[0].Address = "localhost" }},
+		{"udp on socket backends", func(c *config.Config) { c.Frontends[1].Protocol = config.ProtocolUDP }},
+		{"tls without server name", func(c *config.Config) { c.Pools[0].TLS.Enabled = true }},
+		{"duplicate socket path", func(c *config.Config) { c.Frontends[1].Unix.Path = "/run/lb.sock" }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			broken := *cfg
+			broken.Frontends = append([]config.FrontendConfig(nil), cfg.Frontends...)
+			broken.Pools = []config.PoolConfig{cfg.Pools[0]}
+			broken.Pools[0].Backends = append([]config.BackendConfig(nil), cfg.Pools[0].Backends...)
+			test.modify(&broken)
+			if err := broken.Validate(); err == nil {
+				t.Error("Expected validation error, got nil")
+			}
+		})
+	}
+}
+
+func TestAcceptorsConfig(t *testing.T) {
+	valid := &config.Config{
+		Frontends: []config.FrontendConfig{{Name: "web", Port: 80, Pool: "web", Acceptors: 4, Backlog: 4096}},
+		Pools:     []config.PoolConfig{{Name: "web"}},
+	}
+	valid.ApplyDefaults()
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Expected valid config, got %v", err)
+	}
+
+	tests := []struct {
+		name string
+		fe   config.FrontendConfig
+	}{
+		{"negative backlog", config.FrontendConfig{Name: "web", Port: 80, Pool: "web", Backlog: -1}},
+		{"acceptors on udp", config.FrontendConfig{Name: "web", Protocol: config.ProtocolUDP, Port: 80, Pool: "web", Acceptors: 2}},
+		{"acceptors on unix", config.FrontendConfig{Name: "web", Pool: "web", Acceptors: 2, Unix: config.UnixSocketConfig{Path: "/run/lb.sock"}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Frontends: []config.FrontendConfig{test.fe},
+				Pools:     []config.PoolConfig{{Name: "web"}},
+			}
+			if err := cfg.Validate(); err == nil {
+				t.Error("Expected validation error, got nil")
+			}
+		})
+	}
+}
+
+func TestConnectionLimitsConfig(t *testing.T) {
+	cfg := &config.Config{
+		Frontends: []config.FrontendConfig{{Name: "web", Port: 80, Pool: "web", MaxConnections: 1000, Queue: config.QueueConfig{Size: 50}}},
+		Pools:     []config.PoolConfig{{Name: "web", Backends: []config.BackendConfig{{Address: "localhost", Port: 3001, MaxConnections: 100}}}},
+	}
+	cfg.ApplyDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected valid config, got %v", err)
+	}
+	if cfg.Frontends[0].Queue.Timeout != config.DefaultQueueTimeout {
+		t.Errorf("Expected default queue timeout, got %v", cfg.Frontends[0].Queue.Timeout)
+	}
+
+	cfg.Pools[0].Backends[0].MaxConnections = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected negative backend max_connections to be rejected")
+	}
+
+	cfg.Pools[0].Backends[0].MaxConnections = 0
+	cfg.Frontends[0].Queue.Size = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected negative queue size to be rejected")
+	}
+}
+
+func TestRateLimitConfig(t *testing.T) {
+	cfg := &config.Config{
+		Frontends: []config.FrontendConfig{{Name: "web", Port: 80, Pool: "web", RateLimit: config.RateLimitConfig{Rate: 10, PrefixRate: 100, IPv6Prefix: 48, MaxConnectionsPerIP: 20}}},
+		Pools:     []config.PoolConfig{{Name: "web"}},
+	}
+	cfg.ApplyDefaults()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected valid config, got %v", err)
+	}
+	if !cfg.Frontends[0].RateLimit.Enabled() {
+		t.Error("Expected rate limit to be enabled")
+	}
+
+	tests := []struct {
+		name string
+		fe   config.FrontendConfig
+	}{
+		{"negative rate", config.FrontendConfig{Name: "web", Port: 80, Pool: "web", RateLimit: config.RateLimitConfig{Rate: -1}}},
+		{"ipv4 prefix too long", config.FrontendConfig{Name: "web", Port: 80, Pool: "web", RateLimit: config.RateLimitConfig{PrefixRate: 1, IPv4Prefix: 33}}},
+		{"ipv6 prefix too long", config.FrontendConfig{Name: "web", Port: 80, Pool: "web", RateLimit: config.RateLimitConfig{PrefixRate: 1, IPv6Prefix: 129}}},
+		{"rate limit on udp", config.FrontendConfig{Name: "web", Protocol: config.ProtocolUDP, Port: 80, Pool: "web", RateLimit: config.RateLimitConfig{Rate: 1}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Frontends: []config.FrontendConfig{test.fe},
+				Pools:     []config.PoolConfig{{Name: "web"}},
+			}
+			if err := cfg.Validate(); err == nil {
+				t.Error("Expected validation error, got nil")
+			}
+		})
+	}
+}
+
+func TestACLConfig(t *testing.T) {
+	cfg := &config.Config{
+		Frontends: []config.FrontendConfig{{Name: "web", Port: 80, Pool: "web", ACL: config.ACLConfig{
+			Allow: []string{"10.0.0.0/8", "2001:db8::1"},
+			Deny:  []string{"10.1.0.0/16"},
+			File:  "/etc/lb/acl.txt",
+		}}},
+		Pools: []config.PoolConfig{{Name: "web"}},
+	}
+	cfg.ApplyDefaults()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected valid config, got %v", err)
+	}
+	if cfg.Frontends[0].ACL.ReloadInterval != config.DefaultACLReloadInterval {
+		t.Errorf("Expected default ACL reload interval, got %v", cfg.Frontends[0].ACL.ReloadInterval)
+	}
+
+	cfg.Frontends[0].ACL.Deny = []string{"10.1.0.0/33"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected invalid CIDR to be rejected")
+	}
+
+	cfg.Frontends[0].ACL = config.ACLConfig{Deny: []string{"10.0.0.1"}}
+	cfg.Frontends[0].Protocol = config.ProtocolUDP
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected acl on udp to be rejected")
+	}
+}
+
+func TestAccessLogConfig(t *testing.T) {
+	cfg := &config.Config{
+		AccessLog: config.AccessLogConfig{Enabled: true},
+		Frontends: []config.FrontendConfig{{Name: "web", Port: 80, Pool: "web"}},
+		Pools:     []config.PoolConfig{{Name: "web"}},
+	}
+	cfg.ApplyDefaults()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected valid config, got %v", err)
+	}
+	if cfg.AccessLog.Format != config.AccessLogFormatJSON || cfg.AccessLog.Output != config.AccessLogStdout {
+		t.Errorf("Expected JSON to stdout by default, got %s to %s", cfg.AccessLog.Format, cfg.AccessLog.Output)
+	}
+
+	cfg.AccessLog.Format = "xml"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected unknown format to be rejected")
+	}
+}
+
+func TestMetricsConfig(t *testing.T) {
+	cfg := &config.Config{
+		Frontends: []config.FrontendConfig{{Name: "web", Port: 80, Pool: "web"}},
+		Pools:     []config.PoolConfig{{Name: "web"}},
+	}
+	cfg.ApplyDefaults()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected valid config, got %v", err)
+	}
+	if cfg.Metrics.Address != config.DefaultMetricsAddress || cfg.Metrics.Path != config.DefaultMetricsPath {
+		t.Errorf("Expected default metrics endpoint, got %s%s", cfg.Metrics.Address, cfg.Metrics.Path)
+	}
+
+	cfg.Metrics.Path = "metrics"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected relative metrics path to be rejected")
+	}
+}
+
+func TestAdminConfig(t *testing.T) {
+	cfg := &config.Config{
+		Frontends: []config.FrontendConfig{{Name: "web", Port: 80, Pool: "web"}},
+		Pools:     []config.PoolConfig{{Name: "web"}},
+	}
+	cfg.ApplyDefaults()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected valid config, got %v", err)
+	}
+	if cfg.Admin.Address != config.DefaultAdminAddress {
+		t.Errorf("Expected default admin address, got %s", cfg.Admin.Address)
+	}
+
+	for _, addr := range []string{"localhost:9092", "[::1]:9092"} {
+		cfg.Admin.Address = addr
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Expected loopback admin address %s to be valid, got %v", addr, err)
+		}
+	}
+
+	for _, addr := range []string{":9092", "0.0.0.0:9092", "10.0.0.1:9092"} {
+		cfg.Admin.Address = addr
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Expected admin address %s without a token to be rejected", addr)
+		}
+	}
+
+	cfg.Admin.Token = "secret"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected admin address with a token to be valid, got %v", err)
+	}
+}
+
+func TestOTelConfig(t *testing.T) {
+	cfg := &config.Config{
+		OTel:      config.OTelConfig{Enabled: true, Traces: true},
+		Frontends: []config.FrontendConfig{{Name: "web", Port: 80, Pool: "web"}},
+		Pools:     []config.PoolConfig{{Name: "web"}},
+	}
+	cfg.ApplyDefaults()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected valid config, got %v", err)
+	}
+	if cfg.OTel.Endpoint != config.DefaultOTelEndpoint || cfg.OTel.Interval != config.DefaultOTelInterval {
+		t.Errorf("Expected default endpoint and interval, got %s every %v", cfg.OTel.Endpoint, cfg.OTel.Interval)
+	}
+
+	cfg.OTel.Enabled = false
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected traces without otel to be rejected")
+	}
+}
+
+func TestStatsDConfig(t *testing.T) {
+	cfg := &config.Config{
+		StatsD:    config.StatsDConfig{Enabled: true},
+		Frontends: []config.FrontendConfig{{Name: "web", Port: 80, Pool: "web"}},
+		Pools:     []config.PoolConfig{{Name: "web"}},
+	}
+	cfg.ApplyDefaults()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected valid config, got %v", err)
+	}
+	if cfg.StatsD.Address != config.DefaultStatsDAddress || cfg.StatsD.Prefix != config.DefaultStatsDPrefix {
+		t.Errorf("Expected default address and prefix, got %s and %s", cfg.StatsD.Address, cfg.StatsD.Prefix)
+	}
+
+	cfg.OTel.Enabled = true
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected otel and statsd together to be rejected")
+	}
+}
+
+func TestPoolStateFile(t *testing.T) {
+	cfg := &config.Config{
+		Frontends: []config.FrontendConfig{{Name: "web", Port: 80, Pool: "web"}},
+		Pools: []config.PoolConfig{
+			{Name: "web", StateFile: "/var/lib/tcp-lb/web.json"},
+			{Name: "api", StateFile: "/var/lib/tcp-lb/api.json"},
+		},
+	}
+	cfg.ApplyDefaults()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected valid config, got %v", err)
+	}
+
+	cfg.Pools[1].StateFile = cfg.Pools[0].StateFile
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected pools sharing a state file to be rejected")
+	}
+}
@@ -22,24 +22,6 @@ func TestBackendCreation(t *testing.T) {
 	}
 }
 
-func TestBackendHealthStatus(t *testing.T) {
-	backend := model.NewBackend("test", "localhost", 3001, 1)
-
-	if !backend.GetHealthy() {
-		t.Error("Expected backend to be healthy by default")
-	}
-
-	backend.SetHealthy(false)
-	if backend.GetHealthy() {
-		t.Error("Expected backend to be unhealthy after SetHealthy(false)")
-	}
-
-	backend.SetHealthy(true)
-	if !backend.GetHealthy() {
-		t.Error("Expected backend to be healthy after SetHealthy(true)")
-	}
-}
-
 func TestConnectionCount(t *testing.T) {
 	backend := model.NewBackend("test", "localhost", 3001, 1)
 
@@ -114,3 +96,42 @@ func TestBackendGetAddress(t *testing.T) {
 		})
 	}
 }
+
+func TestUnixBackend(t *testing.T) {
+	backend := model.NewUnixBackend("sock", "/run/app.sock", 1)
+
+	if backend.GetNetwork() != model.NetworkUnix {
+		t.Errorf("Expected network unix, got %s", backend.GetNetwork())
+	}
+	if backend.GetAddress() != "/run/app.sock" {
+		t.Errorf("Expected socket path as address, got %s", backend.GetAddress())
+	}
+
+	if tcp := model.NewBackend("tcp", "localhost", 3001, 1); tcp.GetNetwork() != model.NetworkTCP {
+		t.Errorf("Expected network tcp by default, got %s", tcp.GetNetwork())
+	}
+}
+
+func TestBackendTryAcquire(t *testing.T) {
+	backend := model.NewBackend("limited", "localhost", 3001, 1)
+	backend.MaxConnections = 2
+
+	if !backend.TryAcquire() || !backend.TryAcquire() {
+		t.Fatal("Expected two connections to fit")
+	}
+	if backend.HasCapacity() || backend.TryAcquire() {
+		t.Error("Expected backend to be full")
+	}
+
+	backend.DecreaseConnections()
+	if !backend.HasCapacity() || !backend.TryAcquire() {
+		t.Error("Expected a slot after a connection finished")
+	}
+
+	unlimited := model.NewBackend("unlimited", "localhost", 3002, 1)
+	for i := 0; i < 100; i++ {
+		if !unlimited.TryAcquire() {
+			t.Fatal("Expected no limit without MaxConnections")
+		}
+	}
+}
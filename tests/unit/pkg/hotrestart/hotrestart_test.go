@@ -0,0 +1,107 @@
+//go:build linux
+
+package hotrestart
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/hotrestart"
+)
+
+// serveAsChild runs in the process started by hotrestart.Start, which re-runs
+// the test binary with the same arguments.
+func serveAsChild(child *hotrestart.Child) {
+	files := child.Files()
+	if len(files["web"]) != 2 || len(files["empty"]) != 0 {
+		os.Exit(2)
+	}
+
+	var listeners []net.Listener
+	for _, file := range files["web"] {
+		ln, err := net.FileListener(file)
+		if err != nil {
+			os.Exit(3)
+		}
+		file.Close()
+		listeners = append(listeners, ln)
+	}
+
+	if err := child.Ready(); err != nil {
+		os.Exit(4)
+	}
+
+	for _, ln := range listeners {
+		ln.(*net.TCPListener).SetDeadline(time.Now().Add(5 * time.Second))
+		conn, err := ln.Accept()
+		if err != nil {
+			os.Exit(5)
+		}
+		fmt.Fprintf(conn, "%d\n", os.Getpid())
+		conn.Close()
+	}
+	os.Exit(0)
+}
+
+func TestStartHandsOverSockets(t *testing.T) {
+	child, err := hotrestart.Inherit()
+	if err != nil {
+		t.Fatalf("Inherit failed: %v", err)
+	}
+	if child != nil {
+		serveAsChild(child)
+	}
+
+	var files []*os.File
+	var addrs []string
+	for i := 0; i < 2; i++ {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Failed to listen: %v", err)
+		}
+		defer ln.Close()
+		file, err := ln.(*net.TCPListener).File()
+		if err != nil {
+			t.Fatalf("Failed to get socket file: %v", err)
+		}
+		defer file.Close()
+		files = append(files, file)
+		addrs = append(addrs, ln.Addr().String())
+	}
+
+	pid, err := hotrestart.Start(hotrestart.Files{"web": files, "empty": nil}, 10*time.Second)
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	for _, addr := range addrs {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			t.Fatalf("Failed to connect: %v", err)
+		}
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		conn.Close()
+		if err != nil {
+			t.Fatalf("Failed to read from child: %v", err)
+		}
+		if served, _ := strconv.Atoi(strings.TrimSpace(line)); served != pid {
+			t.Errorf("Expected connection served by child %d, got %q", pid, line)
+		}
+	}
+}
+
+func TestInheritWithoutParent(t *testing.T) {
+	t.Setenv("LB_HOT_RESTART_FD", "")
+
+	child, err := hotrestart.Inherit()
+	if err != nil || child != nil {
+		t.Errorf("Expected no parent, got %v, %v", child, err)
+	}
+}
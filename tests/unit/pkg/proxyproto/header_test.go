@@ -0,0 +1,121 @@
+package proxyproto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/proxyproto"
+)
+
+var v2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+func TestFormatV1(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   proxyproto.Header
+		expected string
+	}{
+		{
+			"TCP4",
+			proxyproto.Header{
+				Version:     1,
+				Command:     proxyproto.CommandProxy,
+				Source:      &net.TCPAddr{IP: net.ParseIP("192.168.0.1"), Port: 56324},
+				Destination: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 443},
+			},
+			"PROXY TCP4 192.168.0.1 10.0.0.1 56324 443\r\n",
+		},
+		{
+			"TCP6",
+			proxyproto.Header{
+				Version:     1,
+				Command:     proxyproto.CommandProxy,
+				Source:      &net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 1234},
+				Destination: &net.TCPAddr{IP: net.ParseIP("2001:db8::2"), Port: 80},
+			},
+			"PROXY TCP6 2001:db8::1 2001:db8::2 1234 80\r\n",
+		},
+		{
+			"Local",
+			proxyproto.Header{Version: 1, Command: proxyproto.CommandLocal},
+			"PROXY UNKNOWN\r\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := test.header.Format()
+			if err != nil {
+				t.Fatalf("Format failed: %v", err)
+			}
+			if string(data) != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, data)
+			}
+		})
+	}
+}
+
+func TestFormatV2TCP4WithTLVs(t *testing.T) {
+	header := proxyproto.Header{
+		Version:     2,
+		Command:     proxyproto.CommandProxy,
+		Source:      &net.TCPAddr{IP: net.ParseIP("192.168.0.1"), Port: 56324},
+		Destination: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 443},
+		TLVs: []proxyproto.TLV{
+			{Type: proxyproto.TLVTypeAuthority, Value: []byte("example.com")},
+		},
+	}
+
+	data, err := header.Format()
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	if !bytes.Equal(data[:12], v2Signature) {
+		t.Fatalf("Missing v2 signature: %x", data[:12])
+	}
+	if data[12] != 0x21 {
+		t.Errorf("Expected version/command 0x21, got 0x%02x", data[12])
+	}
+	if data[13] != 0x11 {
+		t.Errorf("Expected family TCP4 0x11, got 0x%02x", data[13])
+	}
+
+	length := binary.BigEndian.Uint16(data[14:16])
+	if int(length) != len(data)-16 {
+		t.Fatalf("Length field %d does not match payload %d", length, len(data)-16)
+	}
+
+	payload := data[16:]
+	if !bytes.Equal(payload[0:4], []byte{192, 168, 0, 1}) || !bytes.Equal(payload[4:8], []byte{10, 0, 0, 1}) {
+		t.Errorf("Unexpected addresses: %v", payload[:8])
+	}
+	if binary.BigEndian.Uint16(payload[8:10]) != 56324 || binary.BigEndian.Uint16(payload[10:12]) != 443 {
+		t.Errorf("Unexpected ports: %v", payload[8:12])
+	}
+
+	tlv := payload[12:]
+	if tlv[0] != proxyproto.TLVTypeAuthority || binary.BigEndian.Uint16(tlv[1:3]) != 11 || string(tlv[3:]) != "example.com" {
+		t.Errorf("Unexpected TLV: %q", tlv)
+	}
+}
+
+func TestFormatV2Local(t *testing.T) {
+	data, err := (&proxyproto.Header{Version: 2, Command: proxyproto.CommandLocal}).Format()
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	expected := append(append([]byte{}, v2Signature...), 0x20, 0x00, 0x00, 0x00)
+	if !bytes.Equal(data, expected) {
+		t.Errorf("Expected %x, got %x", expected, data)
+	}
+}
+
+func TestFormatRejectsUnknownVersion(t *testing.T) {
+	if _, err := (&proxyproto.Header{Version: 3}).Format(); err == nil {
+		t.Error("Expected error for version 3")
+	}
+}
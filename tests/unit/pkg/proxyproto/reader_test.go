@@ -0,0 +1,189 @@
+package proxyproto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/proxyproto"
+)
+
+func TestReadRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		header proxyproto.Header
+	}{
+		{
+			"v1 TCP4",
+			proxyproto.Header{
+				Version:     1,
+				Command:     proxyproto.CommandProxy,
+				Source:      &net.TCPAddr{IP: net.ParseIP("203.0.113.7").To4(), Port: 40000},
+				Destination: &net.TCPAddr{IP: net.ParseIP("10.0.0.1").To4(), Port: 5432},
+			},
+		},
+		{
+			"v2 TCP6 with TLVs",
+			proxyproto.Header{
+				Version:     2,
+				Command:     proxyproto.CommandProxy,
+				Source:      &net.TCPAddr{IP: net.ParseIP("2001:db8::7"), Port: 40000},
+				Destination: &net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 443},
+				TLVs: []proxyproto.TLV{
+					{Type: proxyproto.TLVTypeALPN, Value: []byte("h2")},
+					{Type: proxyproto.TLVTypeAuthority, Value: []byte("api.example.com")},
+				},
+			},
+		},
+		{
+			"v2 unix",
+			proxyproto.Header{
+				Version:     2,
+				Command:     proxyproto.CommandProxy,
+				Source:      &net.UnixAddr{Net: "unix", Name: "/run/client.sock"},
+				Destination: &net.UnixAddr{Net: "unix", Name: "/run/lb.sock"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := test.header.Format()
+			if err != nil {
+				t.Fatalf("Format failed: %v", err)
+			}
+
+			r := bufio.NewReader(io.MultiReader(bytes.NewReader(data), strings.NewReader("payload")))
+			got, err := proxyproto.Read(r)
+			if err != nil {
+				t.Fatalf("Read failed: %v", err)
+			}
+
+			if got.Version != test.header.Version || got.Command != test.header.Command {
+				t.Errorf("Expected version %d command %d, got %d %d",
+					test.header.Version, test.header.Command, got.Version, got.Command)
+			}
+			if got.Source.String() != test.header.Source.String() {
+				t.Errorf("Expected source %s, got %s", test.header.Source, got.Source)
+			}
+			if got.Destination.String() != test.header.Destination.String() {
+				t.Errorf("Expected destination %s, got %s", test.header.Destination, got.Destination)
+			}
+			for _, tlv := range test.header.TLVs {
+				value, ok := got.TLV(tlv.Type)
+				if !ok || !bytes.Equal(value, tlv.Value) {
+					t.Errorf("Expected TLV 0x%02x=%q, got %q", tlv.Type, tlv.Value, value)
+				}
+			}
+
+			rest, _ := io.ReadAll(r)
+			if string(rest) != "payload" {
+				t.Errorf("Expected payload to follow the header, got %q", rest)
+			}
+		})
+	}
+}
+
+// rawV2 builds a v2 PROXY header with the given family byte, address block
+// and an ALPN TLV after it.
+func rawV2(family byte, addrs []byte) []byte {
+	payload := append(append([]byte(nil), addrs...), proxyproto.TLVTypeALPN, 0x00, 0x02, 'h', '2')
+	out := []byte("\r\n\r\n\x00\r\nQUIT\n")
+	out = append(out, 0x21, family)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(payload)))
+	return append(out, payload...)
+}
+
+func unixPath(path string) []byte {
+	b := make([]byte, 108)
+	copy(b, path)
+	return b
+}
+
+func TestReadV2Datagram(t *testing.T) {
+	tests := []struct {
+		name        string
+		family      byte
+		addrs       []byte
+		source      string
+		destination string
+		network     string
+	}{
+		{
+			"UDP4", 0x12,
+			[]byte{203, 0, 113, 7, 10, 0, 0, 1, 0x9C, 0x40, 0x00, 0x35},
+			"203.0.113.7:40000", "10.0.0.1:53", "udp",
+		},
+		{
+			"UDP6", 0x22,
+			append(append(append([]byte(nil), net.ParseIP("2001:db8::7")...), net.ParseIP("2001:db8::1")...), 0x9C, 0x40, 0x00, 0x35),
+			"[2001:db8::7]:40000", "[2001:db8::1]:53", "udp",
+		},
+		{
+			"unix datagram", 0x32,
+			append(unixPath("/run/client.sock"), unixPath("/run/lb.sock")...),
+			"/run/client.sock", "/run/lb.sock", "unixgram",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			header, err := proxyproto.Read(bufio.NewReader(bytes.NewReader(rawV2(test.family, test.addrs))))
+			if err != nil {
+				t.Fatalf("Read failed: %v", err)
+			}
+			if header.Source.Network() != test.network || header.Source.String() != test.source {
+				t.Errorf("Expected %s source %s, got %s %s", test.network, test.source, header.Source.Network(), header.Source)
+			}
+			if header.Destination.String() != test.destination {
+				t.Errorf("Expected destination %s, got %s", test.destination, header.Destination)
+			}
+			if value, ok := header.TLV(proxyproto.TLVTypeALPN); !ok || string(value) != "h2" || len(header.TLVs) != 1 {
+				t.Errorf("Expected only the ALPN TLV, got %+v", header.TLVs)
+			}
+		})
+	}
+}
+
+func TestReadLocal(t *testing.T) {
+	for _, version := range []int{1, 2} {
+		data, _ := (&proxyproto.Header{Version: version, Command: proxyproto.CommandLocal}).Format()
+		header, err := proxyproto.Read(bufio.NewReader(bytes.NewReader(data)))
+		if err != nil {
+			t.Fatalf("v%d: Read failed: %v", version, err)
+		}
+		if header.Command != proxyproto.CommandLocal || header.Source != nil {
+			t.Errorf("v%d: expected LOCAL without addresses, got %+v", version, header)
+		}
+	}
+}
+
+func TestReadRejectsInvalidHeaders(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   error
+	}{
+		{"Plain data", "GET / HTTP/1.1\r\n\r\n", proxyproto.ErrNoHeader},
+		{"Bad protocol", "PROXY UDP4 1.2.3.4 5.6.7.8 1 2\r\n", proxyproto.ErrInvalidHeader},
+		{"Family mismatch", "PROXY TCP4 2001:db8::1 1.2.3.4 1 2\r\n", proxyproto.ErrInvalidHeader},
+		{"Bad port", "PROXY TCP4 1.2.3.4 5.6.7.8 70000 2\r\n", proxyproto.ErrInvalidHeader},
+		{"Missing CRLF", "PROXY TCP4 1.2.3.4 5.6.7.8 1 2\n", proxyproto.ErrInvalidHeader},
+		{"Bad v2 version", "\r\n\r\n\x00\r\nQUIT\n\x11\x11\x00\x00", proxyproto.ErrInvalidHeader},
+		{"Truncated v2 addresses", "\r\n\r\n\x00\r\nQUIT\n\x21\x11\x00\x04\x01\x02\x03\x04", proxyproto.ErrInvalidHeader},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := proxyproto.Read(bufio.NewReader(strings.NewReader(test.input)))
+			if !errors.Is(err, test.err) {
+				t.Errorf("Expected %v, got %v", test.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package systemd
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/systemd"
+)
+
+// TestActivationHelper runs in a child process started by TestListenFiles with
+// the sockets at fd 3 and up, the way systemd passes them.
+func TestActivationHelper(t *testing.T) {
+	if os.Getenv("LB_ACTIVATION_HELPER") != "1" {
+		t.Skip("helper process")
+	}
+	os.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+
+	files, err := systemd.ListenFiles(true)
+	if err != nil {
+		t.Fatalf("ListenFiles failed: %v", err)
+	}
+	if os.Getenv("LISTEN_FDS") != "" {
+		t.Error("Expected LISTEN_FDS to be unset")
+	}
+
+	for _, file := range files {
+		ln, err := net.FileListener(file)
+		if err != nil {
+			t.Fatalf("Failed to use %s: %v", file.Name(), err)
+		}
+		conn, err := ln.Accept()
+		if err != nil {
+			t.Fatalf("Accept failed: %v", err)
+		}
+		conn.Write([]byte(file.Name() + "\n"))
+		conn.Close()
+		ln.Close()
+	}
+}
+
+func TestListenFiles(t *testing.T) {
+	var files []*os.File
+	var addrs []string
+	for i := 0; i < 2; i++ {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Failed to listen: %v", err)
+		}
+		file, err := ln.(*net.TCPListener).File()
+		if err != nil {
+			t.Fatalf("Failed to get socket file: %v", err)
+		}
+		ln.Close()
+		defer file.Close()
+		files = append(files, file)
+		addrs = append(addrs, ln.Addr().String())
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestActivationHelper$")
+	cmd.Env = append(os.Environ(), "LB_ACTIVATION_HELPER=1", "LISTEN_FDS=2", "LISTEN_FDNAMES=web")
+	cmd.ExtraFiles = files
+	output := &strings.Builder{}
+	cmd.Stdout = output
+	cmd.Stderr = output
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start helper: %v", err)
+	}
+
+	for i, expected := range []string{"web", "unknown"} {
+		conn, err := net.DialTimeout("tcp", addrs[i], 2*time.Second)
+		if err != nil {
+			t.Fatalf("Failed to connect to activated socket: %v", err)
+		}
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		name, _ := bufio.NewReader(conn).ReadString('\n')
+		conn.Close()
+		if strings.TrimSpace(name) != expected {
+			t.Errorf("Expected socket %d to be named %q, got %q", i, expected, name)
+		}
+	}
+
+	if err := cmd.Wait(); err != nil {
+		t.Fatalf("Helper failed: %v\n%s", err, output)
+	}
+}
+
+func TestListenFilesIgnoresOtherProcess(t *testing.T) {
+	t.Setenv("LISTEN_PID", "1")
+	t.Setenv("LISTEN_FDS", "2")
+
+	files, err := systemd.ListenFiles(false)
+	if err != nil || files != nil {
+		t.Errorf("Expected no files for another process, got %v, %v", files, err)
+	}
+}
@@ -0,0 +1,113 @@
+package systemd
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/reybrally/TCP-Load-Balancer/internal/pkg/systemd"
+)
+
+func fakeNotifySocket(t *testing.T, name string) *net.UnixConn {
+	t.Helper()
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: name, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("Failed to create notify socket: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func readState(t *testing.T, conn *net.UnixConn) string {
+	t.Helper()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("Failed to read notification: %v", err)
+	}
+	return string(buf[:n])
+}
+
+func TestNotifier(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn := fakeNotifySocket(t, path)
+	t.Setenv("NOTIFY_SOCKET", path)
+
+	notifier := systemd.NewNotifier()
+	if notifier == nil {
+		t.Fatal("Expected notifier with NOTIFY_SOCKET set")
+	}
+
+	notifier.Ready()
+	notifier.Status("Serving 2 frontends")
+	notifier.Stopping()
+
+	for _, expected := range []string{"READY=1", "STATUS=Serving 2 frontends", "STOPPING=1"} {
+		if state := readState(t, conn); state != expected {
+			t.Errorf("Expected %q, got %q", expected, state)
+		}
+	}
+}
+
+func TestNotifierAbstractSocket(t *testing.T) {
+	conn := fakeNotifySocket(t, "\x00tcp-lb-test-notify")
+	t.Setenv("NOTIFY_SOCKET", "@tcp-lb-test-notify")
+
+	if err := systemd.NewNotifier().Ready(); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if state := readState(t, conn); state != "READY=1" {
+		t.Errorf("Expected READY=1, got %q", state)
+	}
+}
+
+func TestNotifierWithoutSystemd(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+
+	notifier := systemd.NewNotifier()
+	if notifier != nil {
+		t.Fatal("Expected nil notifier without NOTIFY_SOCKET")
+	}
+	if err := notifier.Ready(); err != nil {
+		t.Errorf("Expected nil notifier to ignore calls, got %v", err)
+	}
+	notifier.RunWatchdog(context.Background())
+}
+
+func TestWatchdog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn := fakeNotifySocket(t, path)
+	t.Setenv("NOTIFY_SOCKET", path)
+	t.Setenv("WATCHDOG_USEC", "100000")
+	t.Setenv("WATCHDOG_PID", "")
+
+	if interval := systemd.WatchdogInterval(); interval != 100*time.Millisecond {
+		t.Fatalf("Expected 100ms watchdog interval, got %v", interval)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		systemd.NewNotifier().RunWatchdog(ctx)
+		close(done)
+	}()
+
+	for i := 0; i < 2; i++ {
+		if state := readState(t, conn); state != "WATCHDOG=1" {
+			t.Errorf("Expected WATCHDOG=1, got %q", state)
+		}
+	}
+
+	cancel()
+	<-done
+
+	t.Setenv("WATCHDOG_PID", "1")
+	if systemd.WatchdogInterval() != 0 {
+		t.Error("Expected watchdog meant for another process to be ignored")
+	}
+}